@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/twitterclient"
+)
+
+// loginTimeout is how long the login command waits for the redirect
+const loginTimeout = 5 * time.Minute
+
+// runLogin performs the OAuth 2.0 Authorization Code flow with PKCE once and
+// stores the token in a Secret, from where the operator picks it up and
+// keeps it refreshed.
+func runLogin(log logr.Logger, args []string) {
+	flags := flag.NewFlagSet("login", flag.ExitOnError)
+	redirectURL := flags.String(
+		"redirect-url",
+		"http://127.0.0.1:8080/callback",
+		"Callback URL registered for the app. The command listens on its host and port.",
+	)
+	secretName := flags.String("secret", tokenSecretName(), "Secret to store the token in")
+	flags.Parse(args)
+
+	redirect, err := url.Parse(*redirectURL)
+	if err != nil || redirect.Scheme != "http" || redirect.Host == "" {
+		fatal(log, fmt.Errorf("redirect URL must be a local http URL, got %q", *redirectURL), "Invalid --redirect-url")
+	}
+
+	kubeConfig, err := getKubeConfig()
+	if err != nil {
+		fatal(log, err, "Failed to load kubeconfig")
+	}
+	store := newSecretTokenStore(kubeConfig, *secretName)
+	config := newOAuth2Config(log, *redirectURL)
+
+	pkce, err := twitterclient.NewPKCE()
+	if err != nil {
+		fatal(log, err, "Failed to start login")
+	}
+	state, err := twitterclient.NewOAuth2State()
+	if err != nil {
+		fatal(log, err, "Failed to start login")
+	}
+
+	listener, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		fatal(log, err, "Failed to listen for the redirect")
+	}
+	codes := make(chan string, 1)
+	errs := make(chan error, 1)
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != redirect.Path {
+				http.NotFound(w, r)
+				return
+			}
+			query := r.URL.Query()
+			switch {
+			case query.Get("state") != state:
+				http.Error(w, "State does not match, start the login again.", http.StatusBadRequest)
+				errs <- errors.New("state in redirect does not match")
+			case query.Get("error") != "":
+				http.Error(w, "Authorization failed: "+query.Get("error"), http.StatusBadRequest)
+				errs <- fmt.Errorf("authorization failed: %s", query.Get("error"))
+			default:
+				fmt.Fprintln(w, "Logged in. You can close this window.")
+				codes <- query.Get("code")
+			}
+		}),
+	}
+	go server.Serve(listener)
+	defer server.Close()
+
+	fmt.Fprintf(os.Stderr, "Open this URL in a browser and authorize the app:\n\n  %s\n\n", pkce.AuthCodeURL(config, state))
+
+	var code string
+	select {
+	case code = <-codes:
+	case err := <-errs:
+		fatal(log, err, "Login failed")
+	case <-time.After(loginTimeout):
+		fatal(log, fmt.Errorf("no redirect within %s", loginTimeout), "Login failed")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	token, err := pkce.Exchange(ctx, config, code)
+	if err != nil {
+		fatal(log, err, "Failed to exchange authorization code")
+	}
+	if err := store.SaveToken(token); err != nil {
+		fatal(log, err, "Failed to store token")
+	}
+	log.Info("Stored OAuth 2.0 token", "secret", *secretName, "expiry", token.Expiry)
+}
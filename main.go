@@ -1,20 +1,40 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
+	"github.com/go-logr/logr"
+	"golang.org/x/oauth2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
 
+	"github.com/jonatanblue/tweet-operator/pkg/admission"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/blueskyclient"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/httpclient"
 	"github.com/jonatanblue/tweet-operator/pkg/libs/k8sclient"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/logging"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/mastodonclient"
 	"github.com/jonatanblue/tweet-operator/pkg/libs/twitterclient"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/webhookclient"
 
 	"github.com/jonatanblue/tweet-operator/pkg/reconciler"
 
 	"k8s.io/client-go/rest"
 
+	v1 "github.com/jonatanblue/tweet-operator/pkg/apis/example.com/v1"
 	tweetclient "github.com/jonatanblue/tweet-operator/pkg/client/clientset/versioned"
 )
 
@@ -23,16 +43,123 @@ type runMode string
 const (
 	runModeLoop    = runMode("loop")
 	runModeRunOnce = runMode("run-once")
+	runModeDryRun  = runMode("dry-run")
 )
 
-func mustLookupEnv(key string) string {
+// defaultReconcileInterval is the pause between passes in loop mode
+const defaultReconcileInterval = 10 * time.Second
+
+// defaultReplyInterval is the pause between fetching replies, which search
+// rate limits allow far less often than reconciling
+const defaultReplyInterval = 15 * time.Minute
+
+// defaultMentionInterval is the pause between fetching mentions
+const defaultMentionInterval = time.Minute
+
+// defaultAutoReplyInterval is the pause between answering mentions
+const defaultAutoReplyInterval = time.Minute
+
+// webhookTimeout bounds each call to a MentionWatch webhook, so a slow
+// receiver does not hold up reconciling
+const webhookTimeout = 10 * time.Second
+
+// defaultCallTimeout bounds each call to Kubernetes and to the platforms
+const defaultCallTimeout = httpclient.DefaultTimeout
+
+// admissionAddr is where the admission webhook is served
+const admissionAddr = ":9443"
+
+// maxDryRunPasses bounds a dry run in case the simulated state never
+// converges.
+const maxDryRunPasses = 1000
+
+func mustLookupEnv(log logr.Logger, key string) string {
 	value := os.Getenv(key)
 	if value == "" {
-		log.Panicf("%s must be set", key)
+		fatal(log, fmt.Errorf("%s must be set", key), "Missing required environment variable")
 	}
 	return value
 }
 
+func lookupIntEnv(log logr.Logger, key string, defaultValue int) int {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return defaultValue
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil || i <= 0 {
+		fatal(log, fmt.Errorf("%s must be a positive integer, got %q", key, value), "Invalid environment variable")
+	}
+	return i
+}
+
+func lookupDurationEnv(log logr.Logger, key string, defaultValue time.Duration) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		fatal(log, fmt.Errorf("%s must be a duration such as 30s, got %q", key, value), "Invalid environment variable")
+	}
+	return d
+}
+
+func lookupBoolEnv(log logr.Logger, key string, defaultValue bool) bool {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return defaultValue
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		fatal(log, fmt.Errorf("%s must be true or false, got %q", key, value), "Invalid environment variable")
+	}
+	return b
+}
+
+func fatal(log logr.Logger, err error, msg string) {
+	log.Error(err, msg)
+	os.Exit(1)
+}
+
+// serveAdmission serves the admission webhook on listener with the
+// certificate in certDir until the returned function is called, which
+// waits for the reviews under way. Failing is fatal, since Tweets cannot
+// be changed without it once it is registered.
+func serveAdmission(log logr.Logger, listener net.Listener, certDir string, policyClient admission.PolicyClient) (stop func()) {
+	server := &http.Server{
+		Handler:           admission.NewServer(policyClient, log).Handler(),
+		ReadHeaderTimeout: webhookTimeout,
+	}
+	go func() {
+		log.Info("Serving admission webhook", "addr", listener.Addr().String())
+		err := server.ServeTLS(listener, filepath.Join(certDir, "tls.crt"), filepath.Join(certDir, "tls.key"))
+		if errors.Is(err, http.ErrServerClosed) {
+			return
+		}
+		fatal(log, err, "Admission webhook failed")
+	}()
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			log.Error(err, "Admission webhook did not shut down cleanly")
+		}
+	}
+}
+
+func newLogger() (logr.Logger, error) {
+	format := logging.FormatJSON
+	if value, ok := os.LookupEnv("LOG_FORMAT"); ok {
+		format = logging.Format(value)
+	}
+	verbosity, err := logging.ParseVerbosity(os.Getenv("LOG_VERBOSITY"))
+	if err != nil {
+		return logr.Discard(), err
+	}
+	return logging.New(os.Stderr, format, verbosity)
+}
+
 func inClusterConfigAvailable() bool {
 	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
 	return len(host) > 0 && len(port) > 0
@@ -63,57 +190,442 @@ func getKubeConfig() (*rest.Config, error) {
 	return config, nil
 }
 
+// newTwitterClient returns the client for the Twitter account configured
+// through the environment, and the name of that account.
+func newTwitterClient(
+	ctx context.Context,
+	log logr.Logger,
+	kubeConfig *rest.Config,
+	timelineMaxPages int,
+	callTimeout time.Duration,
+) (reconciler.TwitterClient, string) {
+	userName := mustLookupEnv(log, "TWITTER_USERNAME")
+	rateLimitMaxWait := lookupDurationEnv(log, "RATE_LIMIT_MAX_WAIT", twitterclient.DefaultRateLimitMaxWait)
+	apiVersion := os.Getenv("TWITTER_API_VERSION")
+	if apiVersion != "" && apiVersion != "1.1" && apiVersion != "2" {
+		fatal(log, fmt.Errorf("TWITTER_API_VERSION must be 1.1 or 2, got %q", apiVersion), "Invalid environment variable")
+	}
+	nonPublicMetrics := lookupBoolEnv(log, "TWITTER_NON_PUBLIC_METRICS", false)
+	if nonPublicMetrics && apiVersion != "2" {
+		fatal(log, fmt.Errorf("TWITTER_NON_PUBLIC_METRICS needs TWITTER_API_VERSION=2, got %q", apiVersion), "Invalid environment variable")
+	}
+
+	switch auth := os.Getenv("TWITTER_AUTH"); auth {
+	case "", "oauth1":
+	case "oauth2":
+		// OAuth 2.0 user context is only accepted by the v2 API
+		if apiVersion != "2" {
+			fatal(log, fmt.Errorf("TWITTER_AUTH=oauth2 needs TWITTER_API_VERSION=2, got %q", apiVersion), "Invalid environment variable")
+		}
+		// Token refreshes go through a client with the same timeouts
+		ctx := context.WithValue(context.Background(), oauth2.HTTPClient, httpclient.New(callTimeout))
+		httpClient, err := twitterclient.NewOAuth2HTTPClient(
+			ctx,
+			newOAuth2Config(log, os.Getenv("OAUTH2_REDIRECT_URL")),
+			newSecretTokenStore(kubeConfig, tokenSecretName()),
+			log.WithName("oauth2"),
+		)
+		if err != nil {
+			fatal(log, err, "Failed to create Twitter client")
+		}
+		httpClient.Timeout = callTimeout
+		client := newTwitterV2Client(httpClient, timelineMaxPages, rateLimitMaxWait, nonPublicMetrics)
+		if err := client.VerifyCredentials(ctx); err != nil {
+			fatal(log, err, "Failed to create Twitter client")
+		}
+		return client, userName
+	default:
+		fatal(log, fmt.Errorf("TWITTER_AUTH must be oauth1 or oauth2, got %q", auth), "Invalid environment variable")
+	}
+
+	verify := twitterclient.VerifyCredentials
+	if apiVersion == "2" {
+		verify = func(httpClient *http.Client) error {
+			return newTwitterV2Client(httpClient, 1, 0, false).VerifyCredentials(context.Background())
+		}
+	}
+	var httpClient *http.Client
+	if dir, ok := os.LookupEnv("CREDENTIALS_DIR"); ok {
+		// Credentials from a mounted Secret are picked up again when the
+		// Secret changes
+		reloader, client, err := twitterclient.NewCredentialsReloader(dir, verify, log.WithName("credentials"))
+		if err != nil {
+			fatal(log, err, "Failed to create Twitter client")
+		}
+		interval := lookupDurationEnv(log, "CREDENTIALS_RELOAD_INTERVAL", twitterclient.DefaultCredentialsReloadInterval)
+		go reloader.Run(context.Background(), interval)
+		httpClient = client
+	} else {
+		creds, err := twitterclient.CredentialsFromEnv()
+		if err != nil {
+			fatal(log, err, "Missing required environment variable")
+		}
+		httpClient = twitterclient.NewOAuth1HTTPClient(creds)
+		if err := verify(httpClient); err != nil {
+			fatal(log, err, "Failed to create Twitter client")
+		}
+	}
+	httpClient.Timeout = callTimeout
+
+	if apiVersion == "2" {
+		return newTwitterV2Client(httpClient, timelineMaxPages, rateLimitMaxWait, nonPublicMetrics), userName
+	}
+	goTwitterClient := twitterclient.NewGoTwitterClient(httpClient)
+	return twitterclient.NewTwitterClient(
+		goTwitterClient,
+		goTwitterClient,
+		timelineMaxPages,
+		rateLimitMaxWait,
+	), userName
+}
+
+// newAccountClient returns the client for an Account resource, which
+// holds everything needed to post to platforms other than Twitter.
+func newAccountClient(
+	ctx context.Context,
+	log logr.Logger,
+	kubeConfig *rest.Config,
+	name string,
+	timelineMaxPages int,
+	callTimeout time.Duration,
+) (reconciler.TwitterClient, string, error) {
+	tweetClientSet := tweetclient.NewForConfigOrDie(kubeConfig)
+	kubeClientSet := kubernetes.NewForConfigOrDie(kubeConfig)
+	account, err := k8sclient.GetAccount(
+		ctx,
+		tweetClientSet.ExampleV1().Accounts("default"),
+		kubeClientSet.CoreV1().Secrets("default"),
+		name,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+	log.Info("Using account", "account", account.String())
+
+	switch account.Platform {
+	case v1.PlatformMastodon:
+		client := mastodonclient.NewMastodonClient(httpclient.New(callTimeout), account.InstanceURL, account.AccessToken, timelineMaxPages)
+		if err := client.VerifyCredentials(ctx); err != nil {
+			return nil, "", fmt.Errorf("failed to create Mastodon client: %w", err)
+		}
+		return client, account.Username, nil
+	case v1.PlatformBluesky:
+		serviceURL := account.InstanceURL
+		if serviceURL == "" {
+			serviceURL = blueskyclient.DefaultServiceURL
+		}
+		client := blueskyclient.NewBlueskyClient(httpclient.New(callTimeout), serviceURL, account.Username, account.AccessToken, timelineMaxPages)
+		if err := client.VerifyCredentials(ctx); err != nil {
+			return nil, "", fmt.Errorf("failed to create Bluesky client: %w", err)
+		}
+		return client, account.Username, nil
+	}
+	return nil, "", fmt.Errorf("account %s has unsupported platform %q", name, account.Platform)
+}
+
+func newTwitterV2Client(
+	httpClient *http.Client,
+	timelineMaxPages int,
+	rateLimitMaxWait time.Duration,
+	nonPublicMetrics bool,
+) *twitterclient.TwitterV2Client {
+	baseURL := twitterclient.DefaultV2BaseURL
+	if value, ok := os.LookupEnv("TWITTER_API_URL"); ok {
+		baseURL = value
+	}
+	return twitterclient.NewTwitterV2Client(httpClient, baseURL, timelineMaxPages, rateLimitMaxWait, nonPublicMetrics)
+}
+
+func newOAuth2Config(log logr.Logger, redirectURL string) *oauth2.Config {
+	return twitterclient.NewOAuth2Config(
+		mustLookupEnv(log, "OAUTH2_CLIENT_ID"),
+		os.Getenv("OAUTH2_CLIENT_SECRET"),
+		redirectURL,
+	)
+}
+
+// tokenSecretName is the Secret the OAuth 2.0 token is kept in. The operator
+// role in manifests/operator.yaml only grants access to twitter-credentials,
+// so its resourceNames have to list any other name set here, or writing back
+// a refreshed token fails as Forbidden.
+func tokenSecretName() string {
+	if value, ok := os.LookupEnv("OAUTH2_TOKEN_SECRET"); ok {
+		return value
+	}
+	return "twitter-credentials"
+}
+
+func newSecretTokenStore(kubeConfig *rest.Config, name string) *k8sclient.SecretTokenStore {
+	kubeClientSet := kubernetes.NewForConfigOrDie(kubeConfig)
+	return k8sclient.NewSecretTokenStore(kubeClientSet.CoreV1().Secrets("default"), name)
+}
+
 func main() {
+	log, err := newLogger()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	// Route client-go's own logging through the same structured logger
+	klog.SetLogger(log)
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "plan":
+			runPlan(log, os.Args[2:])
+			return
+		case "login":
+			runLogin(log, os.Args[2:])
+			return
+		default:
+			fatal(log, fmt.Errorf("unknown command %q", os.Args[1]), "Usage: tweet-operator [plan [--output text|json] | login]")
+		}
+	}
+
+	// Signals are handled from here on, so one that comes in during startup
+	// ends the operator before its first pass
+	gracePeriod := lookupDurationEnv(log, "SHUTDOWN_GRACE_PERIOD", defaultShutdownGracePeriod)
+	stopping, work, cancel := newShutdown(log, gracePeriod)
+	defer cancel()
+
 	runMode := runModeLoop
 	// Lookup optional run mode env var
-	if os.Getenv("RUN_MODE") == "run-once" {
+	switch os.Getenv("RUN_MODE") {
+	case "run-once":
 		runMode = runModeRunOnce
+	case "dry-run":
+		runMode = runModeDryRun
 	}
 
 	// Kubernetes client
 	kubeConfig, err := getKubeConfig()
 	if err != nil {
-		log.Fatal(err)
+		fatal(log, err, "Failed to load kubeconfig")
 	}
-	tweetClientSet := tweetclient.NewForConfigOrDie(kubeConfig)
-	tweetClient := tweetClientSet.ExampleV1().Tweets("default")
-	k8sClient := k8sclient.NewK8sClient(tweetClient)
+	callTimeout := lookupDurationEnv(log, "CALL_TIMEOUT", defaultCallTimeout)
+	kubeConfig.Timeout = callTimeout
+	targets := newTargets(stopping, log, kubeConfig, callTimeout)
+	policyClient := newTweetPolicyClient(kubeConfig)
 
-	// Twitter client
-	creds := twitterclient.Credentials{
-		ConsumerKey:       mustLookupEnv("CONSUMER_KEY"),
-		ConsumerSecret:    mustLookupEnv("CONSUMER_SECRET"),
-		AccessToken:       mustLookupEnv("ACCESS_TOKEN"),
-		AccessTokenSecret: mustLookupEnv("ACCESS_TOKEN_SECRET"),
+	// Admission webhook, when a serving certificate is mounted. It is served
+	// until the loop has returned rather than until a signal, since the
+	// status updates of in-flight work still have to be admitted.
+	stopAdmission := func() {}
+	if certDir, ok := os.LookupEnv("WEBHOOK_CERT_DIR"); ok {
+		listener, err := net.Listen("tcp", admissionAddr)
+		if err != nil {
+			fatal(log, err, "Admission webhook failed")
+		}
+		stopAdmission = serveAdmission(log.WithName("admission"), listener, certDir, policyClient)
 	}
-	apiClient, err := twitterclient.NewTwitterAPIClient(&creds)
-	if err != nil {
-		log.Fatal(err)
+
+	// Events
+	var recorder reconciler.EventRecorder
+	// Only set outside dry runs, which record events on Tweets alone
+	var eventRecorder *k8sclient.EventRecorder
+	// Sends the events still queued. Deferred, and called before os.Exit,
+	// which skips deferred calls.
+	flushEvents := func() {}
+	dryRunLog := log.WithName("dry-run")
+	if runMode == runModeDryRun {
+		// Read everything for real, but keep every write in memory
+		log.Info("Dry run: no tweets will be posted or deleted and no Kubernetes objects will be changed")
+		recorder = k8sclient.NewLogEventRecorder(dryRunLog)
+	} else {
+		kubeClientSet := kubernetes.NewForConfigOrDie(kubeConfig)
+		eventBroadcaster := record.NewBroadcaster()
+		flushEvents = eventBroadcaster.Shutdown
+		defer flushEvents()
+		eventBroadcaster.StartStructuredLogging(logging.LevelDebug)
+		eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{
+			Interface: kubeClientSet.CoreV1().Events(""),
+		})
+		eventRecorder = k8sclient.NewEventRecorder(eventBroadcaster.NewRecorder(
+			scheme.Scheme,
+			corev1.EventSource{Component: "tweet-operator"},
+		))
+		recorder = eventRecorder
 	}
-	twitterClient := twitterclient.NewTwitterClient(
-		apiClient.Statuses,
-		apiClient.Timelines,
-	)
 
-	// Reconciler
-	reconciler := reconciler.NewTweetReconciler(
-		k8sClient,
-		twitterClient,
-		mustLookupEnv("TWITTER_USERNAME"),
-	)
+	// One reconciler per target
+	snapshotTTL := lookupDurationEnv(log, "SNAPSHOT_TTL", reconciler.DefaultSnapshotTTL)
+	for _, t := range targets {
+		var k8sClient reconciler.K8sClient = newK8sClient(kubeConfig, t)
+		twitterClient := t.twitterClient
+		if runMode == runModeDryRun {
+			k8sClient = k8sclient.NewDryRunClient(k8sClient, dryRunLog)
+			twitterClient = twitterclient.NewDryRunClient(twitterClient, dryRunLog)
+		}
+		t.log = log.WithValues("target", t.name)
+		t.reconciler = reconciler.NewTweetReconciler(
+			k8sClient,
+			twitterClient,
+			recorder,
+			policyClient,
+			t.userName,
+			snapshotTTL,
+			log.WithName("reconciler"),
+		)
+	}
 
-	log.Print("Starting reconciliation loop...")
-	for true {
-		reconciled, err := reconciler.Reconcile()
-		if err != nil {
-			log.Fatal(err)
+	// Replies, except in dry runs, which are about changes to posts
+	replyInterval := lookupDurationEnv(log, "REPLY_INTERVAL", defaultReplyInterval)
+	maxReplies := lookupIntEnv(log, "MAX_REPLIES", reconciler.DefaultMaxReplies)
+	for _, t := range targets {
+		replyClient, ok := t.twitterClient.(reconciler.ReplyClient)
+		if !ok || replyInterval == 0 || runMode == runModeDryRun {
+			continue
+		}
+		t.replyIngester = reconciler.NewReplyIngester(
+			newK8sClient(kubeConfig, t),
+			replyClient,
+			newReplyStore(kubeConfig, t),
+			recorder,
+			maxReplies,
+			log.WithName("replies"),
+		)
+	}
+
+	// Mentions, also not in dry runs
+	mentionInterval := lookupDurationEnv(log, "MENTION_INTERVAL", defaultMentionInterval)
+	webhookClient := webhookclient.NewWebhookClient(httpclient.New(webhookTimeout))
+	for _, t := range targets {
+		mentionClient, ok := t.twitterClient.(reconciler.MentionClient)
+		if !ok || mentionInterval == 0 || runMode == runModeDryRun {
+			continue
+		}
+		t.mentionWatcher = reconciler.NewMentionWatcher(
+			newMentionWatchClient(kubeConfig, t),
+			mentionClient,
+			webhookClient,
+			eventRecorder,
+			t.userName,
+			log.WithName("mentions"),
+		)
+	}
+
+	// Auto-replies, also not in dry runs
+	autoReplyInterval := lookupDurationEnv(log, "AUTO_REPLY_INTERVAL", defaultAutoReplyInterval)
+	for _, t := range targets {
+		mentionClient, ok := t.twitterClient.(reconciler.MentionClient)
+		if !ok || autoReplyInterval == 0 || runMode == runModeDryRun {
+			continue
+		}
+		t.autoReplier = reconciler.NewAutoReplier(
+			newAutoReplyClient(kubeConfig, t),
+			mentionClient,
+			t.twitterClient,
+			eventRecorder,
+			policyClient,
+			t.userName,
+			log.WithName("auto-replies"),
+		)
+	}
+
+	// Tweets naming targets that are not configured, also not in dry runs
+	var unknownTargets *k8sclient.UnknownTargetClient
+	if runMode != runModeDryRun {
+		unknownTargets = newUnknownTargetClient(kubeConfig, targets)
+	}
+
+	interval := lookupDurationEnv(log, "RECONCILE_INTERVAL", defaultReconcileInterval)
+	log.Info("Starting reconciliation loop", "runMode", runMode, "targets", len(targets))
+	failed := 0
+	// Passes stop being started once a signal comes in, while a pass that is
+	// under way finishes with work
+	for pass := 1; stopping.Err() == nil; pass++ {
+		if unknownTargets != nil {
+			abandoned, err := unknownTargets.Check(work)
+			if err != nil {
+				log.Error(err, "Checking unknown targets failed")
+			}
+			for _, post := range abandoned {
+				log.Info("Left post on unknown target behind", "tweet", post.Tweet, "target", post.Target, "postID", post.ID, "url", post.URL)
+			}
+		}
+		for _, t := range targets {
+			if t.done || stopping.Err() != nil {
+				continue
+			}
+			reconciled, err := t.reconcile(work, time.Now())
+			var requeue *reconciler.RequeueError
+			switch {
+			case errors.As(err, &requeue) && runMode != runModeRunOnce:
+				t.log.Info("Reconciliation deferred", "after", requeue.After.String())
+			case err != nil:
+				// Logged rather than fatal, so the other targets carry on
+				t.log.Error(err, "Reconciliation failed")
+				failed++
+				t.done = runMode == runModeDryRun
+			default:
+				t.log.V(logging.LevelDebug).Info("Reconciliation pass finished", "reconciled", reconciled)
+				t.done = runMode == runModeDryRun && reconciled
+			}
+			if stopping.Err() != nil {
+				continue
+			}
+			if err := t.ingestReplies(work, time.Now(), replyInterval); err != nil {
+				t.log.Error(err, "Reply ingestion failed")
+			}
+			if err := t.watchMentions(work, time.Now(), mentionInterval); err != nil {
+				t.log.Error(err, "Mention watch failed")
+			}
+			if err := t.autoReply(work, time.Now(), autoReplyInterval); err != nil {
+				t.log.Error(err, "Auto-reply failed")
+			}
 		}
-		log.Printf("main: reconciled=%v", reconciled)
 
 		if runMode == runModeRunOnce {
 			break
 		}
 
-		<-time.After(10 * time.Second)
+		if runMode == runModeDryRun {
+			// Each pass takes at most one action per target, so keep going
+			// until every target has converged
+			next, pending := nextRun(targets)
+			if !pending {
+				log.Info("Dry run finished", "passes", pass)
+				break
+			}
+			if pass == maxDryRunPasses {
+				fatal(log, fmt.Errorf("not reconciled after %d passes", pass), "Dry run did not converge")
+			}
+			wait(stopping, time.Until(next))
+			continue
+		}
+
+		wait(stopping, interval)
+	}
+	if code := shutdownExitCode(work); code != 0 {
+		log.Error(fmt.Errorf("in-flight work did not finish within %s", gracePeriod), "Shutdown grace period exceeded")
+		stopAdmission()
+		flushEvents()
+		os.Exit(code)
+	}
+	stopAdmission()
+	// In loop mode failures are only logged, as the next pass may succeed
+	if failed > 0 && runMode != runModeLoop {
+		flushEvents()
+		fatal(log, fmt.Errorf("%d of %d targets failed", failed, len(targets)), "Reconciliation failed")
+	}
+	if stopping.Err() != nil {
+		log.Info("Shut down")
+	}
+}
+
+// nextRun returns when the first target a dry run is not finished with can
+// run again, and false once there are none left.
+func nextRun(targets []*target) (time.Time, bool) {
+	var next time.Time
+	pending := false
+	for _, t := range targets {
+		if t.done {
+			continue
+		}
+		if !pending || t.nextRun.Before(next) {
+			next = t.nextRun
+		}
+		pending = true
 	}
+	return next, pending
 }
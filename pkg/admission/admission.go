@@ -0,0 +1,234 @@
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-logr/logr"
+	v1 "github.com/jonatanblue/tweet-operator/pkg/apis/example.com/v1"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/k8sclient"
+	"github.com/jonatanblue/tweet-operator/pkg/policy"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Paths the webhooks are served on
+const (
+	PathMutateTweets   = "/mutate-tweets"
+	PathValidateTweets = "/validate-tweets"
+)
+
+// PolicyClient lists the TweetPolicies new and edited Tweets are checked
+// against.
+type PolicyClient interface {
+	ListTweetPolicies(ctx context.Context) ([]tweettypes.TweetPolicy, error)
+}
+
+// Server admits changes to Tweets. It records who created each Tweet, only
+// lets authenticated users other than the author approve one, and rejects
+// text that violates a TweetPolicy.
+type Server struct {
+	policyClient PolicyClient
+	log          logr.Logger
+}
+
+func NewServer(policyClient PolicyClient, log logr.Logger) *Server {
+	return &Server{
+		policyClient: policyClient,
+		log:          log,
+	}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(PathMutateTweets, s.serve(mutateTweet))
+	mux.HandleFunc(PathValidateTweets, s.serve(s.validateTweet))
+	return mux
+}
+
+func (s *Server) serve(admit func(ctx context.Context, request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		review := admissionv1.AdmissionReview{}
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil || review.Request == nil {
+			http.Error(w, "invalid admission review", http.StatusBadRequest)
+			return
+		}
+		response := admit(r.Context(), review.Request)
+		response.UID = review.Request.UID
+		if !response.Allowed {
+			s.log.Info(
+				"Denied change to tweet",
+				"namespace", review.Request.Namespace,
+				"name", review.Request.Name,
+				"user", review.Request.UserInfo.Username,
+				"reason", response.Result.Message,
+			)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(admissionv1.AdmissionReview{
+			TypeMeta: review.TypeMeta,
+			Response: response,
+		})
+	}
+}
+
+// mutateTweet records the user creating a Tweet as its author.
+func mutateTweet(ctx context.Context, request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if request.Operation != admissionv1.Create {
+		return allow()
+	}
+	tweet := &v1.Tweet{}
+	if err := json.Unmarshal(request.Object.Raw, tweet); err != nil {
+		return deny(fmt.Sprintf("invalid tweet: %v", err))
+	}
+	var patch []map[string]interface{}
+	if tweet.Annotations == nil {
+		patch = append(patch, map[string]interface{}{
+			"op":    "add",
+			"path":  "/metadata/annotations",
+			"value": map[string]string{k8sclient.AuthorAnnotation: request.UserInfo.Username},
+		})
+	} else {
+		patch = append(patch, map[string]interface{}{
+			"op":    "add",
+			"path":  "/metadata/annotations/" + escapePointer(k8sclient.AuthorAnnotation),
+			"value": request.UserInfo.Username,
+		})
+	}
+	raw, err := json.Marshal(patch)
+	if err != nil {
+		return deny(err.Error())
+	}
+	patchType := admissionv1.PatchTypeJSONPatch
+	response := allow()
+	response.Patch = raw
+	response.PatchType = &patchType
+	return response
+}
+
+// validateTweet keeps the author as recorded on creation, and only lets
+// users add themselves as approvers of other users' Tweets. Approvals
+// have to be given again when the text or targets change. The text is
+// checked against the TweetPolicies when it is new, so the operator can
+// still record the status of Tweets that predate a policy.
+func (s *Server) validateTweet(ctx context.Context, request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	tweet := &v1.Tweet{}
+	if err := json.Unmarshal(request.Object.Raw, tweet); err != nil {
+		return deny(fmt.Sprintf("invalid tweet: %v", err))
+	}
+	author := tweet.Annotations[k8sclient.AuthorAnnotation]
+	textChanged := true
+	if duplicate := findDuplicate(tweet.Spec.Approvals); duplicate != "" {
+		return deny(fmt.Sprintf("%s approved the tweet more than once", duplicate))
+	}
+
+	switch request.Operation {
+	case admissionv1.Create:
+		if author != "" && author != request.UserInfo.Username {
+			return deny(fmt.Sprintf("the %s annotation must name the user creating the tweet", k8sclient.AuthorAnnotation))
+		}
+		if len(tweet.Spec.Approvals) > 0 {
+			return deny("a tweet cannot be approved before it is created")
+		}
+	case admissionv1.Update:
+		old := &v1.Tweet{}
+		if err := json.Unmarshal(request.OldObject.Raw, old); err != nil {
+			return deny(fmt.Sprintf("invalid tweet: %v", err))
+		}
+		if author != old.Annotations[k8sclient.AuthorAnnotation] {
+			return deny(fmt.Sprintf("the %s annotation cannot be changed", k8sclient.AuthorAnnotation))
+		}
+		textChanged = tweet.Spec.Text != old.Spec.Text
+		changed := textChanged || !reflect.DeepEqual(tweet.Spec.Targets, old.Spec.Targets)
+		if changed && len(tweet.Spec.Approvals) > 0 {
+			return deny("approvals must be removed when the text or targets change")
+		}
+		for _, user := range added(old.Spec.Approvals, tweet.Spec.Approvals) {
+			switch {
+			case user != request.UserInfo.Username:
+				return deny(fmt.Sprintf("%s cannot approve on behalf of %s", request.UserInfo.Username, user))
+			case !authenticated(request.UserInfo):
+				return deny("only authenticated users can approve a tweet")
+			case author == "":
+				return deny("a tweet without a recorded author cannot be approved")
+			case user == author:
+				return deny("the author cannot approve their own tweet")
+			}
+		}
+	}
+	if textChanged && tweet.Spec.Text != "" {
+		policies, err := s.policyClient.ListTweetPolicies(ctx)
+		if err != nil {
+			return deny(fmt.Sprintf("failed to get tweet policies: %v", err))
+		}
+		if violation := policy.Check(policies, request.Namespace, tweet.Spec.Text); violation != nil {
+			return deny(violation.Error())
+		}
+	}
+	return allow()
+}
+
+func authenticated(user authenticationv1.UserInfo) bool {
+	if user.Username == "" || user.Username == "system:anonymous" {
+		return false
+	}
+	for _, group := range user.Groups {
+		if group == "system:authenticated" {
+			return true
+		}
+	}
+	return false
+}
+
+// added returns the users in new that are not in old.
+func added(old, new []string) []string {
+	known := map[string]bool{}
+	for _, user := range old {
+		known[user] = true
+	}
+	result := []string{}
+	for _, user := range new {
+		if !known[user] {
+			result = append(result, user)
+		}
+	}
+	return result
+}
+
+func findDuplicate(users []string) string {
+	seen := map[string]bool{}
+	for _, user := range users {
+		if seen[user] {
+			return user
+		}
+		seen[user] = true
+	}
+	return ""
+}
+
+// escapePointer escapes a key for use in a JSON pointer.
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+func allow() *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{Allowed: true}
+}
+
+func deny(message string) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Message: message,
+			Reason:  metav1.StatusReasonForbidden,
+			Code:    http.StatusForbidden,
+		},
+	}
+}
@@ -0,0 +1,253 @@
+package admission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+	v1 "github.com/jonatanblue/tweet-operator/pkg/apis/example.com/v1"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/k8sclient"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Only Tweets in the marketing namespace need a disclosure
+var policies = policyClientStub{{
+	Name:             "disclosure",
+	Namespaces:       []string{"marketing"},
+	RequiredPatterns: []string{"#ad"},
+}}
+
+var (
+	alice = authenticationv1.UserInfo{Username: "alice", Groups: []string{"system:authenticated"}}
+	bob   = authenticationv1.UserInfo{Username: "bob", Groups: []string{"system:authenticated"}}
+)
+
+func Test_ValidateTweet(t *testing.T) {
+	tests := map[string]struct {
+		operation admissionv1.Operation
+		namespace string
+		user      authenticationv1.UserInfo
+		old       *v1.Tweet
+		new       *v1.Tweet
+		err       string
+	}{
+		"create by author": {
+			operation: admissionv1.Create,
+			user:      alice,
+			new:       newTweet("alice", "Hello World"),
+		},
+		"create with another author": {
+			operation: admissionv1.Create,
+			user:      bob,
+			new:       newTweet("alice", "Hello World"),
+			err:       "the example.com/author annotation must name the user creating the tweet",
+		},
+		"create approved": {
+			operation: admissionv1.Create,
+			user:      alice,
+			new:       newTweet("alice", "Hello World", "bob"),
+			err:       "a tweet cannot be approved before it is created",
+		},
+		"approved by another user": {
+			operation: admissionv1.Update,
+			user:      bob,
+			old:       newTweet("alice", "Hello World"),
+			new:       newTweet("alice", "Hello World", "bob"),
+		},
+		"approved by the author": {
+			operation: admissionv1.Update,
+			user:      alice,
+			old:       newTweet("alice", "Hello World"),
+			new:       newTweet("alice", "Hello World", "alice"),
+			err:       "the author cannot approve their own tweet",
+		},
+		"approved on behalf of another user": {
+			operation: admissionv1.Update,
+			user:      alice,
+			old:       newTweet("alice", "Hello World"),
+			new:       newTweet("alice", "Hello World", "bob"),
+			err:       "alice cannot approve on behalf of bob",
+		},
+		"approved by an anonymous user": {
+			operation: admissionv1.Update,
+			user:      authenticationv1.UserInfo{Username: "system:anonymous", Groups: []string{"system:unauthenticated"}},
+			old:       newTweet("alice", "Hello World"),
+			new:       newTweet("alice", "Hello World", "system:anonymous"),
+			err:       "only authenticated users can approve a tweet",
+		},
+		"approved twice": {
+			operation: admissionv1.Update,
+			user:      bob,
+			old:       newTweet("alice", "Hello World", "bob"),
+			new:       newTweet("alice", "Hello World", "bob", "bob"),
+			err:       "bob approved the tweet more than once",
+		},
+		"approved without an author": {
+			operation: admissionv1.Update,
+			user:      bob,
+			old:       newTweet("", "Hello World"),
+			new:       newTweet("", "Hello World", "bob"),
+			err:       "a tweet without a recorded author cannot be approved",
+		},
+		"author changed": {
+			operation: admissionv1.Update,
+			user:      bob,
+			old:       newTweet("alice", "Hello World"),
+			new:       newTweet("bob", "Hello World"),
+			err:       "the example.com/author annotation cannot be changed",
+		},
+		"text changed keeping approvals": {
+			operation: admissionv1.Update,
+			user:      alice,
+			old:       newTweet("alice", "Hello World", "bob"),
+			new:       newTweet("alice", "Goodbye World", "bob"),
+			err:       "approvals must be removed when the text or targets change",
+		},
+		"text changed removing approvals": {
+			operation: admissionv1.Update,
+			user:      alice,
+			old:       newTweet("alice", "Hello World", "bob"),
+			new:       newTweet("alice", "Goodbye World"),
+		},
+		"create violating policy": {
+			operation: admissionv1.Create,
+			namespace: "marketing",
+			user:      alice,
+			new:       newTweet("alice", "Buy now"),
+			err:       `violates rule requiredPatterns[0] of TweetPolicy disclosure: text does not match "#ad"`,
+		},
+		"create complying with policy": {
+			operation: admissionv1.Create,
+			namespace: "marketing",
+			user:      alice,
+			new:       newTweet("alice", "Buy now #ad"),
+		},
+		"text changed violating policy": {
+			operation: admissionv1.Update,
+			namespace: "marketing",
+			user:      alice,
+			old:       newTweet("alice", "Buy now #ad"),
+			new:       newTweet("alice", "Buy now"),
+			err:       `violates rule requiredPatterns[0] of TweetPolicy disclosure: text does not match "#ad"`,
+		},
+		"status of tweet predating policy": {
+			operation: admissionv1.Update,
+			namespace: "marketing",
+			user:      authenticationv1.UserInfo{Username: "system:serviceaccount:default:tweet-operator-sa"},
+			old:       newTweet("alice", "Buy now"),
+			new:       newTweet("alice", "Buy now"),
+		},
+		"other approvals kept by the operator": {
+			operation: admissionv1.Update,
+			user:      authenticationv1.UserInfo{Username: "system:serviceaccount:default:tweet-operator-sa"},
+			old:       newTweet("alice", "Hello World", "bob"),
+			new:       newTweet("alice", "Hello World", "bob"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			request := &admissionv1.AdmissionRequest{
+				UID:       "1234",
+				Namespace: test.namespace,
+				Operation: test.operation,
+				UserInfo:  test.user,
+				Object:    runtime.RawExtension{Object: test.new},
+			}
+			if test.old != nil {
+				request.OldObject = runtime.RawExtension{Object: test.old}
+			}
+			response := review(t, PathValidateTweets, request)
+			assert.Equal(t, "1234", string(response.UID))
+			if test.err != "" {
+				assert.False(t, response.Allowed)
+				assert.Equal(t, test.err, response.Result.Message)
+				return
+			}
+			assert.True(t, response.Allowed)
+		})
+	}
+}
+
+func Test_MutateTweet(t *testing.T) {
+	tests := map[string]struct {
+		annotations map[string]string
+		patch       string
+	}{
+		"without annotations": {
+			patch: `[{"op":"add","path":"/metadata/annotations","value":{"example.com/author":"alice"}}]`,
+		},
+		"with annotations": {
+			annotations: map[string]string{"note": "draft"},
+			patch:       `[{"op":"add","path":"/metadata/annotations/example.com~1author","value":"alice"}]`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tweet := newTweet("", "Hello World")
+			tweet.Annotations = test.annotations
+			response := review(t, PathMutateTweets, &admissionv1.AdmissionRequest{
+				UID:       "1234",
+				Operation: admissionv1.Create,
+				UserInfo:  alice,
+				Object:    runtime.RawExtension{Object: tweet},
+			})
+			assert.True(t, response.Allowed)
+			assert.Equal(t, admissionv1.PatchTypeJSONPatch, *response.PatchType)
+			assert.JSONEq(t, test.patch, string(response.Patch))
+		})
+	}
+}
+
+func Test_MutateTweetUpdate(t *testing.T) {
+	response := review(t, PathMutateTweets, &admissionv1.AdmissionRequest{
+		UID:       "1234",
+		Operation: admissionv1.Update,
+		UserInfo:  bob,
+		Object:    runtime.RawExtension{Object: newTweet("alice", "Hello World")},
+	})
+	assert.True(t, response.Allowed)
+	assert.Empty(t, response.Patch)
+}
+
+func review(t *testing.T, path string, request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	body, err := json.Marshal(admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request:  request,
+	})
+	assert.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	NewServer(policies, logr.Discard()).Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body)))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	result := admissionv1.AdmissionReview{}
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+	assert.Equal(t, "AdmissionReview", result.Kind)
+	return result.Response
+}
+
+type policyClientStub []tweettypes.TweetPolicy
+
+func (stub policyClientStub) ListTweetPolicies(ctx context.Context) ([]tweettypes.TweetPolicy, error) {
+	return stub, nil
+}
+
+func newTweet(author, text string, approvals ...string) *v1.Tweet {
+	tweet := &v1.Tweet{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello-world", Namespace: "default"},
+		Spec:       v1.TweetSpec{Text: text, Approvals: approvals},
+	}
+	if author != "" {
+		tweet.Annotations = map[string]string{k8sclient.AuthorAnnotation: author}
+	}
+	return tweet
+}
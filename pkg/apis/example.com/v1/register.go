@@ -25,7 +25,7 @@ func init() {
 }
 
 func addKnownTypes(scheme *runtime.Scheme) error {
-	scheme.AddKnownTypes(SchemeGroupVersion, &Tweet{}, &TweetList{})
+	scheme.AddKnownTypes(SchemeGroupVersion, &Tweet{}, &TweetList{}, &Account{}, &AccountList{}, &TweetReplies{}, &TweetRepliesList{}, &MentionWatch{}, &MentionWatchList{}, &AutoReply{}, &AutoReplyList{}, &TweetPolicy{}, &TweetPolicyList{})
 
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
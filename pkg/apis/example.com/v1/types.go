@@ -16,13 +16,65 @@ type Tweet struct {
 
 type TweetSpec struct {
 	Text string `json:"text,omitempty"`
+	// Targets names the accounts to post to: Account resources, or twitter
+	// for the account configured through the operator's environment. Empty
+	// means the operator's default target.
+	Targets []string `json:"targets,omitempty"`
+	// Approvals lists the users who approved the Tweet for posting. Each
+	// user may only add themselves, and not to a Tweet they created.
+	Approvals []string `json:"approvals,omitempty"`
 }
 
 type TweetStatus struct {
-	ID       int64 `json:"id,omitempty"`
-	Likes    int64 `json:"likes,omitempty"`
-	Retweets int64 `json:"retweets,omitempty"`
-	Replies  int64 `json:"replies,omitempty"`
+	// Targets holds the post on each target, one entry per target
+	Targets []TargetStatus `json:"targets,omitempty"`
+
+	// The post of a Tweet from before targets existed. It is moved into
+	// Targets, under the default target, on the next update.
+	ID       int64  `json:"id,omitempty"`
+	RemoteID string `json:"remoteID,omitempty"`
+	Likes    int64  `json:"likes,omitempty"`
+	Retweets int64  `json:"retweets,omitempty"`
+	Replies  int64  `json:"replies,omitempty"`
+}
+
+type TargetStatus struct {
+	Name string `json:"name"`
+	ID   int64  `json:"id,omitempty"`
+	// RemoteID is how the platform itself refers to the post, where the
+	// numeric ID alone is not enough, such as a Bluesky record URI
+	RemoteID  string `json:"remoteID,omitempty"`
+	URL       string `json:"url,omitempty"`
+	Likes     int64  `json:"likes,omitempty"`
+	Retweets  int64  `json:"retweets,omitempty"`
+	Replies   int64  `json:"replies,omitempty"`
+	Quotes    int64  `json:"quotes,omitempty"`
+	Bookmarks int64  `json:"bookmarks,omitempty"`
+	// Impressions, URLLinkClicks and ProfileClicks are only known to the
+	// author of the post, and only where the platform reports them
+	Impressions   int64              `json:"impressions,omitempty"`
+	URLLinkClicks int64              `json:"urlLinkClicks,omitempty"`
+	ProfileClicks int64              `json:"profileClicks,omitempty"`
+	Conditions    []metav1.Condition `json:"conditions,omitempty"`
+	// ApprovedBy lists the approvers of the text that was posted
+	ApprovedBy []string `json:"approvedBy,omitempty"`
+
+	// History holds snapshots of the metrics, oldest first, taken when they
+	// change. Only the most recent ones are kept.
+	History []MetricsSnapshot `json:"history,omitempty"`
+	// Derived from History. The peaks cover the whole life of the post,
+	// including snapshots no longer kept.
+	LikesLastHour       int64 `json:"likesLastHour,omitempty"`
+	RetweetsLastHour    int64 `json:"retweetsLastHour,omitempty"`
+	PeakLikesPerHour    int64 `json:"peakLikesPerHour,omitempty"`
+	PeakRetweetsPerHour int64 `json:"peakRetweetsPerHour,omitempty"`
+}
+
+type MetricsSnapshot struct {
+	Time     metav1.Time `json:"time"`
+	Likes    int64       `json:"likes,omitempty"`
+	Retweets int64       `json:"retweets,omitempty"`
+	Replies  int64       `json:"replies,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -32,3 +84,225 @@ type TweetList struct {
 
 	Items []Tweet `json:"items,omitempty"`
 }
+
+// Platform is a service an Account posts to
+type Platform string
+
+const (
+	PlatformMastodon = Platform("mastodon")
+	PlatformBluesky  = Platform("bluesky")
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type Account struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AccountSpec `json:"spec,omitempty"`
+}
+
+type AccountSpec struct {
+	Platform Platform `json:"platform"`
+	// InstanceURL is the server the account lives on, such as
+	// https://mastodon.social, or the PDS of a Bluesky account
+	InstanceURL string `json:"instanceURL,omitempty"`
+	Username    string `json:"username"`
+	// AccessTokenSecretRef names the Secret key holding the access token,
+	// or the app password for Bluesky, which is kept out of the Account
+	// itself. The operator's role has to let it read the Secret.
+	AccessTokenSecretRef SecretKeyRef `json:"accessTokenSecretRef"`
+	// RequiredApprovals is how many users other than the author have to
+	// approve a Tweet before it is posted to the account
+	RequiredApprovals int `json:"requiredApprovals,omitempty"`
+}
+
+type SecretKeyRef struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type AccountList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []Account `json:"items,omitempty"`
+}
+
+// TweetReplies holds the most recent replies to the posts of the Tweet of
+// the same name, which owns it. Only the operator writes it.
+// +genclient
+// +resourceName=tweetreplies
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type TweetReplies struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status TweetRepliesStatus `json:"status,omitempty"`
+}
+
+type TweetRepliesStatus struct {
+	Targets []TargetReplies `json:"targets,omitempty"`
+}
+
+type TargetReplies struct {
+	Name string `json:"name"`
+	// Replies holds the most recent replies, newest first
+	Replies []Reply `json:"replies,omitempty"`
+}
+
+type Reply struct {
+	// ID is how the platform refers to the reply
+	ID     string      `json:"id"`
+	Author string      `json:"author"`
+	Text   string      `json:"text"`
+	Time   metav1.Time `json:"time"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type TweetRepliesList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []TweetReplies `json:"items,omitempty"`
+}
+
+// MentionWatch follows the mentions of a target's account. Each new mention
+// is recorded as an event on the MentionWatch, and posted to the webhook if
+// one is set.
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type MentionWatch struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MentionWatchSpec   `json:"spec,omitempty"`
+	Status MentionWatchStatus `json:"status,omitempty"`
+}
+
+type MentionWatchSpec struct {
+	// Target is the account to watch, as in TweetSpec.Targets. Empty means
+	// the default target.
+	Target string `json:"target,omitempty"`
+	// WebhookURL receives a JSON POST for each new mention
+	WebhookURL string `json:"webhookURL,omitempty"`
+}
+
+type MentionWatchStatus struct {
+	// StartedAt is when the watch first polled. Mentions from before then
+	// are not reported.
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+	// SinceID is the last mention reported, which polls start after
+	SinceID string `json:"sinceID,omitempty"`
+	// Mentions counts the mentions reported since the watch started
+	Mentions        int64        `json:"mentions,omitempty"`
+	LastMentionTime *metav1.Time `json:"lastMentionTime,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type MentionWatchList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []MentionWatch `json:"items,omitempty"`
+}
+
+// AutoReply answers the mentions of a target's account that match its
+// rules with a reply rendered from a template.
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type AutoReply struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AutoReplySpec   `json:"spec,omitempty"`
+	Status AutoReplyStatus `json:"status,omitempty"`
+}
+
+type AutoReplySpec struct {
+	// Target is the account whose mentions are answered, as in
+	// TweetSpec.Targets. Empty means the default target.
+	Target string `json:"target,omitempty"`
+	// Regex and Keywords select the mentions to answer: those the regex
+	// matches, or that contain any of the keywords, ignoring case
+	Regex    string   `json:"regex,omitempty"`
+	Keywords []string `json:"keywords,omitempty"`
+	// Template is the reply, as a Go text/template given the mention's
+	// Author, Text and URL, and Groups, the submatches of the regex
+	Template string `json:"template"`
+	// Cooldown is how long an author is not answered again after a reply.
+	// Defaults to an hour.
+	Cooldown *metav1.Duration `json:"cooldown,omitempty"`
+	// DailyLimit caps the replies in any 24 hours. Defaults to 50.
+	DailyLimit int `json:"dailyLimit,omitempty"`
+}
+
+type AutoReplyStatus struct {
+	// StartedAt is when the auto-reply first polled. Mentions from before
+	// then are not answered.
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+	// SinceID is the last mention looked at, which polls start after
+	SinceID string `json:"sinceID,omitempty"`
+	// Replies counts the replies posted since the auto-reply started
+	Replies int64 `json:"replies,omitempty"`
+	// Answered holds the mentions answered recently enough to count
+	// towards the cooldown or the daily limit, newest first
+	Answered []AnsweredMention `json:"answered,omitempty"`
+}
+
+type AnsweredMention struct {
+	// ID is how the platform refers to the mention
+	ID     string `json:"id"`
+	Author string `json:"author"`
+	// ReplyID is empty while the reply is being posted. A mention is
+	// recorded before it is answered, so it is never answered twice.
+	ReplyID string      `json:"replyID,omitempty"`
+	Time    metav1.Time `json:"time"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type AutoReplyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []AutoReply `json:"items,omitempty"`
+}
+
+// TweetPolicy restricts what Tweets may say. It applies to the Tweets of
+// the namespaces it lists, or of all namespaces when it lists none. A Tweet
+// that violates it is neither admitted nor posted.
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type TweetPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec TweetPolicySpec `json:"spec,omitempty"`
+}
+
+type TweetPolicySpec struct {
+	// Namespaces the policy applies to. Empty means all namespaces.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// DenyPatterns are regular expressions no Tweet may match, such as
+	// profanity, internal hostnames or strings that look like secrets
+	DenyPatterns []string `json:"denyPatterns,omitempty"`
+	// RequiredPatterns are regular expressions every Tweet has to match,
+	// such as a disclosure hashtag
+	RequiredPatterns []string `json:"requiredPatterns,omitempty"`
+	// AllowedLinkDomains are the only domains links may point to, along
+	// with their subdomains. Empty means links are not restricted.
+	AllowedLinkDomains []string `json:"allowedLinkDomains,omitempty"`
+	// MaxMentions caps the accounts a Tweet may mention. Unset means no
+	// limit.
+	MaxMentions *int `json:"maxMentions,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type TweetPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []TweetPolicy `json:"items,omitempty"`
+}
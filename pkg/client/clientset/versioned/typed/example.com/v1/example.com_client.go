@@ -28,7 +28,12 @@ import (
 
 type ExampleV1Interface interface {
 	RESTClient() rest.Interface
+	AccountsGetter
+	AutoRepliesGetter
+	MentionWatchesGetter
 	TweetsGetter
+	TweetPoliciesGetter
+	TweetRepliesesGetter
 }
 
 // ExampleV1Client is used to interact with features provided by the example.com group.
@@ -36,10 +41,30 @@ type ExampleV1Client struct {
 	restClient rest.Interface
 }
 
+func (c *ExampleV1Client) Accounts(namespace string) AccountInterface {
+	return newAccounts(c, namespace)
+}
+
+func (c *ExampleV1Client) AutoReplies(namespace string) AutoReplyInterface {
+	return newAutoReplies(c, namespace)
+}
+
+func (c *ExampleV1Client) MentionWatches(namespace string) MentionWatchInterface {
+	return newMentionWatches(c, namespace)
+}
+
 func (c *ExampleV1Client) Tweets(namespace string) TweetInterface {
 	return newTweets(c, namespace)
 }
 
+func (c *ExampleV1Client) TweetPolicies() TweetPolicyInterface {
+	return newTweetPolicies(c)
+}
+
+func (c *ExampleV1Client) TweetReplieses(namespace string) TweetRepliesInterface {
+	return newTweetReplieses(c, namespace)
+}
+
 // NewForConfig creates a new ExampleV1Client for the given config.
 // NewForConfig is equivalent to NewForConfigAndClient(c, httpClient),
 // where httpClient was generated with rest.HTTPClientFor(c).
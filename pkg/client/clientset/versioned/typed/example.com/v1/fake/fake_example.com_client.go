@@ -28,10 +28,30 @@ type FakeExampleV1 struct {
 	*testing.Fake
 }
 
+func (c *FakeExampleV1) Accounts(namespace string) v1.AccountInterface {
+	return &FakeAccounts{c, namespace}
+}
+
+func (c *FakeExampleV1) AutoReplies(namespace string) v1.AutoReplyInterface {
+	return &FakeAutoReplies{c, namespace}
+}
+
+func (c *FakeExampleV1) MentionWatches(namespace string) v1.MentionWatchInterface {
+	return &FakeMentionWatches{c, namespace}
+}
+
 func (c *FakeExampleV1) Tweets(namespace string) v1.TweetInterface {
 	return &FakeTweets{c, namespace}
 }
 
+func (c *FakeExampleV1) TweetPolicies() v1.TweetPolicyInterface {
+	return &FakeTweetPolicies{c}
+}
+
+func (c *FakeExampleV1) TweetReplieses(namespace string) v1.TweetRepliesInterface {
+	return &FakeTweetReplieses{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeExampleV1) RESTClient() rest.Interface {
@@ -18,4 +18,14 @@ limitations under the License.
 
 package v1
 
+type AccountExpansion interface{}
+
+type AutoReplyExpansion interface{}
+
+type MentionWatchExpansion interface{}
+
 type TweetExpansion interface{}
+
+type TweetPolicyExpansion interface{}
+
+type TweetRepliesExpansion interface{}
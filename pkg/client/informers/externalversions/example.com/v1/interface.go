@@ -24,8 +24,18 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// Accounts returns a AccountInformer.
+	Accounts() AccountInformer
+	// AutoReplies returns a AutoReplyInformer.
+	AutoReplies() AutoReplyInformer
+	// MentionWatches returns a MentionWatchInformer.
+	MentionWatches() MentionWatchInformer
 	// Tweets returns a TweetInformer.
 	Tweets() TweetInformer
+	// TweetPolicies returns a TweetPolicyInformer.
+	TweetPolicies() TweetPolicyInformer
+	// TweetReplieses returns a TweetRepliesInformer.
+	TweetReplieses() TweetRepliesInformer
 }
 
 type version struct {
@@ -39,7 +49,32 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// Accounts returns a AccountInformer.
+func (v *version) Accounts() AccountInformer {
+	return &accountInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// AutoReplies returns a AutoReplyInformer.
+func (v *version) AutoReplies() AutoReplyInformer {
+	return &autoReplyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// MentionWatches returns a MentionWatchInformer.
+func (v *version) MentionWatches() MentionWatchInformer {
+	return &mentionWatchInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // Tweets returns a TweetInformer.
 func (v *version) Tweets() TweetInformer {
 	return &tweetInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// TweetPolicies returns a TweetPolicyInformer.
+func (v *version) TweetPolicies() TweetPolicyInformer {
+	return &tweetPolicyInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// TweetReplieses returns a TweetRepliesInformer.
+func (v *version) TweetReplieses() TweetRepliesInformer {
+	return &tweetRepliesInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
@@ -53,8 +53,18 @@ func (f *genericInformer) Lister() cache.GenericLister {
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
 	// Group=example.com, Version=v1
+	case v1.SchemeGroupVersion.WithResource("accounts"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Example().V1().Accounts().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("autoreplies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Example().V1().AutoReplies().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("mentionwatches"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Example().V1().MentionWatches().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("tweets"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Example().V1().Tweets().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("tweetpolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Example().V1().TweetPolicies().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("tweetreplies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Example().V1().TweetReplieses().Informer()}, nil
 
 	}
 
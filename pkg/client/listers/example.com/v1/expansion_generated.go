@@ -18,6 +18,30 @@ limitations under the License.
 
 package v1
 
+// AccountListerExpansion allows custom methods to be added to
+// AccountLister.
+type AccountListerExpansion interface{}
+
+// AccountNamespaceListerExpansion allows custom methods to be added to
+// AccountNamespaceLister.
+type AccountNamespaceListerExpansion interface{}
+
+// AutoReplyListerExpansion allows custom methods to be added to
+// AutoReplyLister.
+type AutoReplyListerExpansion interface{}
+
+// AutoReplyNamespaceListerExpansion allows custom methods to be added to
+// AutoReplyNamespaceLister.
+type AutoReplyNamespaceListerExpansion interface{}
+
+// MentionWatchListerExpansion allows custom methods to be added to
+// MentionWatchLister.
+type MentionWatchListerExpansion interface{}
+
+// MentionWatchNamespaceListerExpansion allows custom methods to be added to
+// MentionWatchNamespaceLister.
+type MentionWatchNamespaceListerExpansion interface{}
+
 // TweetListerExpansion allows custom methods to be added to
 // TweetLister.
 type TweetListerExpansion interface{}
@@ -25,3 +49,15 @@ type TweetListerExpansion interface{}
 // TweetNamespaceListerExpansion allows custom methods to be added to
 // TweetNamespaceLister.
 type TweetNamespaceListerExpansion interface{}
+
+// TweetPolicyListerExpansion allows custom methods to be added to
+// TweetPolicyLister.
+type TweetPolicyListerExpansion interface{}
+
+// TweetRepliesListerExpansion allows custom methods to be added to
+// TweetRepliesLister.
+type TweetRepliesListerExpansion interface{}
+
+// TweetRepliesNamespaceListerExpansion allows custom methods to be added to
+// TweetRepliesNamespaceLister.
+type TweetRepliesNamespaceListerExpansion interface{}
@@ -0,0 +1,505 @@
+// Package blueskyclient implements the operator's TwitterClient on top of
+// the Bluesky XRPC API, so Tweets can be posted to a Bluesky account.
+package blueskyclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jonatanblue/tweet-operator/pkg/libs/twitterclient"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+)
+
+// DefaultServiceURL is the PDS of accounts hosted by Bluesky itself
+const DefaultServiceURL = "https://bsky.social"
+
+const (
+	collectionPost = "app.bsky.feed.post"
+	feedPageSize   = 100
+	// threadDepth is how many levels of replies are fetched below a post
+	threadDepth = 6
+	// getPosts takes at most 25 URIs per request
+	postsBatchSize = 25
+	// rateLimitWindow is assumed when a 429 comes without a reset header
+	rateLimitWindow = 5 * time.Minute
+)
+
+// Endpoints, named by their XRPC method
+const (
+	endpointCreateSession = "com.atproto.server.createSession"
+	endpointResolveHandle = "com.atproto.identity.resolveHandle"
+	endpointCreateRecord  = "com.atproto.repo.createRecord"
+	endpointDeleteRecord  = "com.atproto.repo.deleteRecord"
+	endpointGetAuthorFeed = "app.bsky.feed.getAuthorFeed"
+	endpointGetPosts      = "app.bsky.feed.getPosts"
+	endpointGetPostThread = "app.bsky.feed.getPostThread"
+)
+
+// APIError is returned for non-2xx responses.
+type APIError struct {
+	StatusCode int
+	Name       string `json:"error"`
+	Message    string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("bluesky: %d %s: %s", e.StatusCode, e.Name, e.Message)
+}
+
+type postView struct {
+	URI    string `json:"uri"`
+	Author struct {
+		Handle string `json:"handle"`
+	} `json:"author"`
+	Record struct {
+		Text string `json:"text"`
+	} `json:"record"`
+	LikeCount   int64 `json:"likeCount"`
+	RepostCount int64 `json:"repostCount"`
+	ReplyCount  int64 `json:"replyCount"`
+	QuoteCount  int64 `json:"quoteCount"`
+}
+
+// threadView is a post in a thread with the replies below it. Replies that
+// are deleted or blocked come without a post.
+type threadView struct {
+	Post *struct {
+		URI    string `json:"uri"`
+		Author struct {
+			Handle string `json:"handle"`
+		} `json:"author"`
+		Record struct {
+			Text      string    `json:"text"`
+			CreatedAt time.Time `json:"createdAt"`
+		} `json:"record"`
+	} `json:"post"`
+	Replies []threadView `json:"replies"`
+}
+
+type session struct {
+	accessJwt string
+	did       string
+}
+
+// BlueskyClient posts to and reads from one Bluesky account, logged in with
+// an app password. Likes, reposts and replies are reported as likes,
+// retweets and replies. The record URI of a post is kept as its RemoteID,
+// and the timestamp in its record key as its ID.
+type BlueskyClient struct {
+	httpClient       *http.Client
+	serviceURL       string
+	identifier       string
+	appPassword      string
+	timelineMaxPages int
+	now              func() time.Time
+
+	mu      sync.Mutex
+	session *session
+}
+
+func NewBlueskyClient(
+	httpClient *http.Client,
+	serviceURL string,
+	identifier string,
+	appPassword string,
+	timelineMaxPages int,
+) *BlueskyClient {
+	return &BlueskyClient{
+		httpClient:       httpClient,
+		serviceURL:       strings.TrimSuffix(serviceURL, "/"),
+		identifier:       identifier,
+		appPassword:      appPassword,
+		timelineMaxPages: timelineMaxPages,
+		now:              time.Now,
+	}
+}
+
+// VerifyCredentials logs in, which fails for a wrong app password.
+func (c *BlueskyClient) VerifyCredentials(ctx context.Context) error {
+	_, err := c.createSession(ctx)
+	return err
+}
+
+// GetTweetsForUser returns the account's posts, newest first, leaving out
+// reposts of other accounts' posts. It pages back until all tracked posts
+// are found or timelineMaxPages is reached, then looks up the tracked posts
+// it did not come across.
+func (c *BlueskyClient) GetTweetsForUser(ctx context.Context, userName string, trackedIDs ...int64) (tweettypes.Tweets, error) {
+	missing := map[int64]bool{}
+	for _, id := range trackedIDs {
+		if id > 0 {
+			missing[id] = true
+		}
+	}
+
+	result := tweettypes.Tweets{}
+	cursor := ""
+	for page := 0; page < c.timelineMaxPages; page++ {
+		query := url.Values{
+			"actor": {userName},
+			"limit": {strconv.Itoa(feedPageSize)},
+		}
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+		var resp struct {
+			Feed []struct {
+				Post postView `json:"post"`
+				// Set for reposts
+				Reason *struct {
+					Type string `json:"$type"`
+				} `json:"reason"`
+			} `json:"feed"`
+			Cursor string `json:"cursor"`
+		}
+		if err := c.do(ctx, endpointGetAuthorFeed, http.MethodGet, query, nil, &resp); err != nil {
+			return nil, err
+		}
+		for _, item := range resp.Feed {
+			if item.Reason != nil {
+				continue
+			}
+			tweet, ok := item.Post.toTweet()
+			if !ok {
+				continue
+			}
+			delete(missing, tweet.Status.ID)
+			result = append(result, tweet)
+		}
+		if len(missing) == 0 || resp.Cursor == "" {
+			break
+		}
+		cursor = resp.Cursor
+	}
+
+	found, err := c.getPosts(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	return append(result, found...), nil
+}
+
+// getPosts looks up the account's own posts by ID. Deleted posts are left
+// out of the response.
+func (c *BlueskyClient) getPosts(ctx context.Context, ids map[int64]bool) (tweettypes.Tweets, error) {
+	result := tweettypes.Tweets{}
+	if len(ids) == 0 {
+		return result, nil
+	}
+	s, err := c.getSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	batch := []string{}
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		query := url.Values{"uris": batch}
+		batch = []string{}
+		var resp struct {
+			Posts []postView `json:"posts"`
+		}
+		if err := c.do(ctx, endpointGetPosts, http.MethodGet, query, nil, &resp); err != nil {
+			return err
+		}
+		for _, post := range resp.Posts {
+			if tweet, ok := post.toTweet(); ok {
+				result = append(result, tweet)
+			}
+		}
+		return nil
+	}
+	for id := range ids {
+		batch = append(batch, postURI(s.did, encodeTID(id)))
+		if len(batch) == postsBatchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *BlueskyClient) PostTweet(ctx context.Context, tweet *tweettypes.Tweet) (int64, error) {
+	// A reply would need references to the root and parent records, which
+	// nothing asks for, as the client does not fetch mentions
+	if tweet.Spec.InReplyTo != "" {
+		return 0, fmt.Errorf("replies are not supported on bluesky")
+	}
+	s, err := c.getSession(ctx)
+	if err != nil {
+		return 0, err
+	}
+	facets, err := c.facets(ctx, tweet.Spec.Text)
+	if err != nil {
+		return 0, err
+	}
+	record := map[string]interface{}{
+		"$type":     collectionPost,
+		"text":      tweet.Spec.Text,
+		"createdAt": c.now().UTC().Format(time.RFC3339Nano),
+	}
+	if len(facets) > 0 {
+		record["facets"] = facets
+	}
+	body := map[string]interface{}{
+		"repo":       s.did,
+		"collection": collectionPost,
+		"record":     record,
+	}
+	var resp struct {
+		URI string `json:"uri"`
+	}
+	if err := c.do(ctx, endpointCreateRecord, http.MethodPost, nil, body, &resp); err != nil {
+		return 0, err
+	}
+	_, rkey, err := parsePostURI(resp.URI)
+	if err != nil {
+		return 0, err
+	}
+	return decodeTID(rkey)
+}
+
+// DeleteTweet deletes the post by its record URI, or by the record key its
+// ID stands for if no URI was recorded.
+func (c *BlueskyClient) DeleteTweet(ctx context.Context, tweet *tweettypes.Tweet) error {
+	s, err := c.getSession(ctx)
+	if err != nil {
+		return err
+	}
+	rkey := encodeTID(tweet.Status.ID)
+	if tweet.Status.RemoteID != "" {
+		_, rkey, err = parsePostURI(tweet.Status.RemoteID)
+		if err != nil {
+			return err
+		}
+	}
+	body := map[string]interface{}{
+		"repo":       s.did,
+		"collection": collectionPost,
+		"rkey":       rkey,
+	}
+	var resp struct{}
+	return c.do(ctx, endpointDeleteRecord, http.MethodPost, nil, body, &resp)
+}
+
+// GetReplies returns the most recent posts in the thread below the post,
+// including replies to replies.
+func (c *BlueskyClient) GetReplies(ctx context.Context, tweet *tweettypes.Tweet, max int) ([]tweettypes.Reply, error) {
+	uri := tweet.Status.RemoteID
+	if uri == "" {
+		s, err := c.getSession(ctx)
+		if err != nil {
+			return nil, err
+		}
+		uri = postURI(s.did, encodeTID(tweet.Status.ID))
+	}
+	query := url.Values{
+		"uri":   {uri},
+		"depth": {strconv.Itoa(threadDepth)},
+	}
+	var resp struct {
+		Thread threadView `json:"thread"`
+	}
+	if err := c.do(ctx, endpointGetPostThread, http.MethodGet, query, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	replies := []tweettypes.Reply{}
+	var walk func(views []threadView)
+	walk = func(views []threadView) {
+		for _, view := range views {
+			if view.Post != nil {
+				replies = append(replies, tweettypes.Reply{
+					ID:     view.Post.URI,
+					Author: "@" + view.Post.Author.Handle,
+					Text:   view.Post.Record.Text,
+					Time:   view.Post.Record.CreatedAt,
+				})
+			}
+			walk(view.Replies)
+		}
+	}
+	walk(resp.Thread.Replies)
+	sort.SliceStable(replies, func(i, j int) bool {
+		return replies[i].Time.After(replies[j].Time)
+	})
+	if len(replies) > max {
+		replies = replies[:max]
+	}
+	return replies, nil
+}
+
+// resolveHandle returns the DID of a handle, or false if there is no such
+// handle.
+func (c *BlueskyClient) resolveHandle(ctx context.Context, handle string) (string, bool, error) {
+	var resp struct {
+		DID string `json:"did"`
+	}
+	err := c.do(ctx, endpointResolveHandle, http.MethodGet, url.Values{"handle": {handle}}, nil, &resp)
+	if isInvalidRequest(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return resp.DID, true, nil
+}
+
+func (c *BlueskyClient) getSession(ctx context.Context) (*session, error) {
+	c.mu.Lock()
+	s := c.session
+	c.mu.Unlock()
+	if s != nil {
+		return s, nil
+	}
+	return c.createSession(ctx)
+}
+
+func (c *BlueskyClient) createSession(ctx context.Context) (*session, error) {
+	body := map[string]string{
+		"identifier": c.identifier,
+		"password":   c.appPassword,
+	}
+	var resp struct {
+		AccessJwt string `json:"accessJwt"`
+		DID       string `json:"did"`
+	}
+	if err := c.send(ctx, endpointCreateSession, http.MethodPost, nil, body, "", &resp); err != nil {
+		return nil, err
+	}
+	s := &session{accessJwt: resp.AccessJwt, did: resp.DID}
+	c.mu.Lock()
+	c.session = s
+	c.mu.Unlock()
+	return s, nil
+}
+
+// do calls an XRPC method with the session's access token. An expired
+// session is replaced by a new one and the call is made once more, since
+// the app password never expires.
+func (c *BlueskyClient) do(ctx context.Context, endpoint, method string, query url.Values, body, out interface{}) error {
+	s, err := c.getSession(ctx)
+	if err != nil {
+		return err
+	}
+	err = c.send(ctx, endpoint, method, query, body, s.accessJwt, out)
+	if !isExpiredToken(err) {
+		return err
+	}
+	if s, err = c.createSession(ctx); err != nil {
+		return err
+	}
+	return c.send(ctx, endpoint, method, query, body, s.accessJwt, out)
+}
+
+// send makes one request and decodes the JSON response into out. A 429
+// becomes a twitterclient.RateLimitError, so the reconciler defers the work
+// like it does for Twitter.
+func (c *BlueskyClient) send(ctx context.Context, endpoint, method string, query url.Values, body interface{}, accessJwt string, out interface{}) error {
+	u := c.serviceURL + "/xrpc/" + endpoint
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if accessJwt != "" {
+		req.Header.Set("Authorization", "Bearer "+accessJwt)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &APIError{}
+		if err := json.NewDecoder(resp.Body).Decode(apiErr); err != nil || apiErr.Name == "" {
+			apiErr.Name = http.StatusText(resp.StatusCode)
+		}
+		apiErr.StatusCode = resp.StatusCode
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return &twitterclient.RateLimitError{Endpoint: endpoint, Reset: c.rateLimitReset(resp), Err: apiErr}
+		}
+		return apiErr
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// rateLimitReset reads the ratelimit-reset header, in Unix seconds.
+func (c *BlueskyClient) rateLimitReset(resp *http.Response) time.Time {
+	reset, err := strconv.ParseInt(resp.Header.Get("ratelimit-reset"), 10, 64)
+	if err != nil {
+		return c.now().Add(rateLimitWindow)
+	}
+	return time.Unix(reset, 0)
+}
+
+// toTweet converts a post, or returns false for a post whose record key is
+// not a timestamp identifier. The Bluesky app never creates those, but
+// other clients may.
+func (p postView) toTweet() (tweettypes.Tweet, bool) {
+	_, rkey, err := parsePostURI(p.URI)
+	if err != nil {
+		return tweettypes.Tweet{}, false
+	}
+	id, err := decodeTID(rkey)
+	if err != nil {
+		return tweettypes.Tweet{}, false
+	}
+	return tweettypes.Tweet{
+		Spec: tweettypes.TweetSpec{
+			Text: p.Record.Text,
+		},
+		Status: tweettypes.TweetStatus{
+			ID:       id,
+			RemoteID: p.URI,
+			URL:      "https://bsky.app/profile/" + p.Author.Handle + "/post/" + rkey,
+			Likes:    p.LikeCount,
+			Retweets: p.RepostCount,
+			Replies:  p.ReplyCount,
+			Quotes:   p.QuoteCount,
+		},
+	}, true
+}
+
+func postURI(did, rkey string) string {
+	return "at://" + did + "/" + collectionPost + "/" + rkey
+}
+
+// parsePostURI splits an at://<did>/app.bsky.feed.post/<rkey> URI.
+func parsePostURI(uri string) (did, rkey string, err error) {
+	parts := strings.Split(strings.TrimPrefix(uri, "at://"), "/")
+	if !strings.HasPrefix(uri, "at://") || len(parts) != 3 || parts[1] != collectionPost {
+		return "", "", fmt.Errorf("invalid post URI %q", uri)
+	}
+	return parts[0], parts[2], nil
+}
@@ -0,0 +1,248 @@
+package blueskyclient
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jonatanblue/tweet-operator/pkg/libs/twitterclient"
+	"github.com/jonatanblue/tweet-operator/pkg/testing/fakebluesky"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClient(server *fakebluesky.Server, timelineMaxPages int) *BlueskyClient {
+	return NewBlueskyClient(http.DefaultClient, server.URL+"/", "bob.example.com", "app-password", timelineMaxPages)
+}
+
+func postID(t *testing.T, uri string) int64 {
+	_, rkey, err := parsePostURI(uri)
+	assert.NoError(t, err)
+	id, err := decodeTID(rkey)
+	assert.NoError(t, err)
+	return id
+}
+
+func postURL(uri string) string {
+	return "https://bsky.app/profile/bob.example.com/post/" + uri[strings.LastIndex(uri, "/")+1:]
+}
+
+func Test_Lifecycle(t *testing.T) {
+	server := fakebluesky.New("bob.example.com", "app-password")
+	defer server.Close()
+	server.AddHandle("alice.example.com", "did:plc:alice")
+	existing := server.AddPost("Existing post")
+	server.SetCounts(existing, 1, 2, 3)
+	client := newTestClient(server, 1)
+
+	assert.NoError(t, client.VerifyCredentials(context.TODO()))
+
+	id, err := client.PostTweet(context.TODO(), &tweettypes.Tweet{Spec: tweettypes.TweetSpec{Text: "Hi @alice.example.com, see https://example.com"}})
+	assert.NoError(t, err)
+	posted := server.Posts()[0]
+	assert.Equal(t, postID(t, posted.URI), id)
+	assert.Equal(t, []fakebluesky.Facet{
+		{ByteStart: 27, ByteEnd: 46, Type: facetTypeLink, URI: "https://example.com"},
+		{ByteStart: 3, ByteEnd: 21, Type: facetTypeMention, DID: "did:plc:alice"},
+	}, posted.Facets)
+
+	tweets, err := client.GetTweetsForUser(context.TODO(), "bob.example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, tweettypes.Tweets{
+		{
+			Spec:   tweettypes.TweetSpec{Text: "Hi @alice.example.com, see https://example.com"},
+			Status: tweettypes.TweetStatus{ID: id, RemoteID: posted.URI, URL: postURL(posted.URI)},
+		},
+		{
+			Spec:   tweettypes.TweetSpec{Text: "Existing post"},
+			Status: tweettypes.TweetStatus{ID: postID(t, existing), RemoteID: existing, URL: postURL(existing), Likes: 1, Retweets: 2, Replies: 3},
+		},
+	}, tweets)
+
+	// By record URI
+	err = client.DeleteTweet(context.TODO(), &tweets[0])
+	assert.NoError(t, err)
+	// By ID alone
+	err = client.DeleteTweet(context.TODO(), &tweettypes.Tweet{Status: tweettypes.TweetStatus{ID: postID(t, existing)}})
+	assert.NoError(t, err)
+	assert.Empty(t, server.Posts())
+}
+
+func Test_GetTweetsForUser(t *testing.T) {
+	server := fakebluesky.New("bob.example.com", "app-password")
+	defer server.Close()
+	oldest := server.AddPost("Oldest")
+	for i := 0; i < feedPageSize*2; i++ {
+		server.AddPost("Post " + strconv.Itoa(i))
+	}
+
+	tests := map[string]struct {
+		maxPages   int
+		trackedIDs []int64
+		count      int
+		requests   []string
+	}{
+		"one page": {
+			maxPages: 1,
+			count:    feedPageSize,
+			requests: []string{fakebluesky.EndpointCreateSession, fakebluesky.EndpointGetAuthorFeed},
+		},
+		"tracked post beyond the last page": {
+			maxPages:   1,
+			trackedIDs: []int64{postID(t, oldest)},
+			count:      feedPageSize + 1,
+			requests:   []string{fakebluesky.EndpointCreateSession, fakebluesky.EndpointGetAuthorFeed, fakebluesky.EndpointGetPosts},
+		},
+		"tracked post within max pages": {
+			maxPages:   5,
+			trackedIDs: []int64{postID(t, oldest)},
+			count:      feedPageSize*2 + 1,
+			requests: []string{
+				fakebluesky.EndpointCreateSession,
+				fakebluesky.EndpointGetAuthorFeed,
+				fakebluesky.EndpointGetAuthorFeed,
+				fakebluesky.EndpointGetAuthorFeed,
+			},
+		},
+		"deleted tracked post": {
+			maxPages:   1,
+			trackedIDs: []int64{postID(t, oldest) - 1},
+			count:      feedPageSize,
+			requests:   []string{fakebluesky.EndpointCreateSession, fakebluesky.EndpointGetAuthorFeed, fakebluesky.EndpointGetPosts},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			before := len(server.Requests())
+			tweets, err := newTestClient(server, test.maxPages).GetTweetsForUser(context.TODO(), "bob.example.com", test.trackedIDs...)
+			assert.NoError(t, err)
+			assert.Len(t, tweets, test.count)
+			assert.Equal(t, test.requests, server.Requests()[before:])
+		})
+	}
+}
+
+func Test_Sessions(t *testing.T) {
+	server := fakebluesky.New("bob.example.com", "app-password")
+	defer server.Close()
+	client := newTestClient(server, 1)
+
+	_, err := client.GetTweetsForUser(context.TODO(), "bob.example.com")
+	assert.NoError(t, err)
+
+	// An expired session is replaced and the call made again
+	server.ExpireSessions()
+	_, err = client.GetTweetsForUser(context.TODO(), "bob.example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		fakebluesky.EndpointCreateSession,
+		fakebluesky.EndpointGetAuthorFeed,
+		fakebluesky.EndpointGetAuthorFeed,
+		fakebluesky.EndpointCreateSession,
+		fakebluesky.EndpointGetAuthorFeed,
+	}, server.Requests())
+
+	err = NewBlueskyClient(http.DefaultClient, server.URL, "bob.example.com", "wrong", 1).VerifyCredentials(context.TODO())
+	assert.True(t, IsUnauthorized(err), "unauthorized: %v", err)
+}
+
+func Test_RateLimited(t *testing.T) {
+	server := fakebluesky.New("bob.example.com", "app-password")
+	defer server.Close()
+	server.InjectFault(fakebluesky.EndpointCreateRecord, fakebluesky.Fault{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Hour})
+
+	_, err := newTestClient(server, 1).PostTweet(context.TODO(), &tweettypes.Tweet{Spec: tweettypes.TweetSpec{Text: "Hello World"}})
+	var rateLimitErr *twitterclient.RateLimitError
+	assert.ErrorAs(t, err, &rateLimitErr)
+	assert.Equal(t, fakebluesky.EndpointCreateRecord, rateLimitErr.Endpoint)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), rateLimitErr.Reset, time.Minute)
+	assert.Empty(t, server.Posts())
+}
+
+func Test_ErrorKinds(t *testing.T) {
+	tests := map[string]struct {
+		fault fakebluesky.Fault
+		kind  twitterclient.ErrorKind
+	}{
+		"not found": {
+			fault: fakebluesky.Fault{StatusCode: http.StatusNotFound, Error: "NotFound", Message: "Not Found"},
+			kind:  twitterclient.KindNotFound,
+		},
+		"record not found": {
+			fault: fakebluesky.Fault{StatusCode: http.StatusBadRequest, Error: "RecordNotFound", Message: "Could not locate record"},
+			kind:  twitterclient.KindNotFound,
+		},
+		"unavailable": {
+			fault: fakebluesky.Fault{StatusCode: http.StatusServiceUnavailable, Error: "ServiceUnavailable", Message: "Service Unavailable"},
+			kind:  twitterclient.KindTransient,
+		},
+		"authentication required": {
+			fault: fakebluesky.Fault{StatusCode: http.StatusBadRequest, Error: "AuthenticationRequired", Message: "Authentication Required"},
+			kind:  twitterclient.KindUnauthorized,
+		},
+		"taken down": {
+			fault: fakebluesky.Fault{StatusCode: http.StatusBadRequest, Error: "AccountTakedown", Message: "Account has been taken down"},
+			kind:  twitterclient.KindSuspended,
+		},
+		"invalid request": {
+			fault: fakebluesky.Fault{StatusCode: http.StatusBadRequest, Error: "InvalidRequest", Message: "Invalid rkey"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := fakebluesky.New("bob.example.com", "app-password")
+			defer server.Close()
+			server.InjectFault(fakebluesky.EndpointDeleteRecord, test.fault)
+
+			err := newTestClient(server, 1).DeleteTweet(context.TODO(), &tweettypes.Tweet{Status: tweettypes.TweetStatus{ID: 1}})
+			assert.Error(t, err)
+			assert.Equal(t, test.kind, twitterclient.KindOf(err))
+		})
+	}
+}
+
+func Test_GetReplies(t *testing.T) {
+	server := fakebluesky.New("bob.example.com", "app-password")
+	defer server.Close()
+	uri := server.AddPost("Hello World")
+	first := server.AddReply(uri, "alice.example.com", "Hi")
+	nested := server.AddReply(first, "bob.example.com", "Hi back")
+	last := server.AddReply(uri, "carol.example.com", "Hello")
+	client := newTestClient(server, 1)
+
+	tests := map[string]struct {
+		tweet *tweettypes.Tweet
+		max   int
+		ids   []string
+	}{
+		"by record URI": {
+			tweet: &tweettypes.Tweet{Status: tweettypes.TweetStatus{ID: postID(t, uri), RemoteID: uri}},
+			max:   10,
+			ids:   []string{last, nested, first},
+		},
+		"by ID only": {
+			tweet: &tweettypes.Tweet{Status: tweettypes.TweetStatus{ID: postID(t, uri)}},
+			max:   2,
+			ids:   []string{last, nested},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			replies, err := client.GetReplies(context.TODO(), test.tweet, test.max)
+			assert.NoError(t, err)
+			ids := []string{}
+			for _, r := range replies {
+				ids = append(ids, r.ID)
+			}
+			assert.Equal(t, test.ids, ids)
+			assert.Equal(t, "@carol.example.com", replies[0].Author)
+			assert.Equal(t, "Hello", replies[0].Text)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package blueskyclient
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jonatanblue/tweet-operator/pkg/libs/twitterclient"
+)
+
+// XRPC error names
+const (
+	errorInvalidRequest         = "InvalidRequest"
+	errorExpiredToken           = "ExpiredToken"
+	errorInvalidToken           = "InvalidToken"
+	errorAuthenticationRequired = "AuthenticationRequired"
+	errorAccountTakedown        = "AccountTakedown"
+	errorNotFound               = "NotFound"
+	errorRecordNotFound         = "RecordNotFound"
+)
+
+// Kind classifies the error like the Twitter errors the reconciler acts on.
+// XRPC names most errors, and sends many of them as a 400.
+func (e *APIError) Kind() twitterclient.ErrorKind {
+	switch e.Name {
+	case errorAuthenticationRequired:
+		return twitterclient.KindUnauthorized
+	case errorAccountTakedown:
+		return twitterclient.KindSuspended
+	case errorNotFound, errorRecordNotFound:
+		return twitterclient.KindNotFound
+	}
+	return twitterclient.KindOfStatus(e.StatusCode)
+}
+
+func IsUnauthorized(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) &&
+		(apiErr.StatusCode == http.StatusUnauthorized || apiErr.Name == errorAuthenticationRequired)
+}
+
+func isExpiredToken(err error) bool {
+	return hasErrorName(err, errorExpiredToken, errorInvalidToken)
+}
+
+func isInvalidRequest(err error) bool {
+	return hasErrorName(err, errorInvalidRequest)
+}
+
+func hasErrorName(err error, names ...string) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, name := range names {
+		if apiErr.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package blueskyclient
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+const (
+	facetTypeLink    = "app.bsky.richtext.facet#link"
+	facetTypeMention = "app.bsky.richtext.facet#mention"
+)
+
+var (
+	linkPattern = regexp.MustCompile(`(^|[\s(])(https?://[^\s]+)`)
+	// A handle is a domain name
+	mentionPattern = regexp.MustCompile(`(^|[\s(])(@(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)+[a-zA-Z](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)`)
+)
+
+// facet marks a link or mention in a post, so clients render it as one.
+// Byte offsets count UTF-8 bytes, not characters.
+type facet struct {
+	Index struct {
+		ByteStart int `json:"byteStart"`
+		ByteEnd   int `json:"byteEnd"`
+	} `json:"index"`
+	Features []facetFeature `json:"features"`
+}
+
+type facetFeature struct {
+	Type string `json:"$type"`
+	URI  string `json:"uri,omitempty"`
+	DID  string `json:"did,omitempty"`
+}
+
+// facets finds the links and mentions in text. Bluesky does not do this
+// for the poster, plain text is posted as is.
+func (c *BlueskyClient) facets(ctx context.Context, text string) ([]facet, error) {
+	return parseFacets(text, func(handle string) (string, bool, error) {
+		return c.resolveHandle(ctx, handle)
+	})
+}
+
+// parseFacets finds the links in text, and the mentions of handles resolve
+// knows. Mentions of unknown handles are left as plain text.
+func parseFacets(text string, resolve func(handle string) (did string, ok bool, err error)) ([]facet, error) {
+	facets := []facet{}
+	for _, m := range linkPattern.FindAllStringSubmatchIndex(text, -1) {
+		start, end := m[4], m[5]
+		// Punctuation right after a link ends the sentence, not the link
+		end = start + len(strings.TrimRight(text[start:end], ".,;:!?"))
+		if strings.HasSuffix(text[start:end], ")") && !strings.Contains(text[start:end], "(") {
+			end--
+		}
+		facets = append(facets, newFacet(start, end, facetFeature{Type: facetTypeLink, URI: text[start:end]}))
+	}
+	for _, m := range mentionPattern.FindAllStringSubmatchIndex(text, -1) {
+		start, end := m[4], m[5]
+		did, ok, err := resolve(text[start+1 : end])
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			facets = append(facets, newFacet(start, end, facetFeature{Type: facetTypeMention, DID: did}))
+		}
+	}
+	return facets, nil
+}
+
+func newFacet(start, end int, feature facetFeature) facet {
+	f := facet{Features: []facetFeature{feature}}
+	f.Index.ByteStart = start
+	f.Index.ByteEnd = end
+	return f
+}
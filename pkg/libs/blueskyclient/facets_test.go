@@ -0,0 +1,82 @@
+package blueskyclient
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseFacets(t *testing.T) {
+	handles := map[string]string{
+		"alice.example.com": "did:plc:alice",
+	}
+	resolve := func(handle string) (string, bool, error) {
+		if handle == "broken.example.com" {
+			return "", false, errors.New("connection refused")
+		}
+		did, ok := handles[handle]
+		return did, ok, nil
+	}
+	link := func(start, end int, uri string) facet {
+		return newFacet(start, end, facetFeature{Type: facetTypeLink, URI: uri})
+	}
+	mention := func(start, end int, did string) facet {
+		return newFacet(start, end, facetFeature{Type: facetTypeMention, DID: did})
+	}
+
+	tests := map[string]struct {
+		text   string
+		facets []facet
+		err    string
+	}{
+		"plain text": {
+			text:   "Hello World",
+			facets: []facet{},
+		},
+		"link": {
+			text:   "See https://example.com/a?b=c",
+			facets: []facet{link(4, 29, "https://example.com/a?b=c")},
+		},
+		"punctuation after link": {
+			text:   "See https://example.com. Or (https://example.org)!",
+			facets: []facet{link(4, 23, "https://example.com"), link(29, 48, "https://example.org")},
+		},
+		"link with parentheses": {
+			text:   "https://en.wikipedia.org/wiki/Go_(game)",
+			facets: []facet{link(0, 39, "https://en.wikipedia.org/wiki/Go_(game)")},
+		},
+		"byte offsets after multi-byte characters": {
+			text:   "Grüße 👋 https://example.com",
+			facets: []facet{link(13, 32, "https://example.com")},
+		},
+		"mention": {
+			text:   "@alice.example.com hi",
+			facets: []facet{mention(0, 18, "did:plc:alice")},
+		},
+		"unknown handle": {
+			text:   "hi @nobody.example.com",
+			facets: []facet{},
+		},
+		"email address": {
+			text:   "mail bob@alice.example.com",
+			facets: []facet{},
+		},
+		"resolve error": {
+			text: "hi @broken.example.com",
+			err:  "connection refused",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			facets, err := parseFacets(test.text, resolve)
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.facets, facets)
+		})
+	}
+}
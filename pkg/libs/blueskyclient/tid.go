@@ -0,0 +1,38 @@
+package blueskyclient
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Record keys of posts are timestamp identifiers: 64-bit numbers, with the
+// top bit always zero, written as 13 characters of sortable base32.
+const (
+	tidAlphabet = "234567abcdefghijklmnopqrstuvwxyz"
+	tidLength   = 13
+)
+
+func encodeTID(tid int64) string {
+	b := make([]byte, tidLength)
+	for i := tidLength - 1; i >= 0; i-- {
+		b[i] = tidAlphabet[tid&31]
+		tid >>= 5
+	}
+	return string(b)
+}
+
+func decodeTID(s string) (int64, error) {
+	// The first character only holds the three bits below the top one
+	if len(s) != tidLength || strings.IndexByte(tidAlphabet[:8], s[0]) < 0 {
+		return 0, fmt.Errorf("invalid record key %q", s)
+	}
+	var tid int64
+	for i := 0; i < len(s); i++ {
+		v := strings.IndexByte(tidAlphabet, s[i])
+		if v < 0 {
+			return 0, fmt.Errorf("invalid record key %q", s)
+		}
+		tid = tid<<5 | int64(v)
+	}
+	return tid, nil
+}
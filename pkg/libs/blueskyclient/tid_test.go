@@ -0,0 +1,34 @@
+package blueskyclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TID(t *testing.T) {
+	tests := map[string]struct {
+		tid int64
+		s   string
+	}{
+		"zero":    {tid: 0, s: "2222222222222"},
+		"real":    {tid: 1728652679052295174, s: "3jzfcijpj2z2a"},
+		"largest": {tid: 1<<63 - 1, s: "bzzzzzzzzzzzz"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.s, encodeTID(test.tid))
+			tid, err := decodeTID(test.s)
+			assert.NoError(t, err)
+			assert.Equal(t, test.tid, tid)
+		})
+	}
+}
+
+func Test_decodeTIDInvalid(t *testing.T) {
+	for _, s := range []string{"", "self", "3jzfcijpj2z2", "czzzzzzzzzzzz", "3jzfcijpj2z21"} {
+		_, err := decodeTID(s)
+		assert.Error(t, err, s)
+	}
+}
@@ -0,0 +1,48 @@
+// Package httpclient builds the HTTP clients the platform clients talk
+// through, with timeouts on every step of a request so a hung server
+// cannot hold up reconciling.
+package httpclient
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// DefaultTimeout bounds a whole request, including reading the response.
+const DefaultTimeout = 30 * time.Second
+
+const (
+	dialTimeout           = 10 * time.Second
+	keepAlive             = 30 * time.Second
+	tlsHandshakeTimeout   = 10 * time.Second
+	responseHeaderTimeout = 20 * time.Second
+	idleConnTimeout       = 90 * time.Second
+)
+
+// NewTransport returns a transport that gives up on connecting, the TLS
+// handshake and waiting for response headers on its own, whatever the
+// timeout of the client or request.
+func NewTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   dialTimeout,
+			KeepAlive: keepAlive,
+		}).DialContext,
+		TLSHandshakeTimeout:   tlsHandshakeTimeout,
+		ResponseHeaderTimeout: responseHeaderTimeout,
+		IdleConnTimeout:       idleConnTimeout,
+		MaxIdleConns:          100,
+		ForceAttemptHTTP2:     true,
+	}
+}
+
+// New returns a client on a new transport whose requests fail after
+// timeout. Zero means no limit beyond the transport's.
+func New(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: NewTransport(),
+		Timeout:   timeout,
+	}
+}
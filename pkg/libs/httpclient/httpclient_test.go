@@ -0,0 +1,66 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	tests := map[string]struct {
+		timeout time.Duration
+		ctx     func() (context.Context, context.CancelFunc)
+		delay   time.Duration
+		err     string
+	}{
+		"in time": {
+			timeout: time.Second,
+			ctx:     func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+		},
+		"client timeout": {
+			timeout: 10 * time.Millisecond,
+			ctx:     func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			delay:   time.Second,
+			err:     "Client.Timeout exceeded",
+		},
+		"context deadline": {
+			timeout: time.Second,
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 10*time.Millisecond)
+			},
+			delay: time.Second,
+			err:   "context deadline exceeded",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			done := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-time.After(test.delay):
+				case <-done:
+				}
+			}))
+			defer server.Close()
+			defer close(done)
+
+			ctx, cancel := test.ctx()
+			defer cancel()
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+			assert.NoError(t, err)
+			resp, err := New(test.timeout).Do(req)
+			if test.err != "" {
+				assert.ErrorContains(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	}
+}
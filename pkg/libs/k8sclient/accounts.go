@@ -0,0 +1,58 @@
+package k8sclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "github.com/jonatanblue/tweet-operator/pkg/apis/example.com/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type accountClient interface {
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Account, error)
+}
+
+// Account is an Account resource with the access token it refers to
+type Account struct {
+	Name        string
+	Platform    v1.Platform
+	InstanceURL string
+	Username    string
+	AccessToken string
+}
+
+// GetAccount reads the Account and the access token from its Secret. The
+// operator's role decides which Secrets it can read, so being refused one
+// is reported as such.
+func GetAccount(ctx context.Context, accountClient accountClient, secretClient secretClient, name string) (*Account, error) {
+	account, err := accountClient.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	ref := account.Spec.AccessTokenSecretRef
+	secret, err := secretClient.Get(ctx, ref.Name, metav1.GetOptions{})
+	if apierrors.IsForbidden(err) {
+		return nil, fmt.Errorf("account %s refers to secret %q, which the operator's role does not let it read: %w", name, ref.Name, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	token := strings.TrimSpace(string(secret.Data[ref.Key]))
+	if token == "" {
+		return nil, fmt.Errorf("secret %s has no %s for account %s", ref.Name, ref.Key, name)
+	}
+	return &Account{
+		Name:        name,
+		Platform:    account.Spec.Platform,
+		InstanceURL: account.Spec.InstanceURL,
+		Username:    account.Spec.Username,
+		AccessToken: token,
+	}, nil
+}
+
+// String leaves the access token out, so an Account can be logged.
+func (a *Account) String() string {
+	return fmt.Sprintf("%s (%s %s@%s)", a.Name, a.Platform, a.Username, a.InstanceURL)
+}
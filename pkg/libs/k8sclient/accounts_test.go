@@ -0,0 +1,111 @@
+package k8sclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	v1 "github.com/jonatanblue/tweet-operator/pkg/apis/example.com/v1"
+	tweetfake "github.com/jonatanblue/tweet-operator/pkg/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func Test_GetAccount(t *testing.T) {
+	account := &v1.Account{
+		ObjectMeta: metav1.ObjectMeta{Name: "fediverse", Namespace: "default"},
+		Spec: v1.AccountSpec{
+			Platform:             v1.PlatformMastodon,
+			InstanceURL:          "https://mastodon.example",
+			Username:             "bob",
+			AccessTokenSecretRef: v1.SecretKeyRef{Name: "mastodon-credentials", Key: "ACCESS_TOKEN"},
+		},
+	}
+
+	tests := map[string]struct {
+		secretName string
+		secrets    []*corev1.Secret
+		forbidden  bool
+		expected   *Account
+		err        string
+	}{
+		"token from secret": {
+			secrets: []*corev1.Secret{{
+				ObjectMeta: metav1.ObjectMeta{Name: "mastodon-credentials", Namespace: "default"},
+				Data:       map[string][]byte{"ACCESS_TOKEN": []byte("token\n")},
+			}},
+			expected: &Account{
+				Name:        "fediverse",
+				Platform:    v1.PlatformMastodon,
+				InstanceURL: "https://mastodon.example",
+				Username:    "bob",
+				AccessToken: "token",
+			},
+		},
+		"missing key": {
+			secrets: []*corev1.Secret{{
+				ObjectMeta: metav1.ObjectMeta{Name: "mastodon-credentials", Namespace: "default"},
+				Data:       map[string][]byte{"OTHER": []byte("token")},
+			}},
+			err: "secret mastodon-credentials has no ACCESS_TOKEN for account fediverse",
+		},
+		"missing secret": {
+			err: `secrets "mastodon-credentials" not found`,
+		},
+		"secret added to the role": {
+			secretName: "other-credentials",
+			secrets: []*corev1.Secret{{
+				ObjectMeta: metav1.ObjectMeta{Name: "other-credentials", Namespace: "default"},
+				Data:       map[string][]byte{"ACCESS_TOKEN": []byte("token")},
+			}},
+			expected: &Account{
+				Name:        "fediverse",
+				Platform:    v1.PlatformMastodon,
+				InstanceURL: "https://mastodon.example",
+				Username:    "bob",
+				AccessToken: "token",
+			},
+		},
+		"secret the role does not cover": {
+			secretName: "other-credentials",
+			forbidden:  true,
+			err:        `account fediverse refers to secret "other-credentials", which the operator's role does not let it read: secrets "other-credentials" is forbidden: not in resourceNames`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			objects := []runtime.Object{}
+			for _, secret := range test.secrets {
+				objects = append(objects, secret)
+			}
+			clientset := fake.NewSimpleClientset(objects...)
+			if test.forbidden {
+				clientset.PrependReactor("get", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					name := action.(k8stesting.GetAction).GetName()
+					return true, nil, apierrors.NewForbidden(corev1.Resource("secrets"), name, errors.New("not in resourceNames"))
+				})
+			}
+			secretClient := clientset.CoreV1().Secrets("default")
+			account := account.DeepCopy()
+			if test.secretName != "" {
+				account.Spec.AccessTokenSecretRef.Name = test.secretName
+			}
+			accountClient := tweetfake.NewSimpleClientset(account).ExampleV1().Accounts("default")
+
+			got, err := GetAccount(context.TODO(), accountClient, secretClient, "fediverse")
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, got)
+			assert.NotContains(t, got.String(), "token")
+		})
+	}
+}
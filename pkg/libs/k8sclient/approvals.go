@@ -0,0 +1,49 @@
+package k8sclient
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RequiredApprovalsAnnotation on a namespace sets how many approvals its
+// Tweets need before they are posted to any target.
+const RequiredApprovalsAnnotation = "example.com/required-approvals"
+
+type namespaceClient interface {
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*corev1.Namespace, error)
+}
+
+// GetRequiredApprovals returns the approvals the namespace asks for, zero
+// when it does not.
+func GetRequiredApprovals(ctx context.Context, namespaceClient namespaceClient, namespace string) (int, error) {
+	ns, err := namespaceClient.Get(ctx, namespace, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	value, ok := ns.Annotations[RequiredApprovalsAnnotation]
+	if !ok {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s annotation %q on namespace %s", RequiredApprovalsAnnotation, value, namespace)
+	}
+	return n, nil
+}
+
+// stricter returns the larger of the approvals a target and the namespace
+// ask for.
+func stricter(target, namespace int) int {
+	if target > namespace {
+		return target
+	}
+	return namespace
+}
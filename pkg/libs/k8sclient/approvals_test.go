@@ -0,0 +1,86 @@
+package k8sclient
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/jonatanblue/tweet-operator/pkg/apis/example.com/v1"
+	tweetfake "github.com/jonatanblue/tweet-operator/pkg/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_GetRequiredApprovals(t *testing.T) {
+	tests := map[string]struct {
+		annotations map[string]string
+		want        int
+		err         string
+	}{
+		"not annotated": {
+			want: 0,
+		},
+		"annotated": {
+			annotations: map[string]string{RequiredApprovalsAnnotation: "2"},
+			want:        2,
+		},
+		"invalid annotation": {
+			annotations: map[string]string{RequiredApprovalsAnnotation: "two"},
+			err:         `invalid example.com/required-approvals annotation "two" on namespace default`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(&corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: "default", Annotations: test.annotations},
+			})
+			n, err := GetRequiredApprovals(context.TODO(), clientset.CoreV1().Namespaces(), "default")
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, n)
+		})
+	}
+}
+
+func Test_ListTweetsNamespaceApprovals(t *testing.T) {
+	namespaces := fake.NewSimpleClientset(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Annotations: map[string]string{RequiredApprovalsAnnotation: "1"}},
+	}).CoreV1().Namespaces()
+	tweets := tweetfake.NewSimpleClientset(&v1.Tweet{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello-world", Namespace: "default"},
+		Spec:       v1.TweetSpec{Text: "Hello World"},
+	}).ExampleV1().Tweets("default")
+	client := NewK8sClient(tweets).WithRequiredApprovals(2).WithNamespaceApprovals(namespaces, "default")
+
+	requiredApprovals := func() int {
+		list, err := client.ListTweets(context.TODO())
+		assert.NoError(t, err)
+		assert.Len(t, *list, 1)
+		return (*list)[0].Spec.RequiredApprovals
+	}
+	// The target asks for more
+	assert.Equal(t, 2, requiredApprovals())
+
+	// A changed annotation applies from the next list on
+	ns, err := namespaces.Get(context.TODO(), "default", metav1.GetOptions{})
+	assert.NoError(t, err)
+	ns.Annotations[RequiredApprovalsAnnotation] = "3"
+	_, err = namespaces.Update(context.TODO(), ns, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, requiredApprovals())
+	tweet, err := client.GetTweet(context.TODO(), "hello-world")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, tweet.Spec.RequiredApprovals)
+
+	// An invalid annotation holds everything back rather than nothing
+	ns.Annotations[RequiredApprovalsAnnotation] = "three"
+	_, err = namespaces.Update(context.TODO(), ns, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	_, err = client.ListTweets(context.TODO())
+	assert.EqualError(t, err, `invalid example.com/required-approvals annotation "three" on namespace default`)
+}
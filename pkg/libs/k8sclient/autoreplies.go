@@ -0,0 +1,124 @@
+package k8sclient
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	v1 "github.com/jonatanblue/tweet-operator/pkg/apis/example.com/v1"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Defaults of the AutoReply spec
+const (
+	DefaultAutoReplyCooldown   = time.Hour
+	DefaultAutoReplyDailyLimit = 50
+)
+
+type autoReplyClient interface {
+	Update(ctx context.Context, autoReply *v1.AutoReply, opts metav1.UpdateOptions) (*v1.AutoReply, error)
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.AutoReply, error)
+	List(ctx context.Context, opts metav1.ListOptions) (*v1.AutoReplyList, error)
+}
+
+// AutoReplyClient reads and writes the AutoReplies of one target.
+type AutoReplyClient struct {
+	autoReplyClient autoReplyClient
+	target          string
+	defaultTarget   bool
+}
+
+func NewAutoReplyClient(autoReplyClient autoReplyClient, target string, defaultTarget bool) *AutoReplyClient {
+	return &AutoReplyClient{
+		autoReplyClient: autoReplyClient,
+		target:          target,
+		defaultTarget:   defaultTarget,
+	}
+}
+
+// ListAutoReplies returns the AutoReplies of the target, with defaults
+// filled in. Those without a target belong to the default target.
+func (c *AutoReplyClient) ListAutoReplies(ctx context.Context) ([]tweettypes.AutoReply, error) {
+	list, err := c.autoReplyClient.List(ctx, metav1.ListOptions{})
+	// Not found when the AutoReply CRD is not installed
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	autoReplies := []tweettypes.AutoReply{}
+	for _, autoReply := range list.Items {
+		target := autoReply.Spec.Target
+		if target == c.target || (target == "" && c.defaultTarget) {
+			autoReplies = append(autoReplies, toAutoReply(&autoReply, c.target))
+		}
+	}
+	return autoReplies, nil
+}
+
+func (c *AutoReplyClient) UpdateAutoReplyStatus(ctx context.Context, name string, status tweettypes.AutoReplyStatus) error {
+	current, err := c.autoReplyClient.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	new := current.DeepCopy()
+	new.Status = v1.AutoReplyStatus{
+		StartedAt: toOptionalTime(status.StartedAt),
+		SinceID:   status.SinceID,
+		Replies:   status.Replies,
+	}
+	for _, answered := range status.Answered {
+		new.Status.Answered = append(new.Status.Answered, v1.AnsweredMention{
+			ID:      answered.ID,
+			Author:  answered.Author,
+			ReplyID: answered.ReplyID,
+			Time:    metav1.NewTime(answered.Time),
+		})
+	}
+	if reflect.DeepEqual(current, new) {
+		return nil
+	}
+	_, err = c.autoReplyClient.Update(ctx, new, metav1.UpdateOptions{})
+	return err
+}
+
+func toAutoReply(autoReply *v1.AutoReply, target string) tweettypes.AutoReply {
+	result := tweettypes.AutoReply{
+		Spec: tweettypes.AutoReplySpec{
+			Namespace:  autoReply.Namespace,
+			Name:       autoReply.Name,
+			UID:        string(autoReply.UID),
+			Target:     target,
+			Regex:      autoReply.Spec.Regex,
+			Keywords:   autoReply.Spec.Keywords,
+			Template:   autoReply.Spec.Template,
+			Cooldown:   DefaultAutoReplyCooldown,
+			DailyLimit: autoReply.Spec.DailyLimit,
+		},
+		Status: tweettypes.AutoReplyStatus{
+			SinceID: autoReply.Status.SinceID,
+			Replies: autoReply.Status.Replies,
+		},
+	}
+	if autoReply.Spec.Cooldown != nil {
+		result.Spec.Cooldown = autoReply.Spec.Cooldown.Duration
+	}
+	if result.Spec.DailyLimit <= 0 {
+		result.Spec.DailyLimit = DefaultAutoReplyDailyLimit
+	}
+	if autoReply.Status.StartedAt != nil {
+		result.Status.StartedAt = autoReply.Status.StartedAt.Time
+	}
+	for _, answered := range autoReply.Status.Answered {
+		result.Status.Answered = append(result.Status.Answered, tweettypes.AnsweredMention{
+			ID:      answered.ID,
+			Author:  answered.Author,
+			ReplyID: answered.ReplyID,
+			Time:    answered.Time.Time,
+		})
+	}
+	return result
+}
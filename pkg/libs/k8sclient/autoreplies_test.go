@@ -0,0 +1,69 @@
+package k8sclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/jonatanblue/tweet-operator/pkg/apis/example.com/v1"
+	tweetfake "github.com/jonatanblue/tweet-operator/pkg/client/clientset/versioned/fake"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_AutoReplyClient(t *testing.T) {
+	autoReplyClient := tweetfake.NewSimpleClientset(
+		&v1.AutoReply{
+			ObjectMeta: metav1.ObjectMeta{Name: "defaults", Namespace: "default"},
+			Spec:       v1.AutoReplySpec{Keywords: []string{"open"}, Template: "Yes"},
+		},
+		&v1.AutoReply{
+			ObjectMeta: metav1.ObjectMeta{Name: "fediverse", Namespace: "default"},
+			Spec: v1.AutoReplySpec{
+				Target:     "fediverse",
+				Regex:      "price",
+				Template:   "Ask again",
+				Cooldown:   &metav1.Duration{Duration: 0},
+				DailyLimit: 5,
+			},
+		},
+	).ExampleV1().AutoReplies("default")
+	twitter := NewAutoReplyClient(autoReplyClient, DefaultTarget, true)
+	fediverse := NewAutoReplyClient(autoReplyClient, "fediverse", false)
+
+	autoReplies, err := twitter.ListAutoReplies(context.TODO())
+	assert.NoError(t, err)
+	assert.Len(t, autoReplies, 1)
+	assert.Equal(t, tweettypes.AutoReplySpec{
+		Namespace:  "default",
+		Name:       "defaults",
+		Target:     DefaultTarget,
+		Keywords:   []string{"open"},
+		Template:   "Yes",
+		Cooldown:   DefaultAutoReplyCooldown,
+		DailyLimit: DefaultAutoReplyDailyLimit,
+	}, autoReplies[0].Spec)
+
+	autoReplies, err = fediverse.ListAutoReplies(context.TODO())
+	assert.NoError(t, err)
+	assert.Len(t, autoReplies, 1)
+	assert.Equal(t, time.Duration(0), autoReplies[0].Spec.Cooldown)
+	assert.Equal(t, 5, autoReplies[0].Spec.DailyLimit)
+
+	at := time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+	status := tweettypes.AutoReplyStatus{
+		StartedAt: at,
+		SinceID:   "42",
+		Replies:   1,
+		Answered:  []tweettypes.AnsweredMention{{ID: "42", Author: "@alice", ReplyID: "43", Time: at.Add(time.Minute)}},
+	}
+	assert.NoError(t, fediverse.UpdateAutoReplyStatus(context.TODO(), "fediverse", status))
+	object, err := autoReplyClient.Get(context.TODO(), "fediverse", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "43", object.Status.Answered[0].ReplyID)
+
+	autoReplies, err = fediverse.ListAutoReplies(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, status, autoReplies[0].Status)
+}
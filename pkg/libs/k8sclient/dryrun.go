@@ -0,0 +1,127 @@
+package k8sclient
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/logging"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+)
+
+type tweetReader interface {
+	GetTweet(ctx context.Context, name string) (*tweettypes.Tweet, error)
+	ListTweets(ctx context.Context) (*tweettypes.Tweets, error)
+}
+
+// DryRunClient reads Tweets from the cluster but only logs status updates.
+// Updated statuses are kept in memory and laid over what is read, so later
+// passes see them as if they had been written. Tweets whose finalizer would
+// have been removed are left out, as if they were gone.
+type DryRunClient struct {
+	reader    tweetReader
+	log       logr.Logger
+	statuses  map[string]tweettypes.TweetStatus
+	finalized map[string]bool
+}
+
+func NewDryRunClient(reader tweetReader, log logr.Logger) *DryRunClient {
+	return &DryRunClient{
+		reader:    reader,
+		log:       log,
+		statuses:  map[string]tweettypes.TweetStatus{},
+		finalized: map[string]bool{},
+	}
+}
+
+func (c *DryRunClient) GetTweet(ctx context.Context, name string) (*tweettypes.Tweet, error) {
+	tweet, err := c.reader.GetTweet(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if status, ok := c.statuses[name]; ok {
+		tweet.Status = status
+	}
+	return tweet, nil
+}
+
+func (c *DryRunClient) UpdateStatus(ctx context.Context, name string, tweet *tweettypes.Tweet) (updated bool, err error) {
+	current, err := c.GetTweet(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	status := tweet.Status
+	// The approvers are only recorded with a new post, as by K8sClient
+	if status.ID == current.Status.ID {
+		status.ApprovedBy = current.Status.ApprovedBy
+	}
+	if reflect.DeepEqual(current.Status, status) {
+		return false, nil
+	}
+	logging.WithTweet(c.log, current).Info(
+		"Dry run: would update status",
+		"newID", tweet.Status.ID,
+		"likes", tweet.Status.Likes,
+		"retweets", tweet.Status.Retweets,
+		"replies", tweet.Status.Replies,
+		"quotes", tweet.Status.Quotes,
+		"impressions", tweet.Status.Impressions,
+	)
+	c.statuses[name] = status
+	return true, nil
+}
+
+func (c *DryRunClient) SetCondition(ctx context.Context, name string, condition tweettypes.Condition) error {
+	c.log.Info(
+		"Dry run: would set condition",
+		"name", name,
+		"type", condition.Type,
+		"status", condition.Status,
+		"reason", condition.Reason,
+	)
+	return nil
+}
+
+func (c *DryRunClient) ListTweets(ctx context.Context) (*tweettypes.Tweets, error) {
+	tweets, err := c.reader.ListTweets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := tweettypes.Tweets{}
+	for _, t := range *tweets {
+		if c.finalized[t.Spec.Name] {
+			continue
+		}
+		if status, ok := c.statuses[t.Spec.Name]; ok {
+			t.Status = status
+		}
+		result = append(result, t)
+	}
+	return &result, nil
+}
+
+func (c *DryRunClient) RemoveFinalizer(ctx context.Context, name string) error {
+	c.log.Info("Dry run: would remove finalizer", "name", name)
+	c.finalized[name] = true
+	return nil
+}
+
+// LogEventRecorder logs events instead of creating them, for dry runs.
+type LogEventRecorder struct {
+	log logr.Logger
+}
+
+func NewLogEventRecorder(log logr.Logger) *LogEventRecorder {
+	return &LogEventRecorder{
+		log: log,
+	}
+}
+
+func (r *LogEventRecorder) Event(tweet *tweettypes.Tweet, eventType, reason, message string) {
+	logging.WithTweet(r.log, tweet).Info(
+		"Dry run: would record event",
+		"type", eventType,
+		"reason", reason,
+		"message", message,
+	)
+}
@@ -0,0 +1,72 @@
+package k8sclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	v1 "github.com/jonatanblue/tweet-operator/pkg/apis/example.com/v1"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_DryRunClient(t *testing.T) {
+	tweet := &v1.Tweet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "hello-world",
+		},
+		Spec: v1.TweetSpec{
+			Text: "Hello World",
+		},
+	}
+	tweetClient := newTweetClientMock(
+		"Get",
+		[]interface{}{"hello-world"},
+		tweet,
+		nil,
+	).addMethod(
+		"List",
+		[]interface{}{metav1.ListOptions{}},
+		&v1.TweetList{Items: []v1.Tweet{*tweet}},
+		nil,
+	)
+	client := NewDryRunClient(NewK8sClient(tweetClient), logr.Discard())
+
+	posted := &tweettypes.Tweet{
+		Spec: tweettypes.TweetSpec{
+			Name:   "hello-world",
+			Target: DefaultTarget,
+			Text:   "Hello World",
+		},
+		Status: tweettypes.TweetStatus{
+			ID: -1,
+		},
+	}
+	updated, err := client.UpdateStatus(context.TODO(), "hello-world", posted)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+
+	updated, err = client.UpdateStatus(context.TODO(), "hello-world", posted)
+	assert.NoError(t, err)
+	assert.False(t, updated)
+
+	got, err := client.GetTweet(context.TODO(), "hello-world")
+	assert.NoError(t, err)
+	assert.Equal(t, posted, got)
+
+	err = client.SetCondition(context.TODO(), "hello-world", tweettypes.Condition{Type: "Ready", Status: true})
+	assert.NoError(t, err)
+
+	list, err := client.ListTweets(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, &tweettypes.Tweets{*posted}, list)
+
+	err = client.RemoveFinalizer(context.TODO(), "hello-world")
+	assert.NoError(t, err)
+	list, err = client.ListTweets(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, &tweettypes.Tweets{}, list)
+
+	tweetClient.AssertNotCalled(t, "Update")
+}
@@ -0,0 +1,70 @@
+package k8sclient
+
+import (
+	v1 "github.com/jonatanblue/tweet-operator/pkg/apis/example.com/v1"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+// EventRecorder attaches Kubernetes Events to the Tweet object a
+// tweettypes.Tweet was read from.
+type EventRecorder struct {
+	recorder record.EventRecorder
+}
+
+func NewEventRecorder(recorder record.EventRecorder) *EventRecorder {
+	return &EventRecorder{
+		recorder: recorder,
+	}
+}
+
+// Event records an event on the Tweet. Its message names the target, since
+// a cross-posted Tweet gets events from each.
+func (r *EventRecorder) Event(tweet *tweettypes.Tweet, eventType, reason, message string) {
+	if tweet.Spec.Target != "" {
+		message = tweet.Spec.Target + ": " + message
+	}
+	r.recorder.Event(tweetReference(tweet), eventType, reason, message)
+}
+
+// MentionWatchEvent records an event on the MentionWatch.
+func (r *EventRecorder) MentionWatchEvent(watch *tweettypes.MentionWatch, eventType, reason, message string) {
+	r.recorder.Event(mentionWatchReference(watch), eventType, reason, message)
+}
+
+// AutoReplyEvent records an event on the AutoReply.
+func (r *EventRecorder) AutoReplyEvent(autoReply *tweettypes.AutoReply, eventType, reason, message string) {
+	r.recorder.Event(autoReplyReference(autoReply), eventType, reason, message)
+}
+
+func tweetReference(tweet *tweettypes.Tweet) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		APIVersion: v1.SchemeGroupVersion.String(),
+		Kind:       "Tweet",
+		Namespace:  tweet.Spec.Namespace,
+		Name:       tweet.Spec.Name,
+		UID:        types.UID(tweet.Spec.UID),
+	}
+}
+
+func mentionWatchReference(watch *tweettypes.MentionWatch) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		APIVersion: v1.SchemeGroupVersion.String(),
+		Kind:       "MentionWatch",
+		Namespace:  watch.Spec.Namespace,
+		Name:       watch.Spec.Name,
+		UID:        types.UID(watch.Spec.UID),
+	}
+}
+
+func autoReplyReference(autoReply *tweettypes.AutoReply) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		APIVersion: v1.SchemeGroupVersion.String(),
+		Kind:       "AutoReply",
+		Namespace:  autoReply.Spec.Namespace,
+		Name:       autoReply.Spec.Name,
+		UID:        types.UID(autoReply.Spec.UID),
+	}
+}
@@ -0,0 +1,57 @@
+package k8sclient
+
+import (
+	"testing"
+
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func Test_EventRecorder(t *testing.T) {
+	fake := record.NewFakeRecorder(2)
+	fake.IncludeObject = true
+	recorder := NewEventRecorder(fake)
+
+	recorder.Event(
+		&tweettypes.Tweet{
+			Spec: tweettypes.TweetSpec{
+				Namespace: "default",
+				Name:      "hello-world",
+				UID:       "1234-abcd",
+			},
+		},
+		corev1.EventTypeNormal,
+		"Posted",
+		"Posted tweet 12345",
+	)
+
+	event := <-fake.Events
+	assert.Equal(
+		t,
+		"Normal Posted Posted tweet 12345 involvedObject{kind=Tweet,apiVersion=example.com/v1}",
+		event,
+	)
+
+	// Cross-posted Tweets name the target
+	recorder.Event(
+		&tweettypes.Tweet{
+			Spec: tweettypes.TweetSpec{
+				Namespace: "default",
+				Name:      "hello-world",
+				Target:    "fediverse",
+			},
+		},
+		corev1.EventTypeWarning,
+		"PostFailed",
+		"unauthorized",
+	)
+
+	event = <-fake.Events
+	assert.Equal(
+		t,
+		"Warning PostFailed fediverse: unauthorized involvedObject{kind=Tweet,apiVersion=example.com/v1}",
+		event,
+	)
+}
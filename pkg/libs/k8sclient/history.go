@@ -0,0 +1,92 @@
+package k8sclient
+
+import (
+	"time"
+
+	v1 "github.com/jonatanblue/tweet-operator/pkg/apis/example.com/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// historyLength bounds the snapshots kept per target, to keep the
+	// status of a Tweet small
+	historyLength = 72
+	// historyInterval is the least time between snapshots. Changes in
+	// between update the latest snapshot instead.
+	historyInterval = 10 * time.Minute
+	rateWindow      = time.Hour
+)
+
+// recordMetrics adds the target's metrics to its history and derives the
+// hourly rates from it.
+func recordMetrics(status *v1.TargetStatus, now time.Time) {
+	if status.ID == 0 {
+		resetHistory(status)
+		return
+	}
+
+	snapshot := v1.MetricsSnapshot{
+		Time:     metav1.NewTime(now.Truncate(time.Second)),
+		Likes:    status.Likes,
+		Retweets: status.Retweets,
+		Replies:  status.Replies,
+	}
+	last := len(status.History) - 1
+	switch {
+	case last < 0:
+		status.History = []v1.MetricsSnapshot{snapshot}
+	case sameMetrics(status.History[last], snapshot):
+	// The first snapshot is the baseline, such as the zeros right after
+	// posting, so it is never overwritten
+	case last > 0 && now.Sub(status.History[last].Time.Time) < historyInterval:
+		snapshot.Time = status.History[last].Time
+		status.History[last] = snapshot
+	default:
+		status.History = append(status.History, snapshot)
+	}
+	if len(status.History) > historyLength {
+		status.History = status.History[len(status.History)-historyLength:]
+	}
+
+	hourAgo := valueAt(status.History, now.Add(-rateWindow))
+	status.LikesLastHour = status.Likes - hourAgo.Likes
+	status.RetweetsLastHour = status.Retweets - hourAgo.Retweets
+	for i, s := range status.History {
+		before := valueAt(status.History[:i+1], s.Time.Add(-rateWindow))
+		status.PeakLikesPerHour = max64(status.PeakLikesPerHour, s.Likes-before.Likes)
+		status.PeakRetweetsPerHour = max64(status.PeakRetweetsPerHour, s.Retweets-before.Retweets)
+	}
+}
+
+// resetHistory drops the history, when the post it was about is gone.
+func resetHistory(status *v1.TargetStatus) {
+	status.History = nil
+	status.LikesLastHour = 0
+	status.RetweetsLastHour = 0
+	status.PeakLikesPerHour = 0
+	status.PeakRetweetsPerHour = 0
+}
+
+// valueAt returns the metrics as they were at the given time: the latest
+// snapshot taken by then, or the oldest one if all are newer.
+func valueAt(history []v1.MetricsSnapshot, t time.Time) v1.MetricsSnapshot {
+	value := history[0]
+	for _, s := range history {
+		if s.Time.After(t) {
+			break
+		}
+		value = s
+	}
+	return value
+}
+
+func sameMetrics(a, b v1.MetricsSnapshot) bool {
+	return a.Likes == b.Likes && a.Retweets == b.Retweets && a.Replies == b.Replies
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,112 @@
+package k8sclient
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/jonatanblue/tweet-operator/pkg/apis/example.com/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_recordMetrics(t *testing.T) {
+	posted := time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return posted.Add(time.Duration(minutes) * time.Minute)
+	}
+	snapshot := func(minutes int, likes, retweets int64) v1.MetricsSnapshot {
+		return v1.MetricsSnapshot{Time: metav1.NewTime(at(minutes)), Likes: likes, Retweets: retweets}
+	}
+	// Metrics seen at each minute since posting
+	type sync struct {
+		minutes  int
+		likes    int64
+		retweets int64
+	}
+
+	tests := map[string]struct {
+		syncs    []sync
+		expected v1.TargetStatus
+	}{
+		"just posted": {
+			syncs: []sync{{0, 0, 0}},
+			expected: v1.TargetStatus{
+				History: []v1.MetricsSnapshot{snapshot(0, 0, 0)},
+			},
+		},
+		"unchanged metrics": {
+			syncs: []sync{{0, 0, 0}, {20, 0, 0}, {40, 0, 0}},
+			expected: v1.TargetStatus{
+				History: []v1.MetricsSnapshot{snapshot(0, 0, 0)},
+			},
+		},
+		"changes within the interval update the latest snapshot": {
+			syncs: []sync{{0, 0, 0}, {1, 1, 0}, {20, 2, 1}, {25, 3, 1}},
+			expected: v1.TargetStatus{
+				Likes:    3,
+				Retweets: 1,
+				History: []v1.MetricsSnapshot{
+					snapshot(0, 0, 0),
+					snapshot(1, 1, 0),
+					snapshot(20, 3, 1),
+				},
+				LikesLastHour:       3,
+				RetweetsLastHour:    1,
+				PeakLikesPerHour:    3,
+				PeakRetweetsPerHour: 1,
+			},
+		},
+		"rates over the last hour and peak since posting": {
+			syncs: []sync{{0, 0, 0}, {30, 10, 8}, {60, 12, 9}, {120, 20, 10}},
+			expected: v1.TargetStatus{
+				Likes:    20,
+				Retweets: 10,
+				History: []v1.MetricsSnapshot{
+					snapshot(0, 0, 0),
+					snapshot(30, 10, 8),
+					snapshot(60, 12, 9),
+					snapshot(120, 20, 10),
+				},
+				LikesLastHour:       8,
+				RetweetsLastHour:    1,
+				PeakLikesPerHour:    12,
+				PeakRetweetsPerHour: 9,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			status := v1.TargetStatus{ID: 1}
+			for _, s := range test.syncs {
+				status.Likes = s.likes
+				status.Retweets = s.retweets
+				recordMetrics(&status, at(s.minutes))
+			}
+			test.expected.ID = 1
+			assert.Equal(t, test.expected, status)
+		})
+	}
+}
+
+func Test_recordMetricsBounded(t *testing.T) {
+	start := time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+	status := v1.TargetStatus{ID: 1}
+	// Hourly retweets slow down from 2*historyLength-1 to 1
+	for i := 0; i < historyLength*2; i++ {
+		if i > 0 {
+			status.Retweets += int64(historyLength*2 - i)
+		}
+		recordMetrics(&status, start.Add(time.Duration(i)*time.Hour))
+	}
+	assert.Len(t, status.History, historyLength)
+	assert.Equal(t, int64(1), status.RetweetsLastHour)
+	// Reached long before the oldest snapshot kept
+	assert.Equal(t, int64(historyLength*2-1), status.PeakRetweetsPerHour)
+
+	// Gone with the post
+	status.ID = 0
+	recordMetrics(&status, start)
+	assert.Empty(t, status.History)
+	assert.Zero(t, status.PeakRetweetsPerHour)
+}
@@ -3,13 +3,23 @@ package k8sclient
 import (
 	"context"
 	"reflect"
+	"time"
 
 	v1 "github.com/jonatanblue/tweet-operator/pkg/apis/example.com/v1"
 
 	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// Finalizer keeps a Tweet resource around after it is deleted, until the
+// operator has deleted its tweet.
+const Finalizer = "example.com/tweet-operator"
+
+// AuthorAnnotation records the user who created a Tweet. It is set by the
+// admission webhook, so the author cannot approve their own Tweet.
+const AuthorAnnotation = "example.com/author"
+
 type tweetClient interface {
 	Create(ctx context.Context, tweet *v1.Tweet, opts metav1.CreateOptions) (*v1.Tweet, error)
 	Update(ctx context.Context, tweet *v1.Tweet, opts metav1.UpdateOptions) (*v1.Tweet, error)
@@ -17,80 +27,233 @@ type tweetClient interface {
 	List(ctx context.Context, opts metav1.ListOptions) (*v1.TweetList, error)
 }
 
+// K8sClient reads and writes Tweets as seen by one target. Tweets that do
+// not target it are left out, and the status of a Tweet is its entry for
+// the target.
 type K8sClient struct {
-	tweetClient tweetClient
+	tweetClient       tweetClient
+	target            string
+	defaultTarget     bool
+	requiredApprovals int
+	// namespaceClient, when set, reads the approvals the namespace asks for
+	// again on every ListTweets, so a changed annotation applies from the
+	// next pass
+	namespaceClient    namespaceClient
+	namespace          string
+	namespaceApprovals int
+	now                func() time.Time
 }
 
+// NewK8sClient returns a client for DefaultTarget, the default target.
 func NewK8sClient(tweetClient tweetClient) *K8sClient {
 	return &K8sClient{
-		tweetClient: tweetClient,
+		tweetClient:   tweetClient,
+		target:        DefaultTarget,
+		defaultTarget: true,
+		now:           time.Now,
+	}
+}
+
+// ForTarget returns a client for another target. Tweets without targets
+// only go to the default target.
+func (c *K8sClient) ForTarget(target string, defaultTarget bool) *K8sClient {
+	return &K8sClient{
+		tweetClient:        c.tweetClient,
+		target:             target,
+		defaultTarget:      defaultTarget,
+		requiredApprovals:  c.requiredApprovals,
+		namespaceClient:    c.namespaceClient,
+		namespace:          c.namespace,
+		namespaceApprovals: c.namespaceApprovals,
+		now:                c.now,
 	}
 }
 
-func (c *K8sClient) GetTweet(name string) (*tweettypes.Tweet, error) {
-	tweet, err := c.tweetClient.Get(context.TODO(), name, metav1.GetOptions{})
+// WithRequiredApprovals returns a client whose Tweets are only posted once
+// n users other than the author approved them.
+func (c *K8sClient) WithRequiredApprovals(n int) *K8sClient {
+	result := *c
+	result.requiredApprovals = n
+	return &result
+}
+
+// WithNamespaceApprovals returns a client that also holds Tweets back for
+// the approvals the annotation on their namespace asks for, whichever is
+// more.
+func (c *K8sClient) WithNamespaceApprovals(namespaceClient namespaceClient, namespace string) *K8sClient {
+	result := *c
+	result.namespaceClient = namespaceClient
+	result.namespace = namespace
+	return &result
+}
+
+func (c *K8sClient) GetTweet(ctx context.Context, name string) (*tweettypes.Tweet, error) {
+	tweet, err := c.tweetClient.Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
-	return &tweettypes.Tweet{
-		Spec: tweettypes.TweetSpec{
-			Name: tweet.Name,
-			Text: tweet.Spec.Text,
-		},
-		Status: tweettypes.TweetStatus{
-			ID:       tweet.Status.ID,
-			Likes:    tweet.Status.Likes,
-			Retweets: tweet.Status.Retweets,
-			Replies:  tweet.Status.Replies,
-		},
-	}, nil
+	return c.toTweet(tweet), nil
+}
+
+func (c *K8sClient) UpdateStatus(ctx context.Context, name string, tweet *tweettypes.Tweet) (updated bool, err error) {
+	return c.update(ctx, name, func(new *v1.Tweet) {
+		status := c.targetStatus(new)
+		if status.ID != tweet.Status.ID {
+			// A new post, such as after an edit, starts a new history
+			resetHistory(&status)
+			status.ApprovedBy = tweet.Status.ApprovedBy
+		}
+		status.ID = tweet.Status.ID
+		status.RemoteID = tweet.Status.RemoteID
+		status.URL = tweet.Status.URL
+		status.Likes = tweet.Status.Likes
+		status.Retweets = tweet.Status.Retweets
+		status.Replies = tweet.Status.Replies
+		status.Quotes = tweet.Status.Quotes
+		status.Bookmarks = tweet.Status.Bookmarks
+		status.Impressions = tweet.Status.Impressions
+		status.URLLinkClicks = tweet.Status.URLLinkClicks
+		status.ProfileClicks = tweet.Status.ProfileClicks
+		recordMetrics(&status, c.now())
+		c.setTargetStatus(new, status)
+		// Once there is a tweet to clean up, deleting the resource has to
+		// wait for the operator
+		if hasPosts(new) && new.DeletionTimestamp == nil && !hasFinalizer(new) {
+			new.Finalizers = append(new.Finalizers, Finalizer)
+		}
+	})
+}
+
+// SetCondition records a condition in the target's status entry. The
+// transition time only changes along with the condition's status.
+func (c *K8sClient) SetCondition(ctx context.Context, name string, condition tweettypes.Condition) error {
+	_, err := c.update(ctx, name, func(new *v1.Tweet) {
+		status := c.targetStatus(new)
+		meta.SetStatusCondition(&status.Conditions, toCondition(condition, new.Generation))
+		c.setTargetStatus(new, status)
+	})
+	return err
+}
+
+func (c *K8sClient) ListTweets(ctx context.Context) (*tweettypes.Tweets, error) {
+	if c.namespaceClient != nil {
+		n, err := GetRequiredApprovals(ctx, c.namespaceClient, c.namespace)
+		if err != nil {
+			return nil, err
+		}
+		c.namespaceApprovals = n
+	}
+	list, err := c.tweetClient.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	tweets := tweettypes.Tweets{}
+	for i := range list.Items {
+		tweet := &list.Items[i]
+		// Tweets that no longer target this target stay in the list until
+		// their post there is deleted
+		if c.targeted(tweet) || c.findTargetStatus(tweet) != nil {
+			tweets = append(tweets, *c.toTweet(tweet))
+		}
+	}
+	return &tweets, nil
 }
 
-func (c *K8sClient) UpdateStatus(name string, tweet *tweettypes.Tweet) (updated bool, err error) {
-	t, err := c.tweetClient.Get(context.TODO(), name, metav1.GetOptions{})
+// RemoveFinalizer drops the target's status entry, and lets Kubernetes
+// finish deleting the Tweet resource once no target has a post left.
+func (c *K8sClient) RemoveFinalizer(ctx context.Context, name string) error {
+	_, err := c.update(ctx, name, func(new *v1.Tweet) {
+		c.setTargetStatus(new, v1.TargetStatus{Name: c.target})
+		if hasPosts(new) || !hasFinalizer(new) {
+			return
+		}
+		finalizers := []string{}
+		for _, f := range new.Finalizers {
+			if f != Finalizer {
+				finalizers = append(finalizers, f)
+			}
+		}
+		new.Finalizers = finalizers
+	})
+	return err
+}
+
+// update applies change to the Tweet and writes it if anything changed.
+func (c *K8sClient) update(ctx context.Context, name string, change func(new *v1.Tweet)) (updated bool, err error) {
+	t, err := c.tweetClient.Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return false, err
 	}
 	new := t.DeepCopy()
-	new.Status = v1.TweetStatus{
-		ID:       tweet.Status.ID,
-		Likes:    tweet.Status.Likes,
-		Retweets: tweet.Status.Retweets,
-		Replies:  tweet.Status.Replies,
-	}
+	c.migrateStatus(new)
+	change(new)
 	if reflect.DeepEqual(t, new) {
 		return false, nil
 	}
-	_, err = c.tweetClient.Update(
-		context.TODO(),
-		new,
-		metav1.UpdateOptions{},
-	)
+	_, err = c.tweetClient.Update(ctx, new, metav1.UpdateOptions{})
 	if err != nil {
 		return false, err
 	}
 	return true, nil
 }
 
-func (c *K8sClient) ListTweets() (*tweettypes.Tweets, error) {
-	list, err := c.tweetClient.List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return nil, err
+func hasFinalizer(tweet *v1.Tweet) bool {
+	for _, f := range tweet.Finalizers {
+		if f == Finalizer {
+			return true
+		}
 	}
-	tweets := tweettypes.Tweets{}
-	for _, t := range list.Items {
-		tweets = append(tweets, tweettypes.Tweet{
-			Spec: tweettypes.TweetSpec{
-				Name: t.Name,
-				Text: t.Spec.Text,
-			},
-			Status: tweettypes.TweetStatus{
-				ID:       t.Status.ID,
-				Likes:    t.Status.Likes,
-				Retweets: t.Status.Retweets,
-				Replies:  t.Status.Replies,
-			},
-		})
+	return false
+}
+
+// toTweet returns the Tweet as seen by the target. A Tweet that no longer
+// targets it has no text, so its post is deleted.
+func (c *K8sClient) toTweet(tweet *v1.Tweet) *tweettypes.Tweet {
+	result := &tweettypes.Tweet{
+		Spec: tweettypes.TweetSpec{
+			Namespace: tweet.Namespace,
+			Name:      tweet.Name,
+			UID:       string(tweet.UID),
+			Target:    c.target,
+			Deleting:  tweet.DeletionTimestamp != nil,
+		},
 	}
-	return &tweets, nil
+	if c.targeted(tweet) {
+		result.Spec.Text = tweet.Spec.Text
+		result.Spec.Approvals = approvals(tweet)
+		result.Spec.RequiredApprovals = stricter(c.requiredApprovals, c.namespaceApprovals)
+	}
+	if status := c.findTargetStatus(tweet); status != nil {
+		result.Status = tweettypes.TweetStatus{
+			ID:            status.ID,
+			RemoteID:      status.RemoteID,
+			URL:           status.URL,
+			Likes:         status.Likes,
+			Retweets:      status.Retweets,
+			Replies:       status.Replies,
+			Quotes:        status.Quotes,
+			Bookmarks:     status.Bookmarks,
+			Impressions:   status.Impressions,
+			URLLinkClicks: status.URLLinkClicks,
+			ProfileClicks: status.ProfileClicks,
+			ApprovedBy:    status.ApprovedBy,
+		}
+	}
+	return result
+}
+
+// approvals returns the distinct approvers of the Tweet other than its
+// author. The webhook rejects the rest, but it may not be installed.
+func approvals(tweet *v1.Tweet) []string {
+	author := tweet.Annotations[AuthorAnnotation]
+	seen := map[string]bool{}
+	var result []string
+	for _, user := range tweet.Spec.Approvals {
+		if user == "" || user == author || seen[user] {
+			continue
+		}
+		seen[user] = true
+		result = append(result, user)
+	}
+	return result
 }
@@ -3,6 +3,7 @@ package k8sclient
 import (
 	"errors"
 	"testing"
+	"time"
 
 	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
 	"github.com/stretchr/testify/assert"
@@ -46,8 +47,9 @@ func Test_GetTweet(t *testing.T) {
 			name: "hello-world",
 			want: &tweettypes.Tweet{
 				Spec: tweettypes.TweetSpec{
-					Name: "hello-world",
-					Text: "Hello World",
+					Name:   "hello-world",
+					Target: DefaultTarget,
+					Text:   "Hello World",
 				},
 				Status: tweettypes.TweetStatus{
 					ID:       12345,
@@ -75,7 +77,7 @@ func Test_GetTweet(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			tweet, err := test.client.GetTweet(test.name)
+			tweet, err := test.client.GetTweet(context.TODO(), test.name)
 			if err != nil {
 				assert.EqualError(t, err, test.err.Error())
 			}
@@ -85,6 +87,7 @@ func Test_GetTweet(t *testing.T) {
 }
 
 func Test_UpdateStatus(t *testing.T) {
+	now := time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
 	tests := map[string]struct {
 		tweetClient *tweetClientMock
 		name        string
@@ -92,7 +95,7 @@ func Test_UpdateStatus(t *testing.T) {
 		updated     bool
 		err         error
 	}{
-		"tweet status updated no error": {
+		"legacy tweet status moved to the default target": {
 			tweetClient: newTweetClientMock(
 				"Get",
 				[]interface{}{"hello-world"},
@@ -114,14 +117,21 @@ func Test_UpdateStatus(t *testing.T) {
 				[]interface{}{
 					&v1.Tweet{
 						ObjectMeta: metav1.ObjectMeta{
-							Name: "hello-world",
+							Name:       "hello-world",
+							Finalizers: []string{Finalizer},
 						},
 						Spec: v1.TweetSpec{
 							Text: "Hello World",
 						},
 						Status: v1.TweetStatus{
-							ID:    12345,
-							Likes: 1,
+							Targets: []v1.TargetStatus{
+								{
+									Name:    DefaultTarget,
+									ID:      12345,
+									Likes:   1,
+									History: []v1.MetricsSnapshot{{Time: metav1.NewTime(now), Likes: 1}},
+								},
+							},
 						},
 					},
 					metav1.UpdateOptions{},
@@ -149,14 +159,21 @@ func Test_UpdateStatus(t *testing.T) {
 				[]interface{}{"hello-world"},
 				&v1.Tweet{
 					ObjectMeta: metav1.ObjectMeta{
-						Name: "hello-world",
+						Name:       "hello-world",
+						Finalizers: []string{Finalizer},
 					},
 					Spec: v1.TweetSpec{
 						Text: "Hello World",
 					},
 					Status: v1.TweetStatus{
-						ID:    12345,
-						Likes: 1,
+						Targets: []v1.TargetStatus{
+							{
+								Name:    DefaultTarget,
+								ID:      12345,
+								Likes:   1,
+								History: []v1.MetricsSnapshot{{Time: metav1.NewTime(now), Likes: 1}},
+							},
+						},
 					},
 				},
 				nil,
@@ -180,7 +197,8 @@ func Test_UpdateStatus(t *testing.T) {
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
 			client := NewK8sClient(test.tweetClient)
-			updated, err := client.UpdateStatus(test.name, test.in)
+			client.now = func() time.Time { return now }
+			updated, err := client.UpdateStatus(context.TODO(), test.name, test.in)
 			if err != nil {
 				assert.EqualError(t, err, test.err.Error())
 			}
@@ -225,8 +243,9 @@ func Test_ListTweets(t *testing.T) {
 			want: &tweettypes.Tweets{
 				{
 					Spec: tweettypes.TweetSpec{
-						Name: "hello-world",
-						Text: "Hello World",
+						Name:   "hello-world",
+						Target: DefaultTarget,
+						Text:   "Hello World",
 					},
 					Status: tweettypes.TweetStatus{
 						ID:       12345,
@@ -270,15 +289,17 @@ func Test_ListTweets(t *testing.T) {
 			want: &tweettypes.Tweets{
 				{
 					Spec: tweettypes.TweetSpec{
-						Name: "hello-world",
-						Text: "Hello World",
+						Name:   "hello-world",
+						Target: DefaultTarget,
+						Text:   "Hello World",
 					},
 					Status: tweettypes.TweetStatus{},
 				},
 				{
 					Spec: tweettypes.TweetSpec{
-						Name: "hello-world-2",
-						Text: "Hello World 2",
+						Name:   "hello-world-2",
+						Target: DefaultTarget,
+						Text:   "Hello World 2",
 					},
 					Status: tweettypes.TweetStatus{},
 				},
@@ -301,7 +322,7 @@ func Test_ListTweets(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			tweets, err := test.client.ListTweets()
+			tweets, err := test.client.ListTweets(context.TODO())
 			if err != nil {
 				assert.EqualError(t, err, test.err.Error())
 			}
@@ -310,6 +331,110 @@ func Test_ListTweets(t *testing.T) {
 	}
 }
 
+func Test_RemoveFinalizer(t *testing.T) {
+	deletedAt := metav1.Now()
+	tests := map[string]struct {
+		tweetClient *tweetClientMock
+	}{
+		"finalizer removed": {
+			tweetClient: newTweetClientMock(
+				"Get",
+				[]interface{}{"hello-world"},
+				&v1.Tweet{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "hello-world",
+						DeletionTimestamp: &deletedAt,
+						Finalizers:        []string{"other", Finalizer},
+					},
+				},
+				nil,
+			).addMethod(
+				"Update",
+				[]interface{}{
+					&v1.Tweet{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:              "hello-world",
+							DeletionTimestamp: &deletedAt,
+							Finalizers:        []string{"other"},
+						},
+					},
+					metav1.UpdateOptions{},
+				},
+				&v1.Tweet{},
+				nil,
+			),
+		},
+		"no finalizer no update": {
+			tweetClient: newTweetClientMock(
+				"Get",
+				[]interface{}{"hello-world"},
+				&v1.Tweet{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "hello-world",
+						DeletionTimestamp: &deletedAt,
+						Finalizers:        []string{"other"},
+					},
+				},
+				nil,
+			),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := NewK8sClient(test.tweetClient).RemoveFinalizer(context.TODO(), "hello-world")
+			assert.NoError(t, err)
+			test.tweetClient.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_GetTweetDeleting(t *testing.T) {
+	deletedAt := metav1.Now()
+	client := NewK8sClient(newTweetClientMock(
+		"Get",
+		[]interface{}{"hello-world"},
+		&v1.Tweet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "hello-world",
+				DeletionTimestamp: &deletedAt,
+				Finalizers:        []string{Finalizer},
+			},
+		},
+		nil,
+	))
+	tweet, err := client.GetTweet(context.TODO(), "hello-world")
+	assert.NoError(t, err)
+	assert.True(t, tweet.Spec.Deleting)
+}
+
+func Test_GetTweetApprovals(t *testing.T) {
+	client := NewK8sClient(newTweetClientMock(
+		"Get",
+		[]interface{}{"hello-world"},
+		&v1.Tweet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "hello-world",
+				Annotations: map[string]string{AuthorAnnotation: "alice"},
+			},
+			Spec: v1.TweetSpec{
+				Text:      "Hello World",
+				Approvals: []string{"bob", "alice", "bob", "carol"},
+			},
+			Status: v1.TweetStatus{
+				Targets: []v1.TargetStatus{{Name: DefaultTarget, ID: 12345, ApprovedBy: []string{"bob"}}},
+			},
+		},
+		nil,
+	)).WithRequiredApprovals(2)
+	tweet, err := client.GetTweet(context.TODO(), "hello-world")
+	assert.NoError(t, err)
+	// The author and repeated approvers do not count
+	assert.Equal(t, []string{"bob", "carol"}, tweet.Spec.Approvals)
+	assert.Equal(t, 2, tweet.Spec.RequiredApprovals)
+	assert.Equal(t, []string{"bob"}, tweet.Status.ApprovedBy)
+}
+
 func newTweetClientMock(methodName string, arg []interface{}, ret interface{}, err error) *tweetClientMock {
 	client := new(tweetClientMock)
 	client.On(methodName, arg...).Return(ret, err)
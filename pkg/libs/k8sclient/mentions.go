@@ -0,0 +1,104 @@
+package k8sclient
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	v1 "github.com/jonatanblue/tweet-operator/pkg/apis/example.com/v1"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type mentionWatchClient interface {
+	Update(ctx context.Context, watch *v1.MentionWatch, opts metav1.UpdateOptions) (*v1.MentionWatch, error)
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.MentionWatch, error)
+	List(ctx context.Context, opts metav1.ListOptions) (*v1.MentionWatchList, error)
+}
+
+// MentionWatchClient reads and writes the MentionWatches of one target.
+type MentionWatchClient struct {
+	mentionWatchClient mentionWatchClient
+	target             string
+	defaultTarget      bool
+}
+
+func NewMentionWatchClient(mentionWatchClient mentionWatchClient, target string, defaultTarget bool) *MentionWatchClient {
+	return &MentionWatchClient{
+		mentionWatchClient: mentionWatchClient,
+		target:             target,
+		defaultTarget:      defaultTarget,
+	}
+}
+
+// ListMentionWatches returns the MentionWatches of the target. Those
+// without a target belong to the default target.
+func (c *MentionWatchClient) ListMentionWatches(ctx context.Context) ([]tweettypes.MentionWatch, error) {
+	list, err := c.mentionWatchClient.List(ctx, metav1.ListOptions{})
+	// Not found when the MentionWatch CRD is not installed
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	watches := []tweettypes.MentionWatch{}
+	for _, watch := range list.Items {
+		target := watch.Spec.Target
+		if target == c.target || (target == "" && c.defaultTarget) {
+			watches = append(watches, toMentionWatch(&watch, c.target))
+		}
+	}
+	return watches, nil
+}
+
+func (c *MentionWatchClient) UpdateMentionWatchStatus(ctx context.Context, name string, status tweettypes.MentionWatchStatus) error {
+	current, err := c.mentionWatchClient.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	new := current.DeepCopy()
+	new.Status = v1.MentionWatchStatus{
+		StartedAt:       toOptionalTime(status.StartedAt),
+		SinceID:         status.SinceID,
+		Mentions:        status.Mentions,
+		LastMentionTime: toOptionalTime(status.LastMentionTime),
+	}
+	if reflect.DeepEqual(current, new) {
+		return nil
+	}
+	_, err = c.mentionWatchClient.Update(ctx, new, metav1.UpdateOptions{})
+	return err
+}
+
+func toMentionWatch(watch *v1.MentionWatch, target string) tweettypes.MentionWatch {
+	result := tweettypes.MentionWatch{
+		Spec: tweettypes.MentionWatchSpec{
+			Namespace:  watch.Namespace,
+			Name:       watch.Name,
+			UID:        string(watch.UID),
+			Target:     target,
+			WebhookURL: watch.Spec.WebhookURL,
+		},
+		Status: tweettypes.MentionWatchStatus{
+			SinceID:  watch.Status.SinceID,
+			Mentions: watch.Status.Mentions,
+		},
+	}
+	if watch.Status.StartedAt != nil {
+		result.Status.StartedAt = watch.Status.StartedAt.Time
+	}
+	if watch.Status.LastMentionTime != nil {
+		result.Status.LastMentionTime = watch.Status.LastMentionTime.Time
+	}
+	return result
+}
+
+func toOptionalTime(t time.Time) *metav1.Time {
+	if t.IsZero() {
+		return nil
+	}
+	result := metav1.NewTime(t)
+	return &result
+}
@@ -0,0 +1,54 @@
+package k8sclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/jonatanblue/tweet-operator/pkg/apis/example.com/v1"
+	tweetfake "github.com/jonatanblue/tweet-operator/pkg/client/clientset/versioned/fake"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_MentionWatchClient(t *testing.T) {
+	// Created through the client, as the tracker would guess the plural
+	// of objects passed to NewSimpleClientset wrong
+	watchClient := tweetfake.NewSimpleClientset().ExampleV1().MentionWatches("default")
+	for _, watch := range []*v1.MentionWatch{
+		{ObjectMeta: metav1.ObjectMeta{Name: "untargeted", Namespace: "default"}},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "fediverse", Namespace: "default"},
+			Spec:       v1.MentionWatchSpec{Target: "fediverse", WebhookURL: "http://receiver/"},
+		},
+	} {
+		_, err := watchClient.Create(context.TODO(), watch, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+	twitter := NewMentionWatchClient(watchClient, DefaultTarget, true)
+	fediverse := NewMentionWatchClient(watchClient, "fediverse", false)
+
+	watches, err := twitter.ListMentionWatches(context.TODO())
+	assert.NoError(t, err)
+	assert.Len(t, watches, 1)
+	assert.Equal(t, "untargeted", watches[0].Spec.Name)
+	assert.Equal(t, DefaultTarget, watches[0].Spec.Target)
+
+	watches, err = fediverse.ListMentionWatches(context.TODO())
+	assert.NoError(t, err)
+	assert.Len(t, watches, 1)
+	assert.Equal(t, "http://receiver/", watches[0].Spec.WebhookURL)
+
+	started := time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+	status := tweettypes.MentionWatchStatus{StartedAt: started, SinceID: "42", Mentions: 1, LastMentionTime: started.Add(time.Minute)}
+	assert.NoError(t, fediverse.UpdateMentionWatchStatus(context.TODO(), "fediverse", status))
+	object, err := watchClient.Get(context.TODO(), "fediverse", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "42", object.Status.SinceID)
+	assert.Equal(t, started, object.Status.StartedAt.Time)
+
+	watches, err = fediverse.ListMentionWatches(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, status, watches[0].Status)
+}
@@ -0,0 +1,48 @@
+package k8sclient
+
+import (
+	"context"
+
+	v1 "github.com/jonatanblue/tweet-operator/pkg/apis/example.com/v1"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type tweetPolicyClient interface {
+	List(ctx context.Context, opts metav1.ListOptions) (*v1.TweetPolicyList, error)
+}
+
+// TweetPolicyClient reads the TweetPolicies, which are cluster-wide.
+type TweetPolicyClient struct {
+	tweetPolicyClient tweetPolicyClient
+}
+
+func NewTweetPolicyClient(tweetPolicyClient tweetPolicyClient) *TweetPolicyClient {
+	return &TweetPolicyClient{
+		tweetPolicyClient: tweetPolicyClient,
+	}
+}
+
+func (c *TweetPolicyClient) ListTweetPolicies(ctx context.Context) ([]tweettypes.TweetPolicy, error) {
+	list, err := c.tweetPolicyClient.List(ctx, metav1.ListOptions{})
+	// Not found when the TweetPolicy CRD is not installed
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	policies := []tweettypes.TweetPolicy{}
+	for _, p := range list.Items {
+		policies = append(policies, tweettypes.TweetPolicy{
+			Name:               p.Name,
+			Namespaces:         p.Spec.Namespaces,
+			DenyPatterns:       p.Spec.DenyPatterns,
+			RequiredPatterns:   p.Spec.RequiredPatterns,
+			AllowedLinkDomains: p.Spec.AllowedLinkDomains,
+			MaxMentions:        p.Spec.MaxMentions,
+		})
+	}
+	return policies, nil
+}
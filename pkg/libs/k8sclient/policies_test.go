@@ -0,0 +1,36 @@
+package k8sclient
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/jonatanblue/tweet-operator/pkg/apis/example.com/v1"
+	tweetfake "github.com/jonatanblue/tweet-operator/pkg/client/clientset/versioned/fake"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_ListTweetPolicies(t *testing.T) {
+	one := 1
+	clientset := tweetfake.NewSimpleClientset(&v1.TweetPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "disclosure"},
+		Spec: v1.TweetPolicySpec{
+			Namespaces:         []string{"marketing"},
+			DenyPatterns:       []string{`\.internal\b`},
+			RequiredPatterns:   []string{`#ad\b`},
+			AllowedLinkDomains: []string{"example.com"},
+			MaxMentions:        &one,
+		},
+	})
+	policies, err := NewTweetPolicyClient(clientset.ExampleV1().TweetPolicies()).ListTweetPolicies(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, []tweettypes.TweetPolicy{{
+		Name:               "disclosure",
+		Namespaces:         []string{"marketing"},
+		DenyPatterns:       []string{`\.internal\b`},
+		RequiredPatterns:   []string{`#ad\b`},
+		AllowedLinkDomains: []string{"example.com"},
+		MaxMentions:        &one,
+	}}, policies)
+}
@@ -0,0 +1,117 @@
+package k8sclient
+
+import (
+	"context"
+	"reflect"
+
+	v1 "github.com/jonatanblue/tweet-operator/pkg/apis/example.com/v1"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type repliesClient interface {
+	Create(ctx context.Context, replies *v1.TweetReplies, opts metav1.CreateOptions) (*v1.TweetReplies, error)
+	Update(ctx context.Context, replies *v1.TweetReplies, opts metav1.UpdateOptions) (*v1.TweetReplies, error)
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.TweetReplies, error)
+}
+
+// ReplyStore keeps the replies to a target's posts in the TweetReplies
+// object of each Tweet. The object is owned by the Tweet, so it goes away
+// with it.
+type ReplyStore struct {
+	repliesClient repliesClient
+	target        string
+}
+
+func NewReplyStore(repliesClient repliesClient, target string) *ReplyStore {
+	return &ReplyStore{
+		repliesClient: repliesClient,
+		target:        target,
+	}
+}
+
+// GetReplies returns the replies stored for the target, newest first.
+func (s *ReplyStore) GetReplies(ctx context.Context, tweet *tweettypes.Tweet) ([]tweettypes.Reply, error) {
+	replies, err := s.repliesClient.Get(ctx, tweet.Spec.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, target := range replies.Status.Targets {
+		if target.Name != s.target {
+			continue
+		}
+		result := []tweettypes.Reply{}
+		for _, r := range target.Replies {
+			result = append(result, tweettypes.Reply{
+				ID:     r.ID,
+				Author: r.Author,
+				Text:   r.Text,
+				Time:   r.Time.Time,
+			})
+		}
+		return result, nil
+	}
+	return nil, nil
+}
+
+// SetReplies replaces the replies stored for the target, creating the
+// TweetReplies object on first use.
+func (s *ReplyStore) SetReplies(ctx context.Context, tweet *tweettypes.Tweet, replies []tweettypes.Reply) error {
+	current, err := s.repliesClient.Get(ctx, tweet.Spec.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		current = &v1.TweetReplies{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      tweet.Spec.Name,
+				Namespace: tweet.Spec.Namespace,
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: v1.SchemeGroupVersion.String(),
+					Kind:       "Tweet",
+					Name:       tweet.Spec.Name,
+					UID:        types.UID(tweet.Spec.UID),
+				}},
+			},
+		}
+		current.Status.Targets = []v1.TargetReplies{toTargetReplies(s.target, replies)}
+		_, err = s.repliesClient.Create(ctx, current, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	new := current.DeepCopy()
+	target := toTargetReplies(s.target, replies)
+	found := false
+	for i := range new.Status.Targets {
+		if new.Status.Targets[i].Name == s.target {
+			new.Status.Targets[i] = target
+			found = true
+		}
+	}
+	if !found {
+		new.Status.Targets = append(new.Status.Targets, target)
+	}
+	if reflect.DeepEqual(current, new) {
+		return nil
+	}
+	_, err = s.repliesClient.Update(ctx, new, metav1.UpdateOptions{})
+	return err
+}
+
+func toTargetReplies(target string, replies []tweettypes.Reply) v1.TargetReplies {
+	result := v1.TargetReplies{Name: target}
+	for _, r := range replies {
+		result.Replies = append(result.Replies, v1.Reply{
+			ID:     r.ID,
+			Author: r.Author,
+			Text:   r.Text,
+			Time:   metav1.NewTime(r.Time),
+		})
+	}
+	return result
+}
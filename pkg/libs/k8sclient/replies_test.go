@@ -0,0 +1,49 @@
+package k8sclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/jonatanblue/tweet-operator/pkg/apis/example.com/v1"
+	tweetfake "github.com/jonatanblue/tweet-operator/pkg/client/clientset/versioned/fake"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_ReplyStore(t *testing.T) {
+	repliesClient := tweetfake.NewSimpleClientset().ExampleV1().TweetReplieses("default")
+	twitter := NewReplyStore(repliesClient, DefaultTarget)
+	fediverse := NewReplyStore(repliesClient, "fediverse")
+	tweet := &tweettypes.Tweet{Spec: tweettypes.TweetSpec{Namespace: "default", Name: "hello-world", UID: "1234"}}
+	at := time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+	reply := tweettypes.Reply{ID: "2", Author: "alice", Text: "@bob Hi", Time: at}
+
+	replies, err := twitter.GetReplies(context.TODO(), tweet)
+	assert.NoError(t, err)
+	assert.Empty(t, replies)
+
+	// Created on first use, owned by the Tweet
+	assert.NoError(t, twitter.SetReplies(context.TODO(), tweet, []tweettypes.Reply{reply}))
+	object, err := repliesClient.Get(context.TODO(), "hello-world", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Tweet", object.OwnerReferences[0].Kind)
+	assert.Equal(t, "1234", string(object.OwnerReferences[0].UID))
+
+	// Each target has its own entry
+	assert.NoError(t, fediverse.SetReplies(context.TODO(), tweet, []tweettypes.Reply{}))
+	object, err = repliesClient.Get(context.TODO(), "hello-world", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []v1.TargetReplies{
+		{Name: DefaultTarget, Replies: []v1.Reply{{ID: "2", Author: "alice", Text: "@bob Hi", Time: metav1.NewTime(at)}}},
+		{Name: "fediverse"},
+	}, object.Status.Targets)
+
+	replies, err = twitter.GetReplies(context.TODO(), tweet)
+	assert.NoError(t, err)
+	assert.Equal(t, []tweettypes.Reply{reply}, replies)
+	replies, err = fediverse.GetReplies(context.TODO(), tweet)
+	assert.NoError(t, err)
+	assert.Empty(t, replies)
+}
@@ -0,0 +1,94 @@
+package k8sclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.org/x/oauth2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Keys of the OAuth 2.0 token in the Secret
+const (
+	SecretKeyAccessToken  = "OAUTH2_ACCESS_TOKEN"
+	SecretKeyRefreshToken = "OAUTH2_REFRESH_TOKEN"
+	SecretKeyTokenType    = "OAUTH2_TOKEN_TYPE"
+	SecretKeyExpiry       = "OAUTH2_EXPIRY"
+)
+
+type secretClient interface {
+	Create(ctx context.Context, secret *corev1.Secret, opts metav1.CreateOptions) (*corev1.Secret, error)
+	Update(ctx context.Context, secret *corev1.Secret, opts metav1.UpdateOptions) (*corev1.Secret, error)
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*corev1.Secret, error)
+}
+
+// SecretTokenStore keeps an OAuth 2.0 token in a Secret. Other keys in the
+// Secret are left alone, so it can share one with the other credentials.
+type SecretTokenStore struct {
+	secretClient secretClient
+	name         string
+}
+
+func NewSecretTokenStore(secretClient secretClient, name string) *SecretTokenStore {
+	return &SecretTokenStore{
+		secretClient: secretClient,
+		name:         name,
+	}
+}
+
+func (s *SecretTokenStore) LoadToken() (*oauth2.Token, error) {
+	secret, err := s.secretClient.Get(context.TODO(), s.name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	token := &oauth2.Token{
+		AccessToken:  string(secret.Data[SecretKeyAccessToken]),
+		RefreshToken: string(secret.Data[SecretKeyRefreshToken]),
+		TokenType:    string(secret.Data[SecretKeyTokenType]),
+	}
+	if expiry := string(secret.Data[SecretKeyExpiry]); expiry != "" {
+		token.Expiry, err = time.Parse(time.RFC3339, expiry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in secret %s: %v", SecretKeyExpiry, s.name, err)
+		}
+	}
+	return token, nil
+}
+
+func (s *SecretTokenStore) SaveToken(token *oauth2.Token) error {
+	secret, err := s.secretClient.Get(context.TODO(), s.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: s.name,
+			},
+		}
+		setToken(secret, token)
+		_, err = s.secretClient.Create(context.TODO(), secret, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	secret = secret.DeepCopy()
+	setToken(secret, token)
+	_, err = s.secretClient.Update(context.TODO(), secret, metav1.UpdateOptions{})
+	return err
+}
+
+func setToken(secret *corev1.Secret, token *oauth2.Token) {
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[SecretKeyAccessToken] = []byte(token.AccessToken)
+	secret.Data[SecretKeyRefreshToken] = []byte(token.RefreshToken)
+	secret.Data[SecretKeyTokenType] = []byte(token.TokenType)
+	if token.Expiry.IsZero() {
+		delete(secret.Data, SecretKeyExpiry)
+	} else {
+		secret.Data[SecretKeyExpiry] = []byte(token.Expiry.UTC().Format(time.RFC3339))
+	}
+}
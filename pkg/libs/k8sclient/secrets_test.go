@@ -0,0 +1,75 @@
+package k8sclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_SecretTokenStore(t *testing.T) {
+	expiry := time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+	token := &oauth2.Token{
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+		TokenType:    "bearer",
+		Expiry:       expiry,
+	}
+
+	tests := map[string]struct {
+		existing []*corev1.Secret
+		data     map[string][]byte
+	}{
+		"secret is created": {
+			data: map[string][]byte{
+				SecretKeyAccessToken:  []byte("access"),
+				SecretKeyRefreshToken: []byte("refresh"),
+				SecretKeyTokenType:    []byte("bearer"),
+				SecretKeyExpiry:       []byte("2022-07-01T12:00:00Z"),
+			},
+		},
+		"other keys are kept": {
+			existing: []*corev1.Secret{{
+				ObjectMeta: metav1.ObjectMeta{Name: "twitter-credentials", Namespace: "default"},
+				Data: map[string][]byte{
+					"TWITTER_USERNAME":    []byte("bob"),
+					SecretKeyRefreshToken: []byte("old"),
+				},
+			}},
+			data: map[string][]byte{
+				"TWITTER_USERNAME":    []byte("bob"),
+				SecretKeyAccessToken:  []byte("access"),
+				SecretKeyRefreshToken: []byte("refresh"),
+				SecretKeyTokenType:    []byte("bearer"),
+				SecretKeyExpiry:       []byte("2022-07-01T12:00:00Z"),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			for _, secret := range test.existing {
+				clientset.Tracker().Add(secret)
+			}
+			secrets := clientset.CoreV1().Secrets("default")
+			store := NewSecretTokenStore(secrets, "twitter-credentials")
+
+			assert.NoError(t, store.SaveToken(token))
+
+			secret, err := secrets.Get(context.TODO(), "twitter-credentials", metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.Equal(t, test.data, secret.Data)
+
+			loaded, err := store.LoadToken()
+			assert.NoError(t, err)
+			assert.Equal(t, token.RefreshToken, loaded.RefreshToken)
+			assert.True(t, expiry.Equal(loaded.Expiry))
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package k8sclient
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	v1 "github.com/jonatanblue/tweet-operator/pkg/apis/example.com/v1"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultTarget names the Twitter account configured through the
+// operator's environment.
+const DefaultTarget = "twitter"
+
+// ReasonUnknownTarget is the reason of the Ready condition of a target no
+// account is configured for, such as an Account that was skipped.
+const ReasonUnknownTarget = "UnknownTarget"
+
+// conditionReady is the type of reconciler.ConditionReady
+const conditionReady = "Ready"
+
+// UnknownTargetGracePeriod is how long a deleted Tweet waits for an unknown
+// target to come back and delete its post. After that the post is left
+// behind, so the Tweet can go.
+const UnknownTargetGracePeriod = time.Hour
+
+// AbandonedPost is a post on an unknown target that was left behind when
+// its Tweet was deleted.
+type AbandonedPost struct {
+	Tweet  string
+	Target string
+	ID     int64
+	URL    string
+}
+
+// UnknownTargetClient looks after the targets of Tweets that none of the
+// configured targets reconciles.
+type UnknownTargetClient struct {
+	tweetClient tweetClient
+	known       map[string]bool
+	now         func() time.Time
+}
+
+// NewUnknownTargetClient returns a client for the Tweets' targets other
+// than known, which has to hold the default target.
+func NewUnknownTargetClient(tweetClient tweetClient, known []string) *UnknownTargetClient {
+	c := &UnknownTargetClient{
+		tweetClient: tweetClient,
+		known:       map[string]bool{},
+		now:         time.Now,
+	}
+	for _, name := range known {
+		c.known[name] = true
+	}
+	return c
+}
+
+// Check sets the Ready condition of every unknown target to false. Once a
+// deleted Tweet has waited UnknownTargetGracePeriod, their entries are
+// dropped, and the finalizer too if no known target has a post left. It
+// returns the posts that were left behind.
+func (c *UnknownTargetClient) Check(ctx context.Context) ([]AbandonedPost, error) {
+	list, err := c.tweetClient.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	abandoned := []AbandonedPost{}
+	for i := range list.Items {
+		tweet := &list.Items[i]
+		new := tweet.DeepCopy()
+		posts := c.check(new)
+		if reflect.DeepEqual(tweet, new) {
+			continue
+		}
+		if _, err := c.tweetClient.Update(ctx, new, metav1.UpdateOptions{}); err != nil {
+			return abandoned, err
+		}
+		abandoned = append(abandoned, posts...)
+	}
+	return abandoned, nil
+}
+
+func (c *UnknownTargetClient) check(tweet *v1.Tweet) []AbandonedPost {
+	abandoned := []AbandonedPost{}
+	expired := tweet.DeletionTimestamp != nil && c.now().Sub(tweet.DeletionTimestamp.Time) >= UnknownTargetGracePeriod
+	targeted := map[string]bool{}
+	for _, name := range tweet.Spec.Targets {
+		targeted[name] = tweet.DeletionTimestamp == nil
+	}
+	statuses := []v1.TargetStatus{}
+	seen := map[string]bool{}
+	for _, status := range tweet.Status.Targets {
+		seen[status.Name] = true
+		switch {
+		case c.known[status.Name]:
+		case status.ID == 0 && !targeted[status.Name]:
+			// Nothing left to report
+			continue
+		case expired:
+			abandoned = append(abandoned, AbandonedPost{
+				Tweet:  tweet.Namespace + "/" + tweet.Name,
+				Target: status.Name,
+				ID:     status.ID,
+				URL:    status.URL,
+			})
+			continue
+		default:
+			c.setUnknown(tweet, &status)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, name := range tweet.Spec.Targets {
+		if !targeted[name] || c.known[name] || seen[name] {
+			continue
+		}
+		seen[name] = true
+		status := v1.TargetStatus{Name: name}
+		c.setUnknown(tweet, &status)
+		statuses = append(statuses, status)
+	}
+	if len(statuses) == 0 {
+		statuses = nil
+	}
+	tweet.Status.Targets = statuses
+	if expired && len(abandoned) > 0 && !hasPosts(tweet) {
+		finalizers := []string{}
+		for _, f := range tweet.Finalizers {
+			if f != Finalizer {
+				finalizers = append(finalizers, f)
+			}
+		}
+		tweet.Finalizers = finalizers
+	}
+	return abandoned
+}
+
+func (c *UnknownTargetClient) setUnknown(tweet *v1.Tweet, status *v1.TargetStatus) {
+	message := fmt.Sprintf("No account named %s is configured", status.Name)
+	if tweet.DeletionTimestamp != nil && status.ID != 0 {
+		message += fmt.Sprintf(", so post %d is left behind %s after the Tweet was deleted", status.ID, UnknownTargetGracePeriod)
+	}
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               conditionReady,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: tweet.Generation,
+		Reason:             ReasonUnknownTarget,
+		Message:            message,
+	})
+}
+
+// targeted reports whether the Tweet should be posted to the target.
+func (c *K8sClient) targeted(tweet *v1.Tweet) bool {
+	if len(tweet.Spec.Targets) == 0 {
+		return c.defaultTarget
+	}
+	for _, target := range tweet.Spec.Targets {
+		if target == c.target {
+			return true
+		}
+	}
+	return false
+}
+
+// findTargetStatus returns the target's status entry, or nil if it has none.
+func (c *K8sClient) findTargetStatus(tweet *v1.Tweet) *v1.TargetStatus {
+	for i := range tweet.Status.Targets {
+		if tweet.Status.Targets[i].Name == c.target {
+			return &tweet.Status.Targets[i]
+		}
+	}
+	if c.defaultTarget && tweet.Status.ID != 0 {
+		return &v1.TargetStatus{
+			Name:     c.target,
+			ID:       tweet.Status.ID,
+			RemoteID: tweet.Status.RemoteID,
+			Likes:    tweet.Status.Likes,
+			Retweets: tweet.Status.Retweets,
+			Replies:  tweet.Status.Replies,
+		}
+	}
+	return nil
+}
+
+// targetStatus returns a copy of the target's status entry, or an empty one.
+func (c *K8sClient) targetStatus(tweet *v1.Tweet) v1.TargetStatus {
+	if status := c.findTargetStatus(tweet); status != nil {
+		return *status.DeepCopy()
+	}
+	return v1.TargetStatus{Name: c.target}
+}
+
+// setTargetStatus stores the target's status entry. An entry without a post
+// is dropped once the Tweet no longer targets the target, since there is
+// nothing left to report there.
+func (c *K8sClient) setTargetStatus(tweet *v1.Tweet, status v1.TargetStatus) {
+	keep := status.ID != 0 || c.targeted(tweet) && tweet.DeletionTimestamp == nil
+	for i := range tweet.Status.Targets {
+		if tweet.Status.Targets[i].Name != c.target {
+			continue
+		}
+		if keep {
+			tweet.Status.Targets[i] = status
+		} else {
+			tweet.Status.Targets = append(tweet.Status.Targets[:i], tweet.Status.Targets[i+1:]...)
+		}
+		return
+	}
+	if keep {
+		tweet.Status.Targets = append(tweet.Status.Targets, status)
+	}
+}
+
+// migrateStatus moves the status of a Tweet from before targets existed
+// into the default target's entry.
+func (c *K8sClient) migrateStatus(tweet *v1.Tweet) {
+	if !c.defaultTarget || tweet.Status.ID == 0 {
+		return
+	}
+	status := c.targetStatus(tweet)
+	tweet.Status.ID = 0
+	tweet.Status.RemoteID = ""
+	tweet.Status.Likes = 0
+	tweet.Status.Retweets = 0
+	tweet.Status.Replies = 0
+	c.setTargetStatus(tweet, status)
+}
+
+// hasPosts reports whether any target still has a post of the Tweet.
+func hasPosts(tweet *v1.Tweet) bool {
+	if tweet.Status.ID != 0 {
+		return true
+	}
+	for _, status := range tweet.Status.Targets {
+		if status.ID != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func toCondition(condition tweettypes.Condition, generation int64) metav1.Condition {
+	status := metav1.ConditionFalse
+	if condition.Status {
+		status = metav1.ConditionTrue
+	}
+	return metav1.Condition{
+		Type:               condition.Type,
+		Status:             status,
+		ObservedGeneration: generation,
+		Reason:             condition.Reason,
+		Message:            condition.Message,
+	}
+}
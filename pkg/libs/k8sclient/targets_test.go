@@ -0,0 +1,248 @@
+package k8sclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/jonatanblue/tweet-operator/pkg/apis/example.com/v1"
+	tweetfake "github.com/jonatanblue/tweet-operator/pkg/client/clientset/versioned/fake"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_ListTweetsTargets(t *testing.T) {
+	tweets := []v1.Tweet{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "untargeted", Namespace: "default"},
+			Spec:       v1.TweetSpec{Text: "Default target only"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "cross-posted", Namespace: "default"},
+			Spec:       v1.TweetSpec{Text: "Everywhere", Targets: []string{DefaultTarget, "fediverse"}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "retargeted", Namespace: "default"},
+			Spec:       v1.TweetSpec{Text: "Moved", Targets: []string{DefaultTarget}},
+			Status: v1.TweetStatus{Targets: []v1.TargetStatus{
+				{Name: "fediverse", ID: 7, URL: "https://mastodon.example/@bob/7"},
+			}},
+		},
+	}
+
+	tests := map[string]struct {
+		target        string
+		defaultTarget bool
+		expected      map[string]tweettypes.Tweet
+	}{
+		"default target": {
+			target:        DefaultTarget,
+			defaultTarget: true,
+			expected: map[string]tweettypes.Tweet{
+				"untargeted":   {Spec: tweettypes.TweetSpec{Text: "Default target only"}},
+				"cross-posted": {Spec: tweettypes.TweetSpec{Text: "Everywhere"}},
+				"retargeted":   {Spec: tweettypes.TweetSpec{Text: "Moved"}},
+			},
+		},
+		"other target": {
+			target: "fediverse",
+			expected: map[string]tweettypes.Tweet{
+				"cross-posted": {Spec: tweettypes.TweetSpec{Text: "Everywhere"}},
+				// No longer targeted, so its post is deleted
+				"retargeted": {Status: tweettypes.TweetStatus{ID: 7, URL: "https://mastodon.example/@bob/7"}},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			clientset := tweetfake.NewSimpleClientset(&v1.TweetList{Items: tweets})
+			client := NewK8sClient(clientset.ExampleV1().Tweets("default")).ForTarget(test.target, test.defaultTarget)
+
+			list, err := client.ListTweets(context.TODO())
+			assert.NoError(t, err)
+			got := map[string]tweettypes.Tweet{}
+			for _, tweet := range *list {
+				assert.Equal(t, test.target, tweet.Spec.Target)
+				got[tweet.Spec.Name] = tweettypes.Tweet{
+					Spec:   tweettypes.TweetSpec{Text: tweet.Spec.Text},
+					Status: tweet.Status,
+				}
+			}
+			assert.Equal(t, test.expected, got)
+		})
+	}
+}
+
+func Test_TargetStatuses(t *testing.T) {
+	clientset := tweetfake.NewSimpleClientset(&v1.Tweet{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello-world", Namespace: "default"},
+		Spec:       v1.TweetSpec{Text: "Hello World", Targets: []string{DefaultTarget, "fediverse"}},
+	})
+	tweets := clientset.ExampleV1().Tweets("default")
+	twitter := NewK8sClient(tweets)
+	fediverse := twitter.ForTarget("fediverse", false)
+	get := func() *v1.Tweet {
+		tweet, err := tweets.Get(context.TODO(), "hello-world", metav1.GetOptions{})
+		assert.NoError(t, err)
+		return tweet
+	}
+
+	_, err := twitter.UpdateStatus(context.TODO(), "hello-world", &tweettypes.Tweet{Status: tweettypes.TweetStatus{ID: 1, Likes: 2, Impressions: 30}})
+	assert.NoError(t, err)
+	err = fediverse.SetCondition(context.TODO(), "hello-world", tweettypes.Condition{Type: "Ready", Reason: "PostFailed", Message: "unauthorized"})
+	assert.NoError(t, err)
+
+	tweet := get()
+	assert.Equal(t, []string{Finalizer}, tweet.Finalizers)
+	assert.Len(t, tweet.Status.Targets, 2)
+	assert.Equal(t, DefaultTarget, tweet.Status.Targets[0].Name)
+	assert.Equal(t, int64(1), tweet.Status.Targets[0].ID)
+	assert.Equal(t, int64(2), tweet.Status.Targets[0].Likes)
+	assert.Equal(t, int64(30), tweet.Status.Targets[0].Impressions)
+	assert.Equal(t, "fediverse", tweet.Status.Targets[1].Name)
+	assert.Equal(t, metav1.ConditionFalse, tweet.Status.Targets[1].Conditions[0].Status)
+	assert.Equal(t, "PostFailed", tweet.Status.Targets[1].Conditions[0].Reason)
+
+	// The condition survives status updates
+	_, err = fediverse.UpdateStatus(context.TODO(), "hello-world", &tweettypes.Tweet{Status: tweettypes.TweetStatus{ID: 3}})
+	assert.NoError(t, err)
+	tweet = get()
+	assert.Equal(t, int64(3), tweet.Status.Targets[1].ID)
+	assert.Len(t, tweet.Status.Targets[1].Conditions, 1)
+
+	// The finalizer stays while any target still has a post
+	deletedAt := metav1.Now()
+	tweet.DeletionTimestamp = &deletedAt
+	_, err = tweets.Update(context.TODO(), tweet, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	assert.NoError(t, twitter.RemoveFinalizer(context.TODO(), "hello-world"))
+	tweet = get()
+	assert.Equal(t, []string{Finalizer}, tweet.Finalizers)
+	assert.Len(t, tweet.Status.Targets, 1)
+
+	assert.NoError(t, fediverse.RemoveFinalizer(context.TODO(), "hello-world"))
+	tweet = get()
+	assert.Empty(t, tweet.Finalizers)
+	assert.Empty(t, tweet.Status.Targets)
+}
+
+func Test_UnknownTargetClientCheck(t *testing.T) {
+	now := time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+	deletedAt := func(ago time.Duration) *metav1.Time {
+		at := metav1.NewTime(now.Add(-ago))
+		return &at
+	}
+	unknownReady := func(tweet *v1.Tweet, target string) *metav1.Condition {
+		for _, status := range tweet.Status.Targets {
+			if status.Name == target {
+				return meta.FindStatusCondition(status.Conditions, "Ready")
+			}
+		}
+		return nil
+	}
+
+	tests := map[string]struct {
+		tweet      *v1.Tweet
+		finalizers []string
+		targets    []string
+		abandoned  []AbandonedPost
+	}{
+		"targeted unknown account": {
+			tweet: &v1.Tweet{
+				ObjectMeta: metav1.ObjectMeta{Name: "hello-world", Namespace: "default"},
+				Spec:       v1.TweetSpec{Text: "Hello World", Targets: []string{DefaultTarget, "skipped"}},
+			},
+			targets:   []string{"skipped"},
+			abandoned: []AbandonedPost{},
+		},
+		"deleted within the grace period": {
+			tweet: &v1.Tweet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "hello-world",
+					Namespace:         "default",
+					Finalizers:        []string{Finalizer},
+					DeletionTimestamp: deletedAt(time.Minute),
+				},
+				Spec:   v1.TweetSpec{Text: "Hello World", Targets: []string{"skipped"}},
+				Status: v1.TweetStatus{Targets: []v1.TargetStatus{{Name: "skipped", ID: 7}}},
+			},
+			finalizers: []string{Finalizer},
+			targets:    []string{"skipped"},
+			abandoned:  []AbandonedPost{},
+		},
+		"deleted after the grace period": {
+			tweet: &v1.Tweet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "hello-world",
+					Namespace:         "default",
+					Finalizers:        []string{Finalizer},
+					DeletionTimestamp: deletedAt(UnknownTargetGracePeriod),
+				},
+				Spec:   v1.TweetSpec{Text: "Hello World", Targets: []string{"skipped"}},
+				Status: v1.TweetStatus{Targets: []v1.TargetStatus{{Name: "skipped", ID: 7, URL: "https://mastodon.example/@bob/7"}}},
+			},
+			abandoned: []AbandonedPost{{Tweet: "default/hello-world", Target: "skipped", ID: 7, URL: "https://mastodon.example/@bob/7"}},
+		},
+		"deleted after the grace period with a post on a known target": {
+			tweet: &v1.Tweet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "hello-world",
+					Namespace:         "default",
+					Finalizers:        []string{Finalizer},
+					DeletionTimestamp: deletedAt(UnknownTargetGracePeriod),
+				},
+				Spec: v1.TweetSpec{Text: "Hello World", Targets: []string{DefaultTarget, "skipped"}},
+				Status: v1.TweetStatus{Targets: []v1.TargetStatus{
+					{Name: DefaultTarget, ID: 1},
+					{Name: "skipped", ID: 7},
+				}},
+			},
+			finalizers: []string{Finalizer},
+			targets:    []string{DefaultTarget},
+			abandoned:  []AbandonedPost{{Tweet: "default/hello-world", Target: "skipped", ID: 7}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tweets := tweetfake.NewSimpleClientset(test.tweet).ExampleV1().Tweets("default")
+			client := NewUnknownTargetClient(tweets, []string{DefaultTarget, "fediverse"})
+			client.now = func() time.Time { return now }
+
+			abandoned, err := client.Check(context.TODO())
+			assert.NoError(t, err)
+			assert.Equal(t, test.abandoned, abandoned)
+
+			tweet, err := tweets.Get(context.TODO(), "hello-world", metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.Equal(t, test.finalizers, nilIfEmpty(tweet.Finalizers))
+			targets := []string{}
+			for _, status := range tweet.Status.Targets {
+				targets = append(targets, status.Name)
+			}
+			assert.Equal(t, test.targets, nilIfEmpty(targets))
+			if len(test.abandoned) == 0 {
+				ready := unknownReady(tweet, "skipped")
+				if assert.NotNil(t, ready) {
+					assert.Equal(t, metav1.ConditionFalse, ready.Status)
+					assert.Equal(t, ReasonUnknownTarget, ready.Reason)
+				}
+			}
+
+			// Nothing changes on the next pass
+			again, err := client.Check(context.TODO())
+			assert.NoError(t, err)
+			assert.Empty(t, again)
+		})
+	}
+}
+
+func nilIfEmpty(s []string) []string {
+	if len(s) == 0 {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+)
+
+type Format string
+
+const (
+	FormatJSON = Format("json")
+	FormatText = Format("text")
+)
+
+// Verbosity levels used throughout the operator. Level 0 is always on and
+// is reserved for remote side effects and errors.
+const (
+	LevelInfo  = 0
+	LevelDebug = 1
+	// LevelText is the lowest level at which tweet text is logged in clear.
+	LevelText = 2
+)
+
+func New(out io.Writer, format Format, verbosity int) (logr.Logger, error) {
+	opts := funcr.Options{
+		LogTimestamp: true,
+		Verbosity:    verbosity,
+	}
+	switch format {
+	case FormatJSON:
+		return funcr.NewJSON(func(obj string) {
+			fmt.Fprintln(out, obj)
+		}, opts), nil
+	case FormatText:
+		return funcr.New(func(prefix, args string) {
+			if prefix != "" {
+				fmt.Fprintln(out, prefix, args)
+			} else {
+				fmt.Fprintln(out, args)
+			}
+		}, opts), nil
+	}
+	return logr.Discard(), fmt.Errorf("unknown log format %q", format)
+}
+
+func ParseVerbosity(value string) (int, error) {
+	if value == "" {
+		return LevelInfo, nil
+	}
+	verbosity, err := strconv.Atoi(value)
+	if err != nil || verbosity < 0 {
+		return 0, fmt.Errorf("invalid log verbosity %q", value)
+	}
+	return verbosity, nil
+}
+
+// WithTweet returns a logger carrying the fields that identify a tweet, so
+// every line logged about it can be correlated.
+func WithTweet(log logr.Logger, tweet *tweettypes.Tweet) logr.Logger {
+	log = log.WithValues(
+		"namespace", tweet.Spec.Namespace,
+		"name", tweet.Spec.Name,
+		"tweetID", tweet.Status.ID,
+	)
+	if tweet.Spec.Target != "" {
+		log = log.WithValues("target", tweet.Spec.Target)
+	}
+	return log
+}
+
+// Text returns the tweet text if the logger is verbose enough to show it,
+// and a placeholder otherwise. Unpublished text is treated as sensitive.
+func Text(log logr.Logger, text string) string {
+	if log.V(LevelText).Enabled() {
+		return text
+	}
+	return fmt.Sprintf("<redacted %d chars>", len([]rune(text)))
+}
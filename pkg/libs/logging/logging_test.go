@@ -0,0 +1,105 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	tests := map[string]struct {
+		format Format
+		err    error
+	}{
+		"json":    {format: FormatJSON},
+		"text":    {format: FormatText},
+		"unknown": {format: Format("xml"), err: errors.New(`unknown log format "xml"`)},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			_, err := New(&out, test.format, 0)
+			if test.err != nil {
+				assert.EqualError(t, err, test.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_WithTweet(t *testing.T) {
+	var out bytes.Buffer
+	log, err := New(&out, FormatJSON, 0)
+	assert.NoError(t, err)
+
+	tweet := &tweettypes.Tweet{
+		Spec: tweettypes.TweetSpec{
+			Namespace: "default",
+			Name:      "hello-world",
+			Text:      "Hello World",
+		},
+		Status: tweettypes.TweetStatus{
+			ID: 12345,
+		},
+	}
+	WithTweet(log, tweet).Info("Posting tweet", "text", Text(log, tweet.Spec.Text))
+
+	line := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, "Posting tweet", line["msg"])
+	assert.Equal(t, "default", line["namespace"])
+	assert.Equal(t, "hello-world", line["name"])
+	assert.Equal(t, float64(12345), line["tweetID"])
+	assert.Equal(t, "<redacted 11 chars>", line["text"])
+}
+
+func Test_Text(t *testing.T) {
+	tests := map[string]struct {
+		verbosity int
+		want      string
+	}{
+		"redacted at default verbosity": {verbosity: 0, want: "<redacted 11 chars>"},
+		"redacted at debug verbosity":   {verbosity: LevelDebug, want: "<redacted 11 chars>"},
+		"clear at text verbosity":       {verbosity: LevelText, want: "Hello World"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			log, err := New(&out, FormatJSON, test.verbosity)
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, Text(log, "Hello World"))
+		})
+	}
+}
+
+func Test_ParseVerbosity(t *testing.T) {
+	tests := map[string]struct {
+		in   string
+		want int
+		err  bool
+	}{
+		"empty defaults to info": {in: "", want: LevelInfo},
+		"number":                 {in: "2", want: 2},
+		"negative":               {in: "-1", err: true},
+		"not a number":           {in: "loud", err: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			verbosity, err := ParseVerbosity(test.in)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, verbosity)
+		})
+	}
+}
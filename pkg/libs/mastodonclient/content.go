@@ -0,0 +1,36 @@
+package mastodonclient
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// contentToText recovers the posted text from a status' HTML content, so it
+// can be compared with the Tweet text. Mastodon wraps paragraphs in <p>,
+// turns line breaks into <br> and splits links into spans, some of them
+// hidden, that together still hold the full URL.
+func contentToText(content string) string {
+	var text strings.Builder
+	tokenizer := html.NewTokenizer(strings.NewReader(content))
+	paragraphs := 0
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return text.String()
+		case html.TextToken:
+			text.Write(tokenizer.Text())
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := tokenizer.TagName()
+			switch string(name) {
+			case "br":
+				text.WriteString("\n")
+			case "p":
+				if paragraphs > 0 {
+					text.WriteString("\n\n")
+				}
+				paragraphs++
+			}
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package mastodonclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_contentToText(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		text    string
+	}{
+		"plain": {
+			content: "<p>Hello World</p>",
+			text:    "Hello World",
+		},
+		"escaped": {
+			content: "<p>Fish &amp; chips &lt;3</p>",
+			text:    "Fish & chips <3",
+		},
+		"line breaks and paragraphs": {
+			content: "<p>Hello<br />World</p><p>Bye</p>",
+			text:    "Hello\nWorld\n\nBye",
+		},
+		"shortened link": {
+			content: `<p>See <a href="https://example.com/a/long/path" rel="nofollow noopener noreferrer" target="_blank">` +
+				`<span class="invisible">https://</span><span class="ellipsis">example.com/a/lo</span>` +
+				`<span class="invisible">ng/path</span></a></p>`,
+			text: "See https://example.com/a/long/path",
+		},
+		"mention": {
+			content: `<p><span class="h-card"><a href="https://example.com/@alice" class="u-url mention">@<span>alice</span></a></span> hi</p>`,
+			text:    "@alice hi",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.text, contentToText(test.content))
+		})
+	}
+}
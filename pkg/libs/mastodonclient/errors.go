@@ -0,0 +1,32 @@
+package mastodonclient
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/jonatanblue/tweet-operator/pkg/libs/twitterclient"
+)
+
+// Kind classifies the error like the Twitter errors the reconciler acts on.
+// Mastodon answers a suspended or disabled account with a 403 saying so.
+func (e *APIError) Kind() twitterclient.ErrorKind {
+	message := strings.ToLower(e.Message)
+	if e.StatusCode == http.StatusForbidden && (strings.Contains(message, "suspended") || strings.Contains(message, "disabled")) {
+		return twitterclient.KindSuspended
+	}
+	return twitterclient.KindOfStatus(e.StatusCode)
+}
+
+func IsNotFound(err error) bool {
+	return hasStatusCode(err, http.StatusNotFound)
+}
+
+func IsUnauthorized(err error) bool {
+	return hasStatusCode(err, http.StatusUnauthorized)
+}
+
+func hasStatusCode(err error, statusCode int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
@@ -0,0 +1,393 @@
+// Package mastodonclient implements the operator's TwitterClient on top of
+// the Mastodon API, so Tweets can be posted to a Mastodon account instead.
+package mastodonclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jonatanblue/tweet-operator/pkg/libs/twitterclient"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+)
+
+const (
+	// Account statuses take at most 40 per page
+	statusesPageSize = 40
+	// Notifications take at most 40 per page
+	notificationsPageSize = 40
+	// rateLimitWindow is assumed when a 429 comes without a reset header
+	rateLimitWindow = 5 * time.Minute
+)
+
+// Endpoints, named after the Mastodon API paths
+const (
+	endpointVerifyCredentials = "GET /api/v1/accounts/verify_credentials"
+	endpointAccountsLookup    = "GET /api/v1/accounts/lookup"
+	endpointAccountStatuses   = "GET /api/v1/accounts/:id/statuses"
+	endpointStatusesGet       = "GET /api/v1/statuses/:id"
+	endpointStatusesContext   = "GET /api/v1/statuses/:id/context"
+	endpointStatusesCreate    = "POST /api/v1/statuses"
+	endpointStatusesDelete    = "DELETE /api/v1/statuses/:id"
+	endpointNotifications     = "GET /api/v1/notifications"
+)
+
+// APIError is returned for non-2xx responses.
+type APIError struct {
+	StatusCode int
+	Message    string `json:"error"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("mastodon: %d %s", e.StatusCode, e.Message)
+}
+
+type status struct {
+	ID              string `json:"id"`
+	URL             string `json:"url"`
+	Content         string `json:"content"`
+	FavouritesCount int64  `json:"favourites_count"`
+	ReblogsCount    int64  `json:"reblogs_count"`
+	RepliesCount    int64  `json:"replies_count"`
+}
+
+// reply is a status in the descendants of a status' context, or the status
+// of a mention notification
+type reply struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+	Account   struct {
+		Acct string `json:"acct"`
+	} `json:"account"`
+}
+
+type account struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+// MastodonClient posts to and reads from one Mastodon account. Favourites,
+// reblogs and replies are reported as likes, retweets and replies.
+type MastodonClient struct {
+	httpClient       *http.Client
+	instanceURL      string
+	accessToken      string
+	timelineMaxPages int
+	now              func() time.Time
+
+	mu         sync.Mutex
+	accountIDs map[string]string
+}
+
+func NewMastodonClient(
+	httpClient *http.Client,
+	instanceURL string,
+	accessToken string,
+	timelineMaxPages int,
+) *MastodonClient {
+	return &MastodonClient{
+		httpClient:       httpClient,
+		instanceURL:      strings.TrimSuffix(instanceURL, "/"),
+		accessToken:      accessToken,
+		timelineMaxPages: timelineMaxPages,
+		now:              time.Now,
+		accountIDs:       map[string]string{},
+	}
+}
+
+func (c *MastodonClient) VerifyCredentials(ctx context.Context) error {
+	var resp account
+	return c.do(ctx, endpointVerifyCredentials, http.MethodGet, "/api/v1/accounts/verify_credentials", nil, nil, &resp)
+}
+
+// GetTweetsForUser returns the account's statuses, newest first. It pages
+// back until all tracked statuses are found or timelineMaxPages is reached,
+// then fetches tracked statuses it did not come across one by one.
+func (c *MastodonClient) GetTweetsForUser(ctx context.Context, userName string, trackedIDs ...int64) (tweettypes.Tweets, error) {
+	accountID, err := c.accountID(ctx, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := map[int64]bool{}
+	for _, id := range trackedIDs {
+		if id > 0 {
+			missing[id] = true
+		}
+	}
+
+	result := tweettypes.Tweets{}
+	maxID := ""
+	for page := 0; page < c.timelineMaxPages; page++ {
+		query := url.Values{
+			"limit":           {strconv.Itoa(statusesPageSize)},
+			"exclude_reblogs": {"true"},
+		}
+		if maxID != "" {
+			query.Set("max_id", maxID)
+		}
+		var resp []status
+		err := c.do(ctx, endpointAccountStatuses, http.MethodGet, "/api/v1/accounts/"+url.PathEscape(accountID)+"/statuses", query, nil, &resp)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range resp {
+			tweet, err := s.toTweet()
+			if err != nil {
+				return nil, err
+			}
+			delete(missing, tweet.Status.ID)
+			result = append(result, tweet)
+		}
+		if len(missing) == 0 || len(resp) == 0 {
+			break
+		}
+		maxID = resp[len(resp)-1].ID
+	}
+
+	for id := range missing {
+		var resp status
+		err := c.do(ctx, endpointStatusesGet, http.MethodGet, "/api/v1/statuses/"+strconv.FormatInt(id, 10), nil, nil, &resp)
+		if IsNotFound(err) {
+			// Deleted, which the reconciler finds out by its absence
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		tweet, err := resp.toTweet()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, tweet)
+	}
+	return result, nil
+}
+
+// PostTweet posts a public status. The Tweet's UID and a hash of what is
+// posted are sent as idempotency key, so a retried post does not create a
+// second status, while an edited Tweet is posted anew. Mastodon returns the
+// status a key created for about an hour, even once it is deleted.
+func (c *MastodonClient) PostTweet(ctx context.Context, tweet *tweettypes.Tweet) (int64, error) {
+	form := url.Values{
+		"status":     {tweet.Spec.Text},
+		"visibility": {"public"},
+	}
+	if tweet.Spec.InReplyTo != "" {
+		form.Set("in_reply_to_id", tweet.Spec.InReplyTo)
+	}
+	var resp status
+	err := c.do(ctx, endpointStatusesCreate, http.MethodPost, "/api/v1/statuses", nil, form, &resp, idempotencyKey(postIdempotencyKey(tweet)))
+	if err != nil {
+		return 0, err
+	}
+	return parseID(resp.ID)
+}
+
+func (c *MastodonClient) DeleteTweet(ctx context.Context, tweet *tweettypes.Tweet) error {
+	var resp status
+	return c.do(ctx, endpointStatusesDelete, http.MethodDelete, "/api/v1/statuses/"+strconv.FormatInt(tweet.Status.ID, 10), nil, nil, &resp)
+}
+
+// GetReplies returns the most recent statuses in the thread below the
+// status, including replies to replies.
+func (c *MastodonClient) GetReplies(ctx context.Context, tweet *tweettypes.Tweet, max int) ([]tweettypes.Reply, error) {
+	var resp struct {
+		Descendants []reply `json:"descendants"`
+	}
+	path := "/api/v1/statuses/" + strconv.FormatInt(tweet.Status.ID, 10) + "/context"
+	if err := c.do(ctx, endpointStatusesContext, http.MethodGet, path, nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	// Descendants come in thread order, not by time
+	sort.SliceStable(resp.Descendants, func(i, j int) bool {
+		return resp.Descendants[i].CreatedAt.After(resp.Descendants[j].CreatedAt)
+	})
+	replies := []tweettypes.Reply{}
+	for _, r := range resp.Descendants {
+		if len(replies) == max {
+			break
+		}
+		replies = append(replies, tweettypes.Reply{
+			ID:     r.ID,
+			Author: "@" + r.Account.Acct,
+			Text:   contentToText(r.Content),
+			Time:   r.CreatedAt,
+		})
+	}
+	return replies, nil
+}
+
+// GetMentions returns the mentions of the authenticated account after
+// sinceID, newest first. Their IDs are those of the notifications, which
+// since_id pages by. Only the most recent page is read.
+func (c *MastodonClient) GetMentions(ctx context.Context, userName, sinceID string) ([]tweettypes.Mention, error) {
+	query := url.Values{
+		"types[]": {"mention"},
+		"limit":   {strconv.Itoa(notificationsPageSize)},
+	}
+	if sinceID != "" {
+		query.Set("since_id", sinceID)
+	}
+	var resp []struct {
+		ID     string `json:"id"`
+		Status reply  `json:"status"`
+	}
+	if err := c.do(ctx, endpointNotifications, http.MethodGet, "/api/v1/notifications", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	mentions := []tweettypes.Mention{}
+	for _, n := range resp {
+		mentions = append(mentions, tweettypes.Mention{
+			ID:     n.ID,
+			PostID: n.Status.ID,
+			Author: "@" + n.Status.Account.Acct,
+			Text:   contentToText(n.Status.Content),
+			Time:   n.Status.CreatedAt,
+			URL:    n.Status.URL,
+		})
+	}
+	return mentions, nil
+}
+
+func (c *MastodonClient) accountID(ctx context.Context, userName string) (string, error) {
+	c.mu.Lock()
+	id, ok := c.accountIDs[userName]
+	c.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	var resp account
+	err := c.do(ctx, endpointAccountsLookup, http.MethodGet, "/api/v1/accounts/lookup", url.Values{"acct": {userName}}, nil, &resp)
+	if err != nil {
+		return "", err
+	}
+	if resp.ID == "" {
+		return "", fmt.Errorf("account %s not found", userName)
+	}
+
+	c.mu.Lock()
+	c.accountIDs[userName] = resp.ID
+	c.mu.Unlock()
+	return resp.ID, nil
+}
+
+type requestOption func(*http.Request)
+
+// postIdempotencyKey is empty for a Tweet without UID, which is then posted
+// without one.
+func postIdempotencyKey(tweet *tweettypes.Tweet) string {
+	if tweet.Spec.UID == "" {
+		return ""
+	}
+	hash := sha256.New()
+	hash.Write([]byte(tweet.Spec.Text))
+	if tweet.Spec.InReplyTo != "" {
+		hash.Write([]byte{0})
+		hash.Write([]byte(tweet.Spec.InReplyTo))
+	}
+	return tweet.Spec.UID + "-" + hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+func idempotencyKey(key string) requestOption {
+	return func(req *http.Request) {
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+	}
+}
+
+// do sends a request and decodes the JSON response into out. A 429 becomes
+// a twitterclient.RateLimitError, so the reconciler defers the work like it
+// does for Twitter.
+func (c *MastodonClient) do(ctx context.Context, endpoint, method, path string, query, form url.Values, out interface{}, opts ...requestOption) error {
+	u := c.instanceURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	for _, opt := range opts {
+		opt(req)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &APIError{}
+		if err := json.NewDecoder(resp.Body).Decode(apiErr); err != nil || apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		apiErr.StatusCode = resp.StatusCode
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return &twitterclient.RateLimitError{Endpoint: endpoint, Reset: c.rateLimitReset(resp), Err: apiErr}
+		}
+		return apiErr
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// rateLimitReset reads the X-RateLimit-Reset header, an ISO 8601 timestamp.
+func (c *MastodonClient) rateLimitReset(resp *http.Response) time.Time {
+	reset, err := time.Parse(time.RFC3339Nano, resp.Header.Get("X-RateLimit-Reset"))
+	if err != nil {
+		return c.now().Add(rateLimitWindow)
+	}
+	return reset
+}
+
+func (s status) toTweet() (tweettypes.Tweet, error) {
+	id, err := parseID(s.ID)
+	if err != nil {
+		return tweettypes.Tweet{}, err
+	}
+	return tweettypes.Tweet{
+		Spec: tweettypes.TweetSpec{
+			Text: contentToText(s.Content),
+		},
+		Status: tweettypes.TweetStatus{
+			ID:       id,
+			URL:      s.URL,
+			Likes:    s.FavouritesCount,
+			Retweets: s.ReblogsCount,
+			Replies:  s.RepliesCount,
+		},
+	}, nil
+}
+
+// parseID reads a status ID. Mastodon IDs are strings, but the common
+// server implementations all use numeric ones.
+func parseID(id string) (int64, error) {
+	i, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid status ID %q", id)
+	}
+	return i, nil
+}
@@ -0,0 +1,207 @@
+package mastodonclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jonatanblue/tweet-operator/pkg/libs/twitterclient"
+	"github.com/jonatanblue/tweet-operator/pkg/testing/fakemastodon"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClient(server *fakemastodon.Server, timelineMaxPages int) *MastodonClient {
+	return NewMastodonClient(http.DefaultClient, server.URL+"/", "token", timelineMaxPages)
+}
+
+func statusID(t *testing.T, id string) int64 {
+	i, err := strconv.ParseInt(id, 10, 64)
+	assert.NoError(t, err)
+	return i
+}
+
+func Test_Lifecycle(t *testing.T) {
+	server := fakemastodon.New("bob", "token")
+	defer server.Close()
+	existing := server.AddStatus("Existing status https://example.com/a/long/path")
+	server.SetCounts(existing, 1, 2, 3)
+	client := newTestClient(server, 1)
+
+	assert.NoError(t, client.VerifyCredentials(context.TODO()))
+
+	id, err := client.PostTweet(context.TODO(), &tweettypes.Tweet{Spec: tweettypes.TweetSpec{UID: "uid-1", Text: "Hello\nWorld\n\nBye"}})
+	assert.NoError(t, err)
+
+	tweets, err := client.GetTweetsForUser(context.TODO(), "bob")
+	assert.NoError(t, err)
+	assert.Equal(t, tweettypes.Tweets{
+		{
+			Spec:   tweettypes.TweetSpec{Text: "Hello\nWorld\n\nBye"},
+			Status: tweettypes.TweetStatus{ID: id, URL: fmt.Sprintf("https://mastodon.example/@bob/%d", id)},
+		},
+		{
+			Spec:   tweettypes.TweetSpec{Text: "Existing status https://example.com/a/long/path"},
+			Status: tweettypes.TweetStatus{ID: statusID(t, existing), URL: "https://mastodon.example/@bob/" + existing, Likes: 1, Retweets: 2, Replies: 3},
+		},
+	}, tweets)
+
+	// A retried post with the same UID does not post twice
+	retried, err := client.PostTweet(context.TODO(), &tweettypes.Tweet{Spec: tweettypes.TweetSpec{UID: "uid-1", Text: "Hello\nWorld\n\nBye"}})
+	assert.NoError(t, err)
+	assert.Equal(t, id, retried)
+	assert.Len(t, server.Statuses(), 2)
+
+	// Once deleted, an edited text is posted anew
+	err = client.DeleteTweet(context.TODO(), &tweettypes.Tweet{Status: tweettypes.TweetStatus{ID: id}})
+	assert.NoError(t, err)
+	assert.Len(t, server.Statuses(), 1)
+	edited, err := client.PostTweet(context.TODO(), &tweettypes.Tweet{Spec: tweettypes.TweetSpec{UID: "uid-1", Text: "Hello again"}})
+	assert.NoError(t, err)
+	assert.NotEqual(t, id, edited)
+	assert.Equal(t, "Hello again", server.Statuses()[0].Text)
+	err = client.DeleteTweet(context.TODO(), &tweettypes.Tweet{Status: tweettypes.TweetStatus{ID: edited}})
+	assert.NoError(t, err)
+
+	err = client.DeleteTweet(context.TODO(), &tweettypes.Tweet{Status: tweettypes.TweetStatus{ID: id}})
+	assert.True(t, IsNotFound(err), "not found: %v", err)
+}
+
+func Test_GetTweetsForUser(t *testing.T) {
+	server := fakemastodon.New("bob", "token")
+	defer server.Close()
+	oldest := server.AddStatus("Oldest")
+	for i := 0; i < statusesPageSize*2; i++ {
+		server.AddStatus("Status " + strconv.Itoa(i))
+	}
+
+	tests := map[string]struct {
+		maxPages   int
+		trackedIDs []int64
+		count      int
+		requests   []string
+	}{
+		"one page": {
+			maxPages: 1,
+			count:    statusesPageSize,
+			requests: []string{fakemastodon.EndpointAccountsLookup, fakemastodon.EndpointAccountStatuses},
+		},
+		"tracked status beyond the last page": {
+			maxPages:   1,
+			trackedIDs: []int64{statusID(t, oldest)},
+			count:      statusesPageSize + 1,
+			requests:   []string{fakemastodon.EndpointAccountsLookup, fakemastodon.EndpointAccountStatuses, fakemastodon.EndpointStatusesGet},
+		},
+		"tracked status within max pages": {
+			maxPages:   5,
+			trackedIDs: []int64{statusID(t, oldest)},
+			count:      statusesPageSize*2 + 1,
+			requests: []string{
+				fakemastodon.EndpointAccountsLookup,
+				fakemastodon.EndpointAccountStatuses,
+				fakemastodon.EndpointAccountStatuses,
+				fakemastodon.EndpointAccountStatuses,
+			},
+		},
+		"deleted tracked status": {
+			maxPages:   1,
+			trackedIDs: []int64{1},
+			count:      statusesPageSize,
+			requests:   []string{fakemastodon.EndpointAccountsLookup, fakemastodon.EndpointAccountStatuses, fakemastodon.EndpointStatusesGet},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			before := len(server.Requests())
+			tweets, err := newTestClient(server, test.maxPages).GetTweetsForUser(context.TODO(), "bob", test.trackedIDs...)
+			assert.NoError(t, err)
+			assert.Len(t, tweets, test.count)
+			assert.Equal(t, test.requests, server.Requests()[before:])
+		})
+	}
+}
+
+func Test_Errors(t *testing.T) {
+	server := fakemastodon.New("bob", "token")
+	defer server.Close()
+
+	err := NewMastodonClient(http.DefaultClient, server.URL, "wrong", 1).VerifyCredentials(context.TODO())
+	assert.True(t, IsUnauthorized(err), "unauthorized: %v", err)
+
+	_, err = newTestClient(server, 1).GetTweetsForUser(context.TODO(), "nobody")
+	assert.True(t, IsNotFound(err), "not found: %v", err)
+
+	// The reconciler knows these by the kinds of Twitter errors
+	err = newTestClient(server, 1).DeleteTweet(context.TODO(), &tweettypes.Tweet{Status: tweettypes.TweetStatus{ID: 1}})
+	assert.True(t, twitterclient.IsNotFound(err), "not found: %v", err)
+	server.InjectFault(fakemastodon.EndpointStatusesCreate, fakemastodon.Fault{StatusCode: http.StatusServiceUnavailable})
+	_, err = newTestClient(server, 1).PostTweet(context.TODO(), &tweettypes.Tweet{Spec: tweettypes.TweetSpec{Text: "Hello World"}})
+	assert.True(t, twitterclient.IsTransient(err), "transient: %v", err)
+	server.InjectFault(fakemastodon.EndpointStatusesCreate, fakemastodon.Fault{StatusCode: http.StatusForbidden, Message: "Your login is currently disabled"})
+	_, err = newTestClient(server, 1).PostTweet(context.TODO(), &tweettypes.Tweet{Spec: tweettypes.TweetSpec{Text: "Hello World"}})
+	assert.True(t, twitterclient.IsSuspended(err), "suspended: %v", err)
+
+	server.InjectFault(fakemastodon.EndpointStatusesCreate, fakemastodon.Fault{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Hour})
+	_, err = newTestClient(server, 1).PostTweet(context.TODO(), &tweettypes.Tweet{Spec: tweettypes.TweetSpec{Text: "Hello World"}})
+	var rateLimitErr *twitterclient.RateLimitError
+	assert.ErrorAs(t, err, &rateLimitErr)
+	assert.Equal(t, fakemastodon.EndpointStatusesCreate, rateLimitErr.Endpoint)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), rateLimitErr.Reset, time.Minute)
+	assert.Empty(t, server.Statuses())
+}
+
+func Test_GetReplies(t *testing.T) {
+	server := fakemastodon.New("bob", "token")
+	defer server.Close()
+	id := server.AddStatus("Hello World")
+	first := server.AddReply(id, "alice@mastodon.example", "@bob Hi")
+	server.AddReply(server.AddStatus("Other"), "carol", "Elsewhere")
+	nested := server.AddReply(first, "bob", "@alice Hi back")
+	last := server.AddReply(id, "carol", "@bob Hello")
+	client := newTestClient(server, 1)
+
+	replies, err := client.GetReplies(context.TODO(), &tweettypes.Tweet{Status: tweettypes.TweetStatus{ID: statusID(t, id)}}, 2)
+	assert.NoError(t, err)
+	assert.Len(t, replies, 2)
+	assert.Equal(t, last, replies[0].ID)
+	assert.Equal(t, "@carol", replies[0].Author)
+	assert.Equal(t, "@bob Hello", replies[0].Text)
+	assert.Equal(t, nested, replies[1].ID)
+
+	_, err = client.GetReplies(context.TODO(), &tweettypes.Tweet{Status: tweettypes.TweetStatus{ID: 1}}, 2)
+	assert.True(t, IsNotFound(err))
+}
+
+func Test_GetMentions(t *testing.T) {
+	server := fakemastodon.New("bob", "token")
+	defer server.Close()
+	id := server.AddStatus("Hello World")
+	first := server.AddMention("alice@mastodon.example", "@bob Hi")
+	server.AddReply(id, "bob", "Replying to myself")
+	last := server.AddReply(id, "carol", "@bob Hello")
+	client := newTestClient(server, 1)
+
+	mentions, err := client.GetMentions(context.TODO(), "bob", "")
+	assert.NoError(t, err)
+	assert.Len(t, mentions, 2)
+	assert.Equal(t, last, mentions[0].ID)
+	assert.Equal(t, "@carol", mentions[0].Author)
+	assert.Equal(t, "@bob Hello", mentions[0].Text)
+	assert.Equal(t, first, mentions[1].ID)
+	assert.Equal(t, first, mentions[1].PostID)
+	assert.Equal(t, "https://mastodon.example/@alice@mastodon.example/"+first, mentions[1].URL)
+	assert.False(t, mentions[1].Time.IsZero())
+
+	mentions, err = client.GetMentions(context.TODO(), "bob", last)
+	assert.NoError(t, err)
+	assert.Empty(t, mentions)
+	assert.Contains(t, server.Requests(), fakemastodon.EndpointNotifications)
+
+	_, err = client.PostTweet(context.TODO(), &tweettypes.Tweet{Spec: tweettypes.TweetSpec{UID: "1234-" + first, Text: "@alice@mastodon.example Hello", InReplyTo: first}})
+	assert.NoError(t, err)
+	assert.Equal(t, first, server.Statuses()[0].InReplyToID)
+}
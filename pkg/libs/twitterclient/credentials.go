@@ -0,0 +1,174 @@
+package twitterclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/httpclient"
+	"github.com/pkg/errors"
+)
+
+// DefaultCredentialsReloadInterval is how often a credentials directory is
+// checked for changes.
+const DefaultCredentialsReloadInterval = 30 * time.Second
+
+// Names of the credentials, both as environment variables and as files in a
+// mounted Secret
+const (
+	KeyConsumerKey       = "CONSUMER_KEY"
+	KeyConsumerSecret    = "CONSUMER_SECRET"
+	KeyAccessToken       = "ACCESS_TOKEN"
+	KeyAccessTokenSecret = "ACCESS_TOKEN_SECRET"
+)
+
+// CredentialsFromEnv reads the credentials from environment variables.
+func CredentialsFromEnv() (*Credentials, error) {
+	return newCredentials(os.Getenv)
+}
+
+// LoadCredentials reads the credentials from a directory with one file per
+// key, as Kubernetes mounts a Secret.
+func LoadCredentials(dir string) (*Credentials, error) {
+	var readErr error
+	creds, err := newCredentials(func(key string) string {
+		b, err := os.ReadFile(filepath.Join(dir, key))
+		if err != nil {
+			if !os.IsNotExist(err) && readErr == nil {
+				readErr = err
+			}
+			return ""
+		}
+		return string(b)
+	})
+	if readErr != nil {
+		return nil, errors.Wrapf(readErr, "failed to read credentials from %s", dir)
+	}
+	return creds, err
+}
+
+func newCredentials(lookup func(key string) string) (*Credentials, error) {
+	creds := &Credentials{
+		ConsumerKey:       strings.TrimSpace(lookup(KeyConsumerKey)),
+		ConsumerSecret:    strings.TrimSpace(lookup(KeyConsumerSecret)),
+		AccessToken:       strings.TrimSpace(lookup(KeyAccessToken)),
+		AccessTokenSecret: strings.TrimSpace(lookup(KeyAccessTokenSecret)),
+	}
+	missing := []string{}
+	for key, value := range map[string]string{
+		KeyConsumerKey:       creds.ConsumerKey,
+		KeyConsumerSecret:    creds.ConsumerSecret,
+		KeyAccessToken:       creds.AccessToken,
+		KeyAccessTokenSecret: creds.AccessTokenSecret,
+	} {
+		if value == "" {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("missing credentials: %s", strings.Join(missing, ", "))
+	}
+	return creds, nil
+}
+
+// SwappableTransport sends requests through a transport that can be replaced
+// while requests are in flight. Requests already sent finish on the old one.
+type SwappableTransport struct {
+	current atomic.Value
+}
+
+func NewSwappableTransport(transport http.RoundTripper) *SwappableTransport {
+	t := &SwappableTransport{}
+	t.Swap(transport)
+	return t
+}
+
+func (t *SwappableTransport) Swap(transport http.RoundTripper) {
+	t.current.Store(&transport)
+}
+
+func (t *SwappableTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return (*t.current.Load().(*http.RoundTripper)).RoundTrip(req)
+}
+
+// CredentialsReloader watches a credentials directory and swaps the
+// transport over to new credentials once they are verified. Credentials
+// that fail verification are ignored, so a half-written rotation keeps the
+// old ones working.
+type CredentialsReloader struct {
+	dir       string
+	transport *SwappableTransport
+	verify    func(*http.Client) error
+	current   Credentials
+	log       logr.Logger
+}
+
+// NewCredentialsReloader loads and verifies the credentials in dir, and
+// returns a reloader along with a client that always uses the latest
+// verified credentials.
+func NewCredentialsReloader(
+	dir string,
+	verify func(*http.Client) error,
+	log logr.Logger,
+) (*CredentialsReloader, *http.Client, error) {
+	creds, err := LoadCredentials(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	client := NewOAuth1HTTPClient(creds)
+	if err := verify(client); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to verify credentials")
+	}
+	reloader := &CredentialsReloader{
+		dir:       dir,
+		transport: NewSwappableTransport(client.Transport),
+		verify:    verify,
+		current:   *creds,
+		log:       log,
+	}
+	return reloader, &http.Client{Transport: reloader.transport, Timeout: httpclient.DefaultTimeout}, nil
+}
+
+// Reload swaps in the credentials from the directory if they changed and
+// pass verification.
+func (r *CredentialsReloader) Reload() (swapped bool, err error) {
+	creds, err := LoadCredentials(r.dir)
+	if err != nil {
+		return false, err
+	}
+	if *creds == r.current {
+		return false, nil
+	}
+	client := NewOAuth1HTTPClient(creds)
+	if err := r.verify(client); err != nil {
+		return false, errors.Wrap(err, "failed to verify new credentials")
+	}
+	r.transport.Swap(client.Transport)
+	r.current = *creds
+	r.log.Info("Reloaded credentials", "credentials", creds)
+	return true, nil
+}
+
+// Run reloads the credentials every interval until ctx is done.
+func (r *CredentialsReloader) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.Reload(); err != nil {
+				r.log.Error(err, "Failed to reload credentials, keeping the current ones")
+			}
+		}
+	}
+}
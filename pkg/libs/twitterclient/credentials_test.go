@@ -0,0 +1,168 @@
+package twitterclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeCredentials(t *testing.T, dir string, files map[string]string) {
+	for key, value := range files {
+		err := os.WriteFile(filepath.Join(dir, key), []byte(value), 0600)
+		assert.NoError(t, err)
+	}
+}
+
+func Test_LoadCredentials(t *testing.T) {
+	tests := map[string]struct {
+		files map[string]string
+		want  *Credentials
+		err   string
+	}{
+		"all keys": {
+			files: map[string]string{
+				KeyConsumerKey:       "key\n",
+				KeyConsumerSecret:    "secret",
+				KeyAccessToken:       "token",
+				KeyAccessTokenSecret: "token-secret",
+			},
+			want: &Credentials{
+				ConsumerKey:       "key",
+				ConsumerSecret:    "secret",
+				AccessToken:       "token",
+				AccessTokenSecret: "token-secret",
+			},
+		},
+		"missing keys": {
+			files: map[string]string{
+				KeyConsumerKey: "key",
+				KeyAccessToken: " ",
+			},
+			err: "missing credentials: ACCESS_TOKEN, ACCESS_TOKEN_SECRET, CONSUMER_SECRET",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeCredentials(t, dir, test.files)
+			creds, err := LoadCredentials(dir)
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, creds)
+		})
+	}
+}
+
+var consumerKeyPattern = regexp.MustCompile(`oauth_consumer_key="([^"]*)"`)
+
+func Test_CredentialsReloader(t *testing.T) {
+	var consumerKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		match := consumerKeyPattern.FindStringSubmatch(r.Header.Get("Authorization"))
+		consumerKey = match[1]
+		if consumerKey == "revoked" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+	verify := func(client *http.Client) error {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return errors.New(resp.Status)
+		}
+		return nil
+	}
+	files := func(consumerKey string) map[string]string {
+		return map[string]string{
+			KeyConsumerKey:       consumerKey,
+			KeyConsumerSecret:    "secret",
+			KeyAccessToken:       "token",
+			KeyAccessTokenSecret: "token-secret",
+		}
+	}
+
+	dir := t.TempDir()
+	writeCredentials(t, dir, files("first"))
+	reloader, client, err := NewCredentialsReloader(dir, verify, logr.Discard())
+	assert.NoError(t, err)
+
+	steps := []struct {
+		consumerKey string
+		swapped     bool
+		err         string
+		used        string
+	}{
+		{consumerKey: "first", swapped: false, used: "first"},
+		{consumerKey: "second", swapped: true, used: "second"},
+		{consumerKey: "revoked", swapped: false, err: "failed to verify new credentials: 401 Unauthorized", used: "second"},
+		{consumerKey: "third", swapped: true, used: "third"},
+	}
+	for _, step := range steps {
+		writeCredentials(t, dir, files(step.consumerKey))
+		swapped, err := reloader.Reload()
+		if step.err != "" {
+			assert.EqualError(t, err, step.err)
+		} else {
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, step.swapped, swapped, step.consumerKey)
+
+		resp, err := client.Get(server.URL)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, step.used, consumerKey)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func Test_SwappableTransportInFlight(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	old := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		close(started)
+		<-release
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	new := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusAccepted, Body: http.NoBody}, nil
+	})
+	transport := NewSwappableTransport(old)
+	client := &http.Client{Transport: transport}
+
+	done := make(chan int)
+	go func() {
+		resp, err := client.Get("http://example.com")
+		assert.NoError(t, err)
+		done <- resp.StatusCode
+	}()
+	<-started
+	transport.Swap(new)
+
+	resp, err := client.Get("http://example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	// The request in flight during the swap still completes
+	close(release)
+	assert.Equal(t, http.StatusOK, <-done)
+}
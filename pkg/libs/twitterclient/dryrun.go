@@ -0,0 +1,79 @@
+package twitterclient
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/logging"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+)
+
+type timelineReader interface {
+	GetTweetsForUser(ctx context.Context, userName string, trackedIDs ...int64) (tweettypes.Tweets, error)
+}
+
+// DryRunClient reads the real timeline but only logs posts and deletes. It
+// remembers what it would have done, so the timeline it returns reflects
+// the simulated changes and the reconciler can converge as it would for
+// real. Simulated tweets get negative IDs, which Twitter never hands out.
+type DryRunClient struct {
+	reader  timelineReader
+	log     logr.Logger
+	posted  tweettypes.Tweets
+	deleted map[int64]bool
+	lastID  int64
+}
+
+func NewDryRunClient(reader timelineReader, log logr.Logger) *DryRunClient {
+	return &DryRunClient{
+		reader:  reader,
+		log:     log,
+		deleted: map[int64]bool{},
+	}
+}
+
+func (c *DryRunClient) GetTweetsForUser(ctx context.Context, userName string, trackedIDs ...int64) (tweettypes.Tweets, error) {
+	tweets, err := c.reader.GetTweetsForUser(ctx, userName, trackedIDs...)
+	if err != nil {
+		return nil, err
+	}
+	result := tweettypes.Tweets{}
+	// Newest first, like the real timeline
+	for i := len(c.posted) - 1; i >= 0; i-- {
+		result = append(result, c.posted[i])
+	}
+	for _, tweet := range tweets {
+		if !c.deleted[tweet.Status.ID] {
+			result = append(result, tweet)
+		}
+	}
+	return result, nil
+}
+
+func (c *DryRunClient) PostTweet(ctx context.Context, tweet *tweettypes.Tweet) (int64, error) {
+	c.lastID--
+	log := logging.WithTweet(c.log, tweet)
+	log.Info("Dry run: would post tweet", "simulatedID", c.lastID, "text", logging.Text(log, tweet.Spec.Text))
+	c.posted = append(c.posted, tweettypes.Tweet{
+		Spec: tweettypes.TweetSpec{
+			Text: tweet.Spec.Text,
+		},
+		Status: tweettypes.TweetStatus{
+			ID: c.lastID,
+		},
+	})
+	return c.lastID, nil
+}
+
+func (c *DryRunClient) DeleteTweet(ctx context.Context, tweet *tweettypes.Tweet) error {
+	log := logging.WithTweet(c.log, tweet)
+	log.Info("Dry run: would delete tweet", "text", logging.Text(log, tweet.Spec.Text))
+	for i, posted := range c.posted {
+		if posted.Status.ID == tweet.Status.ID {
+			c.posted = append(c.posted[:i], c.posted[i+1:]...)
+			return nil
+		}
+	}
+	c.deleted[tweet.Status.ID] = true
+	return nil
+}
@@ -0,0 +1,88 @@
+package twitterclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dghubble/go-twitter/twitter"
+	"github.com/go-logr/logr"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DryRunClient(t *testing.T) {
+	// No status client: anything that tries to write to Twitter panics
+	client := NewDryRunClient(
+		NewTwitterClient(
+			nil,
+			newTimelineClientMock(
+				"UserTimeline",
+				[]interface{}{
+					&twitter.UserTimelineParams{
+						ScreenName: "bob",
+						Count:      200,
+					},
+				},
+				[]twitter.Tweet{
+					{ID: 2, Text: "Unmanaged"},
+					{ID: 1, Text: "Hello World"},
+				},
+				nil,
+			),
+			DefaultTimelineMaxPages,
+			DefaultRateLimitMaxWait,
+		),
+		logr.Discard(),
+	)
+
+	id, err := client.PostTweet(context.TODO(), &tweettypes.Tweet{
+		Spec: tweettypes.TweetSpec{
+			Name: "good-morning",
+			Text: "Good morning",
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-1), id)
+
+	err = client.DeleteTweet(context.TODO(), &tweettypes.Tweet{
+		Spec:   tweettypes.TweetSpec{Text: "Unmanaged"},
+		Status: tweettypes.TweetStatus{ID: 2},
+	})
+	assert.NoError(t, err)
+
+	tweets, err := client.GetTweetsForUser(context.TODO(), "bob")
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		tweettypes.Tweets{
+			{
+				Spec:   tweettypes.TweetSpec{Text: "Good morning"},
+				Status: tweettypes.TweetStatus{ID: -1},
+			},
+			{
+				Spec:   tweettypes.TweetSpec{Text: "Hello World"},
+				Status: tweettypes.TweetStatus{ID: 1, URL: "https://twitter.com/bob/status/1"},
+			},
+		},
+		tweets,
+	)
+
+	err = client.DeleteTweet(context.TODO(), &tweettypes.Tweet{
+		Spec:   tweettypes.TweetSpec{Text: "Good morning"},
+		Status: tweettypes.TweetStatus{ID: -1},
+	})
+	assert.NoError(t, err)
+
+	tweets, err = client.GetTweetsForUser(context.TODO(), "bob")
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		tweettypes.Tweets{
+			{
+				Spec:   tweettypes.TweetSpec{Text: "Hello World"},
+				Status: tweettypes.TweetStatus{ID: 1, URL: "https://twitter.com/bob/status/1"},
+			},
+		},
+		tweets,
+	)
+}
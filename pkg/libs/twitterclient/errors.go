@@ -0,0 +1,206 @@
+package twitterclient
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/dghubble/go-twitter/twitter"
+)
+
+// ErrorKind classifies an error from the Twitter API by what can be done
+// about it.
+type ErrorKind string
+
+const (
+	// KindDuplicate is a post with the same text as a recent one
+	KindDuplicate = ErrorKind("Duplicate")
+	// KindNotFound is a tweet or user that does not exist, or no longer
+	KindNotFound = ErrorKind("NotFound")
+	// KindRateLimited is a call over a rate limit, which succeeds later
+	KindRateLimited = ErrorKind("RateLimited")
+	// KindUnauthorized is a call with credentials Twitter does not accept
+	KindUnauthorized = ErrorKind("Unauthorized")
+	// KindSuspended is a call on behalf of a suspended or locked account
+	KindSuspended = ErrorKind("Suspended")
+	// KindTransient is a failure on Twitter's side or on the way there,
+	// which may succeed when tried again
+	KindTransient = ErrorKind("Transient")
+)
+
+// Twitter API error codes, see
+// https://developer.twitter.com/en/support/twitter-api/error-troubleshooting
+const (
+	codeCouldNotAuthenticate  = 32
+	codePageNotFound          = 34
+	codeUserNotFound          = 50
+	codeUserSuspended         = 63
+	codeAccountSuspended      = 64
+	codeRateLimitExceeded     = 88
+	codeInvalidToken          = 89
+	codeOverCapacity          = 130
+	codeInternalError         = 131
+	codeNoStatusFound         = 144
+	codeStatusUpdateLimit     = 185
+	codeDuplicateStatus       = 187
+	codeBadAuthenticationData = 215
+	codeAccountLocked         = 326
+)
+
+var kindByCode = map[int]ErrorKind{
+	codeCouldNotAuthenticate:  KindUnauthorized,
+	codePageNotFound:          KindNotFound,
+	codeUserNotFound:          KindNotFound,
+	codeUserSuspended:         KindSuspended,
+	codeAccountSuspended:      KindSuspended,
+	codeRateLimitExceeded:     KindRateLimited,
+	codeInvalidToken:          KindUnauthorized,
+	codeOverCapacity:          KindTransient,
+	codeInternalError:         KindTransient,
+	codeNoStatusFound:         KindNotFound,
+	codeStatusUpdateLimit:     KindRateLimited,
+	codeDuplicateStatus:       KindDuplicate,
+	codeBadAuthenticationData: KindUnauthorized,
+	codeAccountLocked:         KindSuspended,
+}
+
+// Error is an error from the Twitter API with its kind. It reads like the
+// error it wraps.
+type Error struct {
+	Kind ErrorKind
+	// StatusCode is that of the response, zero when there was none
+	StatusCode int
+	Err        error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Permanent reports whether making the same call again is bound to fail
+// the same way.
+func (e *Error) Permanent() bool {
+	return e.Kind != KindRateLimited && e.Kind != KindTransient
+}
+
+// KindOf returns the kind of an error from the Twitter API, or "" when it
+// is of no known kind. Errors of the other backends' APIs tell their kind
+// with a Kind method.
+func KindOf(err error) ErrorKind {
+	if err == nil {
+		return ""
+	}
+	var twitterErr *Error
+	if errors.As(err, &twitterErr) {
+		return twitterErr.Kind
+	}
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return KindRateLimited
+	}
+	var kinded interface{ Kind() ErrorKind }
+	if errors.As(err, &kinded) {
+		return kinded.Kind()
+	}
+	return kindOf(0, err)
+}
+
+// KindOfStatus returns the kind of a failed response by its status code
+// alone, or "" when that says nothing about what can be done.
+func KindOfStatus(statusCode int) ErrorKind {
+	switch {
+	case statusCode == http.StatusUnauthorized:
+		return KindUnauthorized
+	case statusCode == http.StatusNotFound:
+		return KindNotFound
+	case statusCode == http.StatusTooManyRequests:
+		return KindRateLimited
+	case statusCode >= 500:
+		return KindTransient
+	}
+	return ""
+}
+
+func IsRateLimited(err error) bool {
+	return KindOf(err) == KindRateLimited
+}
+
+func IsDuplicate(err error) bool {
+	return KindOf(err) == KindDuplicate
+}
+
+func IsUnauthorized(err error) bool {
+	return KindOf(err) == KindUnauthorized
+}
+
+func IsNotFound(err error) bool {
+	return KindOf(err) == KindNotFound
+}
+
+func IsSuspended(err error) bool {
+	return KindOf(err) == KindSuspended
+}
+
+func IsTransient(err error) bool {
+	return KindOf(err) == KindTransient
+}
+
+// classify gives the error of a call its kind. go-twitter returns no error
+// for a failed response whose body it cannot make sense of, so one is made
+// up from the status code.
+func classify(resp *http.Response, err error) error {
+	statusCode := 0
+	if resp != nil {
+		statusCode = resp.StatusCode
+	}
+	if err == nil {
+		if statusCode < 400 {
+			return nil
+		}
+		err = fmt.Errorf("twitter: %d %s", statusCode, http.StatusText(statusCode))
+	}
+	kind := kindOf(statusCode, err)
+	if kind == "" {
+		return err
+	}
+	return &Error{Kind: kind, StatusCode: statusCode, Err: err}
+}
+
+func kindOf(statusCode int, err error) ErrorKind {
+	var apiErr twitter.APIError
+	if errors.As(err, &apiErr) {
+		for _, detail := range apiErr.Errors {
+			if kind, ok := kindByCode[detail.Code]; ok {
+				return kind
+			}
+		}
+	}
+	var v2Err *V2APIError
+	if errors.As(err, &v2Err) {
+		statusCode = v2Err.StatusCode
+		// v2 has no error codes, only a 403 with an explanation
+		if statusCode == http.StatusForbidden {
+			detail := strings.ToLower(v2Err.Detail)
+			switch {
+			case strings.Contains(detail, "duplicate content"):
+				return KindDuplicate
+			case strings.Contains(detail, "suspended") || strings.Contains(detail, "locked"):
+				return KindSuspended
+			}
+		}
+	}
+	if kind := KindOfStatus(statusCode); kind != "" {
+		return kind
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return KindTransient
+	}
+	return ""
+}
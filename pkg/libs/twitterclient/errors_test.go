@@ -0,0 +1,170 @@
+package twitterclient
+
+import (
+	"net/http"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/dghubble/go-twitter/twitter"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// otherBackendError stands in for the API errors of the other backends,
+// which tell their kind themselves.
+type otherBackendError struct {
+	kind ErrorKind
+}
+
+func (e *otherBackendError) Error() string {
+	return "other: " + string(e.kind)
+}
+
+func (e *otherBackendError) Kind() ErrorKind {
+	return e.kind
+}
+
+func Test_errorClassification(t *testing.T) {
+	apiError := func(code int) error {
+		return twitter.APIError{
+			Errors: []twitter.ErrorDetail{{Code: code, Message: "some message"}},
+		}
+	}
+	tests := map[string]struct {
+		err          error
+		kind         ErrorKind
+		rateLimited  bool
+		duplicate    bool
+		unauthorized bool
+	}{
+		"rate limited": {
+			err:         apiError(88),
+			kind:        KindRateLimited,
+			rateLimited: true,
+		},
+		"rate limit error": {
+			err:         &RateLimitError{Endpoint: endpointStatusesUpdate, Reset: time.Now()},
+			kind:        KindRateLimited,
+			rateLimited: true,
+		},
+		"duplicate status": {
+			err:       apiError(187),
+			kind:      KindDuplicate,
+			duplicate: true,
+		},
+		"invalid token": {
+			err:          apiError(89),
+			kind:         KindUnauthorized,
+			unauthorized: true,
+		},
+		"could not authenticate wrapped": {
+			err:          errors.Wrap(apiError(32), "failed to post tweet"),
+			kind:         KindUnauthorized,
+			unauthorized: true,
+		},
+		"no status found": {
+			err:  apiError(144),
+			kind: KindNotFound,
+		},
+		"account suspended": {
+			err:  apiError(64),
+			kind: KindSuspended,
+		},
+		"over capacity": {
+			err:  apiError(130),
+			kind: KindTransient,
+		},
+		"v2 duplicate": {
+			err:       &V2APIError{StatusCode: 403, Title: "Forbidden", Detail: "You are not allowed to create a Tweet with duplicate content."},
+			kind:      KindDuplicate,
+			duplicate: true,
+		},
+		"v2 suspended": {
+			err:  &V2APIError{StatusCode: 403, Title: "Forbidden", Detail: "Your account is suspended and is not permitted to access this feature."},
+			kind: KindSuspended,
+		},
+		"v2 not found": {
+			err:  &V2APIError{StatusCode: 404, Title: "Not Found Error"},
+			kind: KindNotFound,
+		},
+		"v2 service unavailable": {
+			err:  &V2APIError{StatusCode: 503, Title: "Service Unavailable"},
+			kind: KindTransient,
+		},
+		"connection reset": {
+			err:  &url.Error{Op: "Post", URL: "https://api.twitter.com/1.1/statuses/update.json", Err: syscall.ECONNRESET},
+			kind: KindTransient,
+		},
+		"kind of another backend": {
+			err:          errors.Wrap(&otherBackendError{kind: KindUnauthorized}, "failed to post tweet"),
+			kind:         KindUnauthorized,
+			unauthorized: true,
+		},
+		"other api error": {
+			err: apiError(170),
+		},
+		"not an api error": {
+			err: errors.New("connection reset"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.kind, KindOf(test.err))
+			assert.Equal(t, test.rateLimited, IsRateLimited(test.err))
+			assert.Equal(t, test.duplicate, IsDuplicate(test.err))
+			assert.Equal(t, test.unauthorized, IsUnauthorized(test.err))
+		})
+	}
+}
+
+func Test_classify(t *testing.T) {
+	tests := map[string]struct {
+		statusCode int
+		err        error
+		want       error
+	}{
+		"success": {
+			statusCode: 200,
+		},
+		"api error": {
+			statusCode: 403,
+			err:        twitter.APIError{Errors: []twitter.ErrorDetail{{Code: 187, Message: "Status is a duplicate."}}},
+			want: &Error{
+				Kind:       KindDuplicate,
+				StatusCode: 403,
+				Err:        twitter.APIError{Errors: []twitter.ErrorDetail{{Code: 187, Message: "Status is a duplicate."}}},
+			},
+		},
+		"failed response without api error": {
+			statusCode: 503,
+			want:       &Error{Kind: KindTransient, StatusCode: 503, Err: errors.New("twitter: 503 Service Unavailable")},
+		},
+		"unknown error": {
+			statusCode: 200,
+			err:        errors.New("unexpected end of JSON input"),
+			want:       errors.New("unexpected end of JSON input"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := classify(&http.Response{StatusCode: test.statusCode}, test.err)
+			if test.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, test.want.Error())
+			assert.Equal(t, KindOf(test.want), KindOf(err))
+		})
+	}
+}
+
+func Test_ErrorPermanent(t *testing.T) {
+	assert.True(t, (&Error{Kind: KindDuplicate}).Permanent())
+	assert.True(t, (&Error{Kind: KindSuspended}).Permanent())
+	assert.False(t, (&Error{Kind: KindTransient}).Permanent())
+	assert.False(t, (&Error{Kind: KindRateLimited}).Permanent())
+}
@@ -0,0 +1,55 @@
+package twitterclient
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/dghubble/go-twitter/twitter"
+)
+
+// GoTwitterClient is the StatusClient and TimelineClient of the v1.1 API.
+// go-twitter takes no context, so each call gets a client whose requests
+// carry the context of the call.
+type GoTwitterClient struct {
+	httpClient *http.Client
+}
+
+func NewGoTwitterClient(httpClient *http.Client) *GoTwitterClient {
+	return &GoTwitterClient{httpClient: httpClient}
+}
+
+func (c *GoTwitterClient) Update(ctx context.Context, status string, params *twitter.StatusUpdateParams) (*twitter.Tweet, *http.Response, error) {
+	return c.client(ctx).Statuses.Update(status, params)
+}
+
+func (c *GoTwitterClient) Destroy(ctx context.Context, id int64, params *twitter.StatusDestroyParams) (*twitter.Tweet, *http.Response, error) {
+	return c.client(ctx).Statuses.Destroy(id, params)
+}
+
+func (c *GoTwitterClient) UserTimeline(ctx context.Context, params *twitter.UserTimelineParams) ([]twitter.Tweet, *http.Response, error) {
+	return c.client(ctx).Timelines.UserTimeline(params)
+}
+
+func (c *GoTwitterClient) MentionTimeline(ctx context.Context, params *twitter.MentionTimelineParams) ([]twitter.Tweet, *http.Response, error) {
+	return c.client(ctx).Timelines.MentionTimeline(params)
+}
+
+func (c *GoTwitterClient) client(ctx context.Context) *twitter.Client {
+	httpClient := *c.httpClient
+	next := httpClient.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	httpClient.Transport = &contextTransport{ctx: ctx, next: next}
+	return twitter.NewClient(&httpClient)
+}
+
+// contextTransport sends each request with its context.
+type contextTransport struct {
+	ctx  context.Context
+	next http.RoundTripper
+}
+
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.next.RoundTrip(req.WithContext(t.ctx))
+}
@@ -0,0 +1,67 @@
+package twitterclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dghubble/go-twitter/twitter"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GoTwitterClient(t *testing.T) {
+	tests := map[string]struct {
+		cancel bool
+		err    error
+	}{
+		"sent":              {},
+		"context cancelled": {cancel: true, err: context.Canceled},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`[{"id": 1, "text": "Hello"}]`))
+			}))
+			defer server.Close()
+			httpClient := server.Client()
+			httpClient.Transport = &redirectTransport{target: server.URL, next: httpClient.Transport}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if test.cancel {
+				cancel()
+			}
+			tweets, _, err := NewGoTwitterClient(httpClient).UserTimeline(ctx, &twitter.UserTimelineParams{ScreenName: "jonatanblue"})
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				assert.Equal(t, 0, requests)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, []twitter.Tweet{{ID: 1, Text: "Hello"}}, tweets)
+			assert.Equal(t, 1, requests)
+		})
+	}
+}
+
+// redirectTransport sends requests for the Twitter API to a test server
+type redirectTransport struct {
+	target string
+	next   http.RoundTripper
+}
+
+func (t *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	target, err := req.URL.Parse(t.target)
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.URL.Scheme = target.Scheme
+	req.URL.Host = target.Host
+	return t.next.RoundTrip(req)
+}
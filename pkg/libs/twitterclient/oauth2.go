@@ -0,0 +1,142 @@
+package twitterclient
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+// OAuth 2.0 endpoints for user context access
+const (
+	OAuth2AuthURL  = "https://twitter.com/i/oauth2/authorize"
+	OAuth2TokenURL = "https://api.twitter.com/2/oauth2/token"
+)
+
+// OAuth2Scopes are the scopes the operator needs. offline.access is what
+// makes Twitter hand out a refresh token.
+var OAuth2Scopes = []string{"tweet.read", "tweet.write", "users.read", "offline.access"}
+
+// NewOAuth2Config returns the config for an app's OAuth 2.0 client. The
+// client secret is only set for confidential clients.
+func NewOAuth2Config(clientID, clientSecret, redirectURL string) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       OAuth2Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   OAuth2AuthURL,
+			TokenURL:  OAuth2TokenURL,
+			AuthStyle: oauth2.AuthStyleInHeader,
+		},
+	}
+}
+
+// PKCE holds the verifier for one Authorization Code flow, as described in
+// RFC 7636.
+type PKCE struct {
+	Verifier string
+}
+
+func NewPKCE() (*PKCE, error) {
+	verifier, err := randomString(32)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate code verifier")
+	}
+	return &PKCE{Verifier: verifier}, nil
+}
+
+func (p *PKCE) Challenge() string {
+	sum := sha256.Sum256([]byte(p.Verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL returns the URL the user opens to authorize the app.
+func (p *PKCE) AuthCodeURL(config *oauth2.Config, state string) string {
+	return config.AuthCodeURL(
+		state,
+		oauth2.SetAuthURLParam("code_challenge", p.Challenge()),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	)
+}
+
+// Exchange trades the code from the redirect for a token.
+func (p *PKCE) Exchange(ctx context.Context, config *oauth2.Config, code string) (*oauth2.Token, error) {
+	return config.Exchange(ctx, code, oauth2.SetAuthURLParam("code_verifier", p.Verifier))
+}
+
+// NewOAuth2State returns a random value to tie the redirect to the request.
+func NewOAuth2State() (string, error) {
+	return randomString(16)
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// TokenStore persists OAuth 2.0 tokens between runs.
+type TokenStore interface {
+	LoadToken() (*oauth2.Token, error)
+	SaveToken(token *oauth2.Token) error
+}
+
+// persistingTokenSource saves every token it gets from the wrapped source
+// that differs from the last one. Twitter rotates the refresh token on every
+// refresh and the old one stops working, so a rotated token that is not
+// saved would lock the operator out after a restart.
+type persistingTokenSource struct {
+	mu     sync.Mutex
+	source oauth2.TokenSource
+	store  TokenStore
+	last   *oauth2.Token
+	log    logr.Logger
+}
+
+func (s *persistingTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token, err := s.source.Token()
+	if err != nil {
+		return nil, err
+	}
+	if s.last != nil && token.AccessToken == s.last.AccessToken && token.RefreshToken == s.last.RefreshToken {
+		return token, nil
+	}
+	s.log.Info("Refreshed OAuth 2.0 access token", "expiry", token.Expiry)
+	if err := s.store.SaveToken(token); err != nil {
+		return nil, errors.Wrap(err, "failed to save refreshed token")
+	}
+	s.last = token
+	return token, nil
+}
+
+// NewOAuth2HTTPClient returns a client that authorizes requests with the
+// token in the store, refreshing it when it expires and saving the rotated
+// token back to the store.
+func NewOAuth2HTTPClient(ctx context.Context, config *oauth2.Config, store TokenStore, log logr.Logger) (*http.Client, error) {
+	token, err := store.LoadToken()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load token")
+	}
+	if token.RefreshToken == "" {
+		return nil, errors.New("stored token has no refresh token, run tweet-operator login")
+	}
+	source := &persistingTokenSource{
+		source: config.TokenSource(ctx, token),
+		store:  store,
+		last:   token,
+		log:    log,
+	}
+	return oauth2.NewClient(ctx, oauth2.ReuseTokenSource(token, source)), nil
+}
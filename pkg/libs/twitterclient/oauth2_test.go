@@ -0,0 +1,151 @@
+package twitterclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+type memoryTokenStore struct {
+	token *oauth2.Token
+	saved []*oauth2.Token
+}
+
+func (s *memoryTokenStore) LoadToken() (*oauth2.Token, error) {
+	return s.token, nil
+}
+
+func (s *memoryTokenStore) SaveToken(token *oauth2.Token) error {
+	s.token = token
+	s.saved = append(s.saved, token)
+	return nil
+}
+
+// newFakeTokenServer hands out a new access and refresh token on every
+// refresh, like Twitter does, and records the forms it receives.
+func newFakeTokenServer(forms *[]url.Values) *httptest.Server {
+	count := 0
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		*forms = append(*forms, r.PostForm)
+		count++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "access-" + string(rune('0'+count)),
+			"refresh_token": "refresh-" + string(rune('0'+count)),
+			"token_type":    "bearer",
+			"expires_in":    7200,
+		})
+	}))
+}
+
+func Test_PKCE(t *testing.T) {
+	pkce := &PKCE{Verifier: "verifier-for-testing-0123456789-abcdefghijk"}
+	assert.Equal(t, "CbgJTzwi3NAzFUuEp_3CgFKN50Gg1DbOfklZjsM7yJg", pkce.Challenge())
+
+	config := NewOAuth2Config("client", "", "http://127.0.0.1:8080/callback")
+	u, err := url.Parse(pkce.AuthCodeURL(config, "state"))
+	assert.NoError(t, err)
+	assert.Equal(t, "twitter.com", u.Host)
+	query := u.Query()
+	assert.Equal(t, "client", query.Get("client_id"))
+	assert.Equal(t, "state", query.Get("state"))
+	assert.Equal(t, "tweet.read tweet.write users.read offline.access", query.Get("scope"))
+	assert.Equal(t, pkce.Challenge(), query.Get("code_challenge"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+
+	generated, err := NewPKCE()
+	assert.NoError(t, err)
+	assert.Len(t, generated.Verifier, 43)
+}
+
+func Test_PKCEExchange(t *testing.T) {
+	forms := []url.Values{}
+	server := newFakeTokenServer(&forms)
+	defer server.Close()
+	config := NewOAuth2Config("client", "", "http://127.0.0.1:8080/callback")
+	config.Endpoint.TokenURL = server.URL
+
+	pkce := &PKCE{Verifier: "verifier"}
+	token, err := pkce.Exchange(context.Background(), config, "code")
+	assert.NoError(t, err)
+	assert.Equal(t, "refresh-1", token.RefreshToken)
+	assert.Equal(t, "authorization_code", forms[0].Get("grant_type"))
+	assert.Equal(t, "code", forms[0].Get("code"))
+	assert.Equal(t, "verifier", forms[0].Get("code_verifier"))
+}
+
+func Test_OAuth2HTTPClient(t *testing.T) {
+	tests := map[string]struct {
+		token         *oauth2.Token
+		authorization string
+		saved         []string
+		err           string
+	}{
+		"valid token is used as is": {
+			token: &oauth2.Token{
+				AccessToken:  "access-0",
+				RefreshToken: "refresh-0",
+				Expiry:       time.Now().Add(time.Hour),
+			},
+			authorization: "Bearer access-0",
+			saved:         []string{},
+		},
+		"expired token is refreshed and saved": {
+			token: &oauth2.Token{
+				AccessToken:  "access-0",
+				RefreshToken: "refresh-0",
+				Expiry:       time.Now().Add(-time.Hour),
+			},
+			authorization: "Bearer access-1",
+			saved:         []string{"refresh-1"},
+		},
+		"token without refresh token": {
+			token: &oauth2.Token{AccessToken: "access-0"},
+			err:   "stored token has no refresh token, run tweet-operator login",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			forms := []url.Values{}
+			tokenServer := newFakeTokenServer(&forms)
+			defer tokenServer.Close()
+			var authorization string
+			api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				authorization = r.Header.Get("Authorization")
+			}))
+			defer api.Close()
+
+			config := NewOAuth2Config("client", "", "")
+			config.Endpoint.TokenURL = tokenServer.URL
+			store := &memoryTokenStore{token: test.token}
+			client, err := NewOAuth2HTTPClient(context.Background(), config, store, logr.Discard())
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+
+			for i := 0; i < 2; i++ {
+				resp, err := client.Get(api.URL)
+				assert.NoError(t, err)
+				resp.Body.Close()
+			}
+			assert.Equal(t, test.authorization, authorization)
+			saved := []string{}
+			for _, token := range store.saved {
+				saved = append(saved, token.RefreshToken)
+			}
+			assert.Equal(t, test.saved, saved)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package twitterclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultRateLimitMaxWait is how long a call may block waiting for its rate
+// limit window to reset. Calls that would have to wait longer fail with a
+// RateLimitError instead, so the caller can defer them.
+const DefaultRateLimitMaxWait = 30 * time.Second
+
+// rateLimitWindow is assumed when a 429 comes without a reset header
+const rateLimitWindow = 15 * time.Minute
+
+// Endpoints, as named in the x-rate-limit documentation
+const (
+	endpointStatusesUpdate           = "statuses/update"
+	endpointStatusesDestroy          = "statuses/destroy"
+	endpointStatusesUserTimeline     = "statuses/user_timeline"
+	endpointStatusesMentionsTimeline = "statuses/mentions_timeline"
+)
+
+type RateLimitError struct {
+	Endpoint string
+	Reset    time.Time
+	Err      error
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded for %s until %s", e.Endpoint, e.Reset.Format(time.RFC3339))
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+type rateLimit struct {
+	remaining int
+	reset     time.Time
+}
+
+// rateLimiter tracks the x-rate-limit-remaining and x-rate-limit-reset
+// headers per endpoint, so calls that are bound to be rejected are not made.
+type rateLimiter struct {
+	mu      sync.Mutex
+	limits  map[string]rateLimit
+	maxWait time.Duration
+	now     func() time.Time
+	sleep   func(context.Context, time.Duration) error
+}
+
+func newRateLimiter(maxWait time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limits:  map[string]rateLimit{},
+		maxWait: maxWait,
+		now:     time.Now,
+		sleep:   sleep,
+	}
+}
+
+// wait blocks until a call to the endpoint is allowed, or returns a
+// RateLimitError if that would take longer than maxWait. It gives up early
+// with the context's error once ctx is done.
+func (l *rateLimiter) wait(ctx context.Context, endpoint string) error {
+	l.mu.Lock()
+	limit, ok := l.limits[endpoint]
+	l.mu.Unlock()
+	if !ok || limit.remaining > 0 {
+		return nil
+	}
+	delay := limit.reset.Sub(l.now())
+	if delay <= 0 {
+		return nil
+	}
+	if delay > l.maxWait {
+		return &RateLimitError{Endpoint: endpoint, Reset: limit.reset}
+	}
+	return l.sleep(ctx, delay)
+}
+
+func (l *rateLimiter) update(endpoint string, resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	remaining, err := strconv.Atoi(resp.Header.Get("x-rate-limit-remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get("x-rate-limit-reset"), 10, 64)
+	if err != nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limits[endpoint] = rateLimit{
+		remaining: remaining,
+		reset:     time.Unix(reset, 0),
+	}
+}
+
+// do makes a call to the endpoint within its rate limit, and turns a 429
+// response into a RateLimitError. Other errors are classified as Errors.
+func (l *rateLimiter) do(ctx context.Context, endpoint string, call func() (*http.Response, error)) error {
+	if err := l.wait(ctx, endpoint); err != nil {
+		return err
+	}
+	resp, err := call()
+	l.update(endpoint, resp)
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		reset := l.now().Add(rateLimitWindow)
+		l.mu.Lock()
+		if limit, ok := l.limits[endpoint]; ok && limit.reset.After(l.now()) {
+			reset = limit.reset
+		}
+		l.mu.Unlock()
+		return &RateLimitError{Endpoint: endpoint, Reset: reset, Err: err}
+	}
+	return classify(resp, err)
+}
+
+// sleep waits for d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,123 @@
+package twitterclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_rateLimiter(t *testing.T) {
+	now := time.Unix(1656676800, 0)
+	response := func(status, remaining int, reset time.Time) *http.Response {
+		resp := &http.Response{StatusCode: status, Header: http.Header{}}
+		resp.Header.Set("x-rate-limit-remaining", strconv.Itoa(remaining))
+		resp.Header.Set("x-rate-limit-reset", strconv.FormatInt(reset.Unix(), 10))
+		return resp
+	}
+
+	tests := map[string]struct {
+		responses []*http.Response
+		calls     int
+		slept     time.Duration
+		err       error
+	}{
+		"no headers": {
+			responses: []*http.Response{{StatusCode: 200, Header: http.Header{}}, {StatusCode: 200, Header: http.Header{}}},
+			calls:     2,
+		},
+		"calls remaining": {
+			responses: []*http.Response{response(200, 1, now.Add(time.Minute)), response(200, 0, now.Add(time.Minute))},
+			calls:     2,
+		},
+		"blocks until a reset within max wait": {
+			responses: []*http.Response{response(200, 0, now.Add(10*time.Second)), response(200, 899, now.Add(15*time.Minute))},
+			calls:     2,
+			slept:     10 * time.Second,
+		},
+		"defers past max wait": {
+			responses: []*http.Response{response(200, 0, now.Add(time.Minute)), nil},
+			calls:     1,
+			err:       &RateLimitError{Endpoint: "statuses/user_timeline", Reset: now.Add(time.Minute)},
+		},
+		"429 with reset": {
+			responses: []*http.Response{response(429, 0, now.Add(5*time.Minute))},
+			calls:     1,
+			err: &RateLimitError{
+				Endpoint: "statuses/user_timeline",
+				Reset:    now.Add(5 * time.Minute),
+				Err:      errors.New("twitter: 88 Rate limit exceeded"),
+			},
+		},
+		"429 without reset": {
+			responses: []*http.Response{{StatusCode: 429, Header: http.Header{}}},
+			calls:     1,
+			err: &RateLimitError{
+				Endpoint: "statuses/user_timeline",
+				Reset:    now.Add(15 * time.Minute),
+				Err:      errors.New("twitter: 88 Rate limit exceeded"),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var slept time.Duration
+			limiter := newRateLimiter(30 * time.Second)
+			limiter.now = func() time.Time { return now }
+			limiter.sleep = func(ctx context.Context, d time.Duration) error {
+				slept += d
+				return nil
+			}
+
+			calls := 0
+			var err error
+			for _, resp := range test.responses {
+				resp := resp
+				err = limiter.do(context.TODO(), endpointStatusesUserTimeline, func() (*http.Response, error) {
+					calls++
+					if resp.StatusCode == http.StatusTooManyRequests {
+						return resp, errors.New("twitter: 88 Rate limit exceeded")
+					}
+					return resp, nil
+				})
+				if err != nil {
+					break
+				}
+			}
+			assert.Equal(t, test.calls, calls)
+			assert.Equal(t, test.slept, slept)
+			assert.Equal(t, test.err, err)
+			if test.err != nil {
+				assert.True(t, IsRateLimited(err))
+			}
+		})
+	}
+}
+
+func Test_sleep(t *testing.T) {
+	tests := map[string]struct {
+		cancel bool
+		err    error
+	}{
+		"slept":             {},
+		"context cancelled": {cancel: true, err: context.Canceled},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			delay := time.Millisecond
+			if test.cancel {
+				cancel()
+				delay = time.Hour
+			}
+			assert.Equal(t, test.err, sleep(ctx, delay))
+		})
+	}
+}
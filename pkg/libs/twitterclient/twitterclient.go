@@ -1,109 +1,234 @@
 package twitterclient
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/dghubble/go-twitter/twitter"
 	"github.com/dghubble/oauth1"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/httpclient"
 	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
 )
 
 type StatusClient interface {
-	Update(status string, params *twitter.StatusUpdateParams) (*twitter.Tweet, *http.Response, error)
-	Destroy(id int64, params *twitter.StatusDestroyParams) (*twitter.Tweet, *http.Response, error)
+	Update(ctx context.Context, status string, params *twitter.StatusUpdateParams) (*twitter.Tweet, *http.Response, error)
+	Destroy(ctx context.Context, id int64, params *twitter.StatusDestroyParams) (*twitter.Tweet, *http.Response, error)
 }
 
 type TimelineClient interface {
-	UserTimeline(params *twitter.UserTimelineParams) ([]twitter.Tweet, *http.Response, error)
+	UserTimeline(ctx context.Context, params *twitter.UserTimelineParams) ([]twitter.Tweet, *http.Response, error)
+	MentionTimeline(ctx context.Context, params *twitter.MentionTimelineParams) ([]twitter.Tweet, *http.Response, error)
 }
 
+// DefaultTimelineMaxPages walks back through the 3200 most recent tweets,
+// which is as far back as the user timeline API goes.
+const DefaultTimelineMaxPages = 16
+
+const timelinePageSize = 200
+
 type TwitterClient struct {
-	statusClient   StatusClient
-	timelineClient TimelineClient
+	statusClient     StatusClient
+	timelineClient   TimelineClient
+	timelineMaxPages int
+	limiter          *rateLimiter
 }
 
 func NewTwitterClient(
 	statusClient StatusClient,
 	timelineClient TimelineClient,
+	timelineMaxPages int,
+	rateLimitMaxWait time.Duration,
 ) *TwitterClient {
 	return &TwitterClient{
-		statusClient:   statusClient,
-		timelineClient: timelineClient,
+		statusClient:     statusClient,
+		timelineClient:   timelineClient,
+		timelineMaxPages: timelineMaxPages,
+		limiter:          newRateLimiter(rateLimitMaxWait),
 	}
 }
 
-func (c *TwitterClient) GetTweetsForUser(userName string) (tweettypes.Tweets, error) {
-	params := &twitter.UserTimelineParams{
-		ScreenName: userName,
-		Count:      200,
+// GetTweetsForUser returns the user's timeline, newest first. It pages back
+// until every tracked ID has been seen, the timeline runs out or
+// timelineMaxPages pages have been read, and never further back than the
+// oldest tracked ID.
+func (c *TwitterClient) GetTweetsForUser(ctx context.Context, userName string, trackedIDs ...int64) (tweettypes.Tweets, error) {
+	missing := map[int64]bool{}
+	var sinceID int64
+	for _, id := range trackedIDs {
+		if id <= 0 {
+			continue
+		}
+		missing[id] = true
+		// since_id is exclusive
+		if sinceID == 0 || id-1 < sinceID {
+			sinceID = id - 1
+		}
 	}
-	tweets, _, err := c.timelineClient.UserTimeline(params)
-	if err != nil {
-		return nil, err
+
+	result := tweettypes.Tweets{}
+	var maxID int64
+	for page := 0; page < c.timelineMaxPages; page++ {
+		params := &twitter.UserTimelineParams{
+			ScreenName: userName,
+			Count:      timelinePageSize,
+			SinceID:    sinceID,
+			MaxID:      maxID,
+		}
+		var tweets []twitter.Tweet
+		err := c.limiter.do(ctx, endpointStatusesUserTimeline, func() (resp *http.Response, err error) {
+			tweets, resp, err = c.timelineClient.UserTimeline(ctx, params)
+			return resp, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(tweets) == 0 {
+			break
+		}
+		for _, tweet := range tweets {
+			delete(missing, tweet.ID)
+			result = append(
+				result,
+				tweettypes.Tweet{
+					Spec: tweettypes.TweetSpec{
+						Text: tweet.Text,
+					},
+					Status: tweettypes.TweetStatus{
+						ID:       tweet.ID,
+						URL:      TweetURL(userName, tweet.ID),
+						Likes:    int64(tweet.FavoriteCount),
+						Retweets: int64(tweet.RetweetCount),
+						Replies:  int64(tweet.ReplyCount),
+						Quotes:   int64(tweet.QuoteCount),
+					},
+				},
+			)
+		}
+		if len(missing) == 0 {
+			break
+		}
+		// max_id is inclusive
+		maxID = tweets[len(tweets)-1].ID - 1
 	}
-	if len(tweets) == 0 {
+	return result, nil
+}
+
+// GetMentions returns the mentions of the authenticated user posted after
+// sinceID, newest first. Only the most recent page is read, so mentions
+// beyond it are skipped when many come in between polls.
+func (c *TwitterClient) GetMentions(ctx context.Context, userName, sinceID string) ([]tweettypes.Mention, error) {
+	params := &twitter.MentionTimelineParams{
+		Count: timelinePageSize,
+	}
+	if sinceID != "" {
+		id, err := strconv.ParseInt(sinceID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid since ID %q: %w", sinceID, err)
+		}
+		params.SinceID = id
+	}
+	var tweets []twitter.Tweet
+	err := c.limiter.do(ctx, endpointStatusesMentionsTimeline, func() (resp *http.Response, err error) {
+		tweets, resp, err = c.timelineClient.MentionTimeline(ctx, params)
+		return resp, err
+	})
+	if err != nil {
 		return nil, err
 	}
-	var result tweettypes.Tweets
+	mentions := []tweettypes.Mention{}
 	for _, tweet := range tweets {
-		result = append(
-			result,
-			tweettypes.Tweet{
-				Spec: tweettypes.TweetSpec{
-					Text: tweet.Text,
-				},
-				Status: tweettypes.TweetStatus{
-					ID:       tweet.ID,
-					Likes:    int64(tweet.FavoriteCount),
-					Retweets: int64(tweet.RetweetCount),
-					Replies:  int64(tweet.ReplyCount),
-				},
-			},
-		)
+		mention := tweettypes.Mention{
+			ID:   tweet.IDStr,
+			Text: tweet.Text,
+		}
+		if mention.ID == "" {
+			mention.ID = strconv.FormatInt(tweet.ID, 10)
+		}
+		mention.PostID = mention.ID
+		if tweet.User != nil {
+			mention.Author = "@" + tweet.User.ScreenName
+			mention.URL = TweetURL(tweet.User.ScreenName, tweet.ID)
+		}
+		if createdAt, err := tweet.CreatedAtTime(); err == nil {
+			mention.Time = createdAt.UTC()
+		}
+		mentions = append(mentions, mention)
 	}
-	return result, nil
+	return mentions, nil
 }
 
-func (c *TwitterClient) PostTweet(tweet *tweettypes.Tweet) error {
-	_, _, err := c.statusClient.Update(
-		tweet.Spec.Text,
-		&twitter.StatusUpdateParams{
-			Status: tweet.Spec.Text,
-		},
-	)
+func (c *TwitterClient) PostTweet(ctx context.Context, tweet *tweettypes.Tweet) (int64, error) {
+	params := &twitter.StatusUpdateParams{
+		Status: tweet.Spec.Text,
+	}
+	if tweet.Spec.InReplyTo != "" {
+		id, err := strconv.ParseInt(tweet.Spec.InReplyTo, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid in reply to ID %q: %w", tweet.Spec.InReplyTo, err)
+		}
+		// Only threaded if the text mentions the author of the tweet
+		params.InReplyToStatusID = id
+	}
+	var posted *twitter.Tweet
+	err := c.limiter.do(ctx, endpointStatusesUpdate, func() (resp *http.Response, err error) {
+		posted, resp, err = c.statusClient.Update(ctx, tweet.Spec.Text, params)
+		return resp, err
+	})
 	if err != nil {
-		return err
+		return 0, err
 	}
-	return nil
+	return posted.ID, nil
 }
 
-func (c *TwitterClient) DeleteTweet(tweet *tweettypes.Tweet) error {
-	_, _, err := c.statusClient.Destroy(
-		tweet.Status.ID,
-		&twitter.StatusDestroyParams{
-			ID: tweet.Status.ID,
-		},
-	)
+func (c *TwitterClient) DeleteTweet(ctx context.Context, tweet *tweettypes.Tweet) error {
+	err := c.limiter.do(ctx, endpointStatusesDestroy, func() (resp *http.Response, err error) {
+		_, resp, err = c.statusClient.Destroy(
+			ctx,
+			tweet.Status.ID,
+			&twitter.StatusDestroyParams{
+				ID: tweet.Status.ID,
+			},
+		)
+		return resp, err
+	})
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func NewTwitterAPIClient(creds *Credentials) (*twitter.Client, error) {
+// NewOAuth1HTTPClient returns an HTTP client that signs requests on behalf
+// of the user the access token belongs to. Requests time out after
+// httpclient.DefaultTimeout.
+func NewOAuth1HTTPClient(creds *Credentials) *http.Client {
 	config := oauth1.NewConfig(creds.ConsumerKey, creds.ConsumerSecret)
 	token := oauth1.NewToken(creds.AccessToken, creds.AccessTokenSecret)
-	httpClient := config.Client(oauth1.NoContext, token)
-	client := twitter.NewClient(httpClient)
+	ctx := context.WithValue(context.Background(), oauth1.HTTPClient, httpclient.New(0))
+	client := config.Client(ctx, token)
+	client.Timeout = httpclient.DefaultTimeout
+	return client
+}
+
+func NewTwitterAPIClient(creds *Credentials) (*twitter.Client, error) {
+	httpClient := NewOAuth1HTTPClient(creds)
+	if err := VerifyCredentials(httpClient); err != nil {
+		return nil, err
+	}
+	return twitter.NewClient(httpClient), nil
+}
+
+// VerifyCredentials checks that the client is authorized by the v1.1 API.
+func VerifyCredentials(httpClient *http.Client) error {
 	verifyParams := &twitter.AccountVerifyParams{
 		SkipStatus:   twitter.Bool(true),
 		IncludeEmail: twitter.Bool(false),
 	}
-	_, _, err := client.Accounts.VerifyCredentials(verifyParams)
-	if err != nil {
-		return nil, err
-	}
-	return client, nil
+	_, _, err := twitter.NewClient(httpClient).Accounts.VerifyCredentials(verifyParams)
+	return err
 }
 
 type Credentials struct {
@@ -112,3 +237,32 @@ type Credentials struct {
 	AccessToken       string
 	AccessTokenSecret string
 }
+
+// String keeps secrets out of log lines and error messages.
+func (c Credentials) String() string {
+	return fmt.Sprintf(
+		"{ConsumerKey:%s ConsumerSecret:%s AccessToken:%s AccessTokenSecret:%s}",
+		redact(c.ConsumerKey),
+		redact(c.ConsumerSecret),
+		redact(c.AccessToken),
+		redact(c.AccessTokenSecret),
+	)
+}
+
+// MarshalLog implements logr.Marshaler so structured loggers get the same
+// redacted view as fmt.
+func (c Credentials) MarshalLog() interface{} {
+	return c.String()
+}
+
+func redact(value string) string {
+	if value == "" {
+		return "<empty>"
+	}
+	return "<redacted>"
+}
+
+// TweetURL returns the address of a tweet on twitter.com.
+func TweetURL(userName string, id int64) string {
+	return "https://twitter.com/" + url.PathEscape(userName) + "/status/" + strconv.FormatInt(id, 10)
+}
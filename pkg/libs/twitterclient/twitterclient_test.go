@@ -1,8 +1,12 @@
 package twitterclient
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/dghubble/go-twitter/twitter"
 	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
@@ -12,11 +16,12 @@ import (
 
 func Test_GetTweetsForUser(t *testing.T) {
 	tests := map[string]struct {
-		client *TwitterClient
-		name   string
-		want   tweettypes.Tweets
-		calls  int
-		err    error
+		client     *TwitterClient
+		name       string
+		trackedIDs []int64
+		want       tweettypes.Tweets
+		calls      int
+		err        error
 	}{
 		"found 1 tweet": {
 			client: NewTwitterClient(
@@ -47,6 +52,8 @@ func Test_GetTweetsForUser(t *testing.T) {
 					},
 					nil,
 				),
+				DefaultTimelineMaxPages,
+				DefaultRateLimitMaxWait,
 			),
 			name: "bob",
 			want: tweettypes.Tweets{
@@ -56,6 +63,7 @@ func Test_GetTweetsForUser(t *testing.T) {
 					},
 					Status: tweettypes.TweetStatus{
 						ID:       12345,
+						URL:      "https://twitter.com/bob/status/12345",
 						Likes:    1,
 						Retweets: 2,
 						Replies:  3,
@@ -65,13 +73,163 @@ func Test_GetTweetsForUser(t *testing.T) {
 			calls: 1,
 			err:   nil,
 		},
+		"empty timeline": {
+			client: NewTwitterClient(
+				nil,
+				newTimelineClientMock(
+					"UserTimeline",
+					[]interface{}{
+						&twitter.UserTimelineParams{
+							ScreenName: "bob",
+							Count:      200,
+						},
+					},
+					[]twitter.Tweet{},
+					nil,
+				),
+				DefaultTimelineMaxPages,
+				DefaultRateLimitMaxWait,
+			),
+			name:  "bob",
+			want:  tweettypes.Tweets{},
+			calls: 1,
+			err:   nil,
+		},
+		"pages back until tracked tweets are found": {
+			client: NewTwitterClient(
+				nil,
+				newTimelineClientMock(
+					"UserTimeline",
+					[]interface{}{
+						&twitter.UserTimelineParams{
+							ScreenName: "bob",
+							Count:      200,
+							SinceID:    9,
+						},
+					},
+					[]twitter.Tweet{{ID: 30, Text: "Third"}, {ID: 20, Text: "Second"}},
+					nil,
+				).addMethod(
+					"UserTimeline",
+					[]interface{}{
+						&twitter.UserTimelineParams{
+							ScreenName: "bob",
+							Count:      200,
+							SinceID:    9,
+							MaxID:      19,
+						},
+					},
+					[]twitter.Tweet{{ID: 10, Text: "First"}},
+					nil,
+				),
+				DefaultTimelineMaxPages,
+				DefaultRateLimitMaxWait,
+			),
+			name:       "bob",
+			trackedIDs: []int64{10, 30},
+			want: tweettypes.Tweets{
+				{Spec: tweettypes.TweetSpec{Text: "Third"}, Status: tweettypes.TweetStatus{ID: 30, URL: "https://twitter.com/bob/status/30"}},
+				{Spec: tweettypes.TweetSpec{Text: "Second"}, Status: tweettypes.TweetStatus{ID: 20, URL: "https://twitter.com/bob/status/20"}},
+				{Spec: tweettypes.TweetSpec{Text: "First"}, Status: tweettypes.TweetStatus{ID: 10, URL: "https://twitter.com/bob/status/10"}},
+			},
+			calls: 2,
+			err:   nil,
+		},
+		"stops at max pages": {
+			client: NewTwitterClient(
+				nil,
+				newTimelineClientMock(
+					"UserTimeline",
+					[]interface{}{
+						&twitter.UserTimelineParams{
+							ScreenName: "bob",
+							Count:      200,
+							SinceID:    9,
+						},
+					},
+					[]twitter.Tweet{{ID: 30, Text: "Third"}},
+					nil,
+				),
+				1,
+				DefaultRateLimitMaxWait,
+			),
+			name:       "bob",
+			trackedIDs: []int64{10},
+			want: tweettypes.Tweets{
+				{Spec: tweettypes.TweetSpec{Text: "Third"}, Status: tweettypes.TweetStatus{ID: 30, URL: "https://twitter.com/bob/status/30"}},
+			},
+			calls: 1,
+			err:   nil,
+		},
+		"stops when timeline runs out": {
+			client: NewTwitterClient(
+				nil,
+				newTimelineClientMock(
+					"UserTimeline",
+					[]interface{}{
+						&twitter.UserTimelineParams{
+							ScreenName: "bob",
+							Count:      200,
+							SinceID:    9,
+						},
+					},
+					[]twitter.Tweet{{ID: 30, Text: "Third"}},
+					nil,
+				).addMethod(
+					"UserTimeline",
+					[]interface{}{
+						&twitter.UserTimelineParams{
+							ScreenName: "bob",
+							Count:      200,
+							SinceID:    9,
+							MaxID:      29,
+						},
+					},
+					[]twitter.Tweet{},
+					nil,
+				),
+				DefaultTimelineMaxPages,
+				DefaultRateLimitMaxWait,
+			),
+			name:       "bob",
+			trackedIDs: []int64{10},
+			want: tweettypes.Tweets{
+				{Spec: tweettypes.TweetSpec{Text: "Third"}, Status: tweettypes.TweetStatus{ID: 30, URL: "https://twitter.com/bob/status/30"}},
+			},
+			calls: 2,
+			err:   nil,
+		},
+		"timeline error": {
+			client: NewTwitterClient(
+				nil,
+				newTimelineClientMock(
+					"UserTimeline",
+					[]interface{}{
+						&twitter.UserTimelineParams{
+							ScreenName: "bob",
+							Count:      200,
+						},
+					},
+					nil,
+					errors.New("some error"),
+				),
+				DefaultTimelineMaxPages,
+				DefaultRateLimitMaxWait,
+			),
+			name:  "bob",
+			want:  nil,
+			calls: 1,
+			err:   errors.New("some error"),
+		},
 	}
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			tweets, err := test.client.GetTweetsForUser(test.name)
-			if err != nil {
-				assert.EqualError(t, test.err, err.Error())
+			tweets, err := test.client.GetTweetsForUser(context.TODO(), test.name, test.trackedIDs...)
+			if test.err != nil {
+				assert.EqualError(t, err, test.err.Error())
+			} else {
+				assert.NoError(t, err)
 			}
 			assert.Equal(t, test.want, tweets)
 			test.client.timelineClient.(*timelineClientMock).AssertNumberOfCalls(t, "UserTimeline", test.calls)
@@ -79,10 +237,73 @@ func Test_GetTweetsForUser(t *testing.T) {
 	}
 }
 
+func Test_GetMentions(t *testing.T) {
+	tests := map[string]struct {
+		sinceID  string
+		params   *twitter.MentionTimelineParams
+		expected []tweettypes.Mention
+		err      string
+	}{
+		"first poll": {
+			params: &twitter.MentionTimelineParams{Count: 200},
+			expected: []tweettypes.Mention{{
+				ID:     "43",
+				PostID: "43",
+				Author: "@alice",
+				Text:   "@bob Hi",
+				Time:   time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC),
+				URL:    "https://twitter.com/alice/status/43",
+			}},
+		},
+		"since a mention": {
+			sinceID: "42",
+			params:  &twitter.MentionTimelineParams{Count: 200, SinceID: 42},
+			expected: []tweettypes.Mention{{
+				ID:     "43",
+				PostID: "43",
+				Author: "@alice",
+				Text:   "@bob Hi",
+				Time:   time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC),
+				URL:    "https://twitter.com/alice/status/43",
+			}},
+		},
+		"invalid since ID": {
+			sinceID: "latest",
+			err:     `invalid since ID "latest"`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			timelineClient := newTimelineClientMock(
+				"MentionTimeline",
+				[]interface{}{test.params},
+				[]twitter.Tweet{{
+					ID:        43,
+					IDStr:     "43",
+					Text:      "@bob Hi",
+					CreatedAt: "Fri Jul 01 12:00:00 +0000 2022",
+					User:      &twitter.User{ScreenName: "alice"},
+				}},
+				nil,
+			)
+			client := NewTwitterClient(nil, timelineClient, DefaultTimelineMaxPages, DefaultRateLimitMaxWait)
+			mentions, err := client.GetMentions(context.TODO(), "bob", test.sinceID)
+			if test.err != "" {
+				assert.ErrorContains(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, mentions)
+		})
+	}
+}
+
 func Test_PostTweet(t *testing.T) {
 	tests := map[string]struct {
 		client *TwitterClient
 		in     tweettypes.Tweet
+		id     int64
 		calls  int
 		err    error
 	}{
@@ -97,16 +318,53 @@ func Test_PostTweet(t *testing.T) {
 							InReplyToStatusID: 0,
 						},
 					},
-					nil,
+					&twitter.Tweet{
+						ID:   12345,
+						Text: "Hello World",
+					},
 					nil,
 				),
 				nil,
+				DefaultTimelineMaxPages,
+				DefaultRateLimitMaxWait,
 			),
 			in: tweettypes.Tweet{
 				Spec: tweettypes.TweetSpec{
 					Text: "Hello World",
 				},
 			},
+			id:    12345,
+			calls: 1,
+			err:   nil,
+		},
+		"post reply success": {
+			client: NewTwitterClient(
+				newStatusClientMock(
+					"Update",
+					[]interface{}{
+						"@alice Thanks",
+						&twitter.StatusUpdateParams{
+							Status:            "@alice Thanks",
+							InReplyToStatusID: 43,
+						},
+					},
+					&twitter.Tweet{
+						ID:   12346,
+						Text: "@alice Thanks",
+					},
+					nil,
+				),
+				nil,
+				DefaultTimelineMaxPages,
+				DefaultRateLimitMaxWait,
+			),
+			in: tweettypes.Tweet{
+				Spec: tweettypes.TweetSpec{
+					Text:      "@alice Thanks",
+					InReplyTo: "43",
+				},
+			},
+			id:    12346,
 			calls: 1,
 			err:   nil,
 		},
@@ -114,10 +372,11 @@ func Test_PostTweet(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			err := test.client.PostTweet(&test.in)
+			id, err := test.client.PostTweet(context.TODO(), &test.in)
 			if err != nil {
 				assert.EqualError(t, test.err, err.Error())
 			}
+			assert.Equal(t, test.id, id)
 			test.client.statusClient.(*statusClientMock).AssertNumberOfCalls(t, "Update", test.calls)
 		})
 	}
@@ -145,6 +404,8 @@ func Test_DeleteTweet(t *testing.T) {
 					nil,
 				),
 				nil,
+				DefaultTimelineMaxPages,
+				DefaultRateLimitMaxWait,
 			),
 			in: &tweettypes.Tweet{
 				Status: tweettypes.TweetStatus{
@@ -159,7 +420,7 @@ func Test_DeleteTweet(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			err := test.client.DeleteTweet(test.in)
+			err := test.client.DeleteTweet(context.TODO(), test.in)
 			if err != nil {
 				assert.EqualError(t, test.err, err.Error())
 			}
@@ -168,6 +429,19 @@ func Test_DeleteTweet(t *testing.T) {
 	}
 }
 
+func Test_CredentialsRedacted(t *testing.T) {
+	creds := Credentials{
+		ConsumerKey:       "key",
+		ConsumerSecret:    "secret",
+		AccessToken:       "token",
+		AccessTokenSecret: "",
+	}
+	want := "{ConsumerKey:<redacted> ConsumerSecret:<redacted> AccessToken:<redacted> AccessTokenSecret:<empty>}"
+	assert.Equal(t, want, fmt.Sprintf("%+v", creds))
+	assert.Equal(t, want, fmt.Sprintf("%v", &creds))
+	assert.Equal(t, want, creds.MarshalLog())
+}
+
 func newStatusClientMock(method string, args []interface{}, ret interface{}, err error) *statusClientMock {
 	client := new(statusClientMock)
 	client.On(method, args...).Return(ret, err)
@@ -178,7 +452,7 @@ type statusClientMock struct {
 	mock.Mock
 }
 
-func (mock *statusClientMock) Update(status string, params *twitter.StatusUpdateParams) (*twitter.Tweet, *http.Response, error) {
+func (mock *statusClientMock) Update(ctx context.Context, status string, params *twitter.StatusUpdateParams) (*twitter.Tweet, *http.Response, error) {
 	args := mock.Called(status, params)
 	if args.Get(0) == nil {
 		return nil, nil, args.Error(1)
@@ -186,7 +460,7 @@ func (mock *statusClientMock) Update(status string, params *twitter.StatusUpdate
 	return args.Get(0).(*twitter.Tweet), nil, args.Error(1)
 }
 
-func (mock *statusClientMock) Destroy(id int64, params *twitter.StatusDestroyParams) (*twitter.Tweet, *http.Response, error) {
+func (mock *statusClientMock) Destroy(ctx context.Context, id int64, params *twitter.StatusDestroyParams) (*twitter.Tweet, *http.Response, error) {
 	args := mock.Called(id, params)
 	if args.Get(0) == nil {
 		return nil, nil, args.Error(1)
@@ -204,7 +478,20 @@ type timelineClientMock struct {
 	mock.Mock
 }
 
-func (mock *timelineClientMock) UserTimeline(params *twitter.UserTimelineParams) ([]twitter.Tweet, *http.Response, error) {
+func (mock *timelineClientMock) addMethod(method string, args []interface{}, ret interface{}, err error) *timelineClientMock {
+	mock.On(method, args...).Return(ret, err)
+	return mock
+}
+
+func (mock *timelineClientMock) UserTimeline(ctx context.Context, params *twitter.UserTimelineParams) ([]twitter.Tweet, *http.Response, error) {
+	args := mock.Called(params)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(1)
+	}
+	return args.Get(0).([]twitter.Tweet), nil, args.Error(1)
+}
+
+func (mock *timelineClientMock) MentionTimeline(ctx context.Context, params *twitter.MentionTimelineParams) ([]twitter.Tweet, *http.Response, error) {
 	args := mock.Called(params)
 	if args.Get(0) == nil {
 		return nil, nil, args.Error(1)
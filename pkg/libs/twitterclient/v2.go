@@ -0,0 +1,486 @@
+package twitterclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+)
+
+const DefaultV2BaseURL = "https://api.twitter.com/2/"
+
+const (
+	v2TimelinePageSize = 100
+	// Tweet lookup takes at most 100 IDs per request
+	v2LookupBatchSize = 100
+	// Recent search takes between 10 and 100 results per request
+	v2SearchMinResults = 10
+	v2SearchMaxResults = 100
+	v2TweetFields      = "public_metrics"
+	// Only available to the author, through user context auth, and only
+	// for tweets from the last 30 days
+	v2NonPublicTweetFields = "non_public_metrics"
+)
+
+// Endpoints, as named in the v2 rate limit documentation
+const (
+	endpointV2UsersMe         = "GET /2/users/me"
+	endpointV2UsersByUsername = "GET /2/users/by/username/:username"
+	endpointV2UsersTweets     = "GET /2/users/:id/tweets"
+	endpointV2UsersMentions   = "GET /2/users/:id/mentions"
+	endpointV2TweetsLookup    = "GET /2/tweets"
+	endpointV2TweetsCreate    = "POST /2/tweets"
+	endpointV2TweetsDelete    = "DELETE /2/tweets/:id"
+	endpointV2SearchRecent    = "GET /2/tweets/search/recent"
+)
+
+// V2APIError is the problem document the v2 API returns with non-2xx
+// responses.
+type V2APIError struct {
+	StatusCode int    `json:"status"`
+	Title      string `json:"title"`
+	Detail     string `json:"detail"`
+	Type       string `json:"type"`
+}
+
+func (e *V2APIError) Error() string {
+	return fmt.Sprintf("twitter: %d %s: %s", e.StatusCode, e.Title, e.Detail)
+}
+
+type v2Tweet struct {
+	ID            string `json:"id"`
+	Text          string `json:"text"`
+	PublicMetrics struct {
+		LikeCount     int64 `json:"like_count"`
+		RetweetCount  int64 `json:"retweet_count"`
+		ReplyCount    int64 `json:"reply_count"`
+		QuoteCount    int64 `json:"quote_count"`
+		BookmarkCount int64 `json:"bookmark_count"`
+		// Public since mid 2023, and missing from older responses
+		ImpressionCount int64 `json:"impression_count"`
+	} `json:"public_metrics"`
+	NonPublicMetrics struct {
+		ImpressionCount   int64 `json:"impression_count"`
+		URLLinkClicks     int64 `json:"url_link_clicks"`
+		UserProfileClicks int64 `json:"user_profile_clicks"`
+	} `json:"non_public_metrics"`
+}
+
+type v2User struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+// TwitterV2Client implements the same operations as TwitterClient on top of
+// the Twitter API v2, for access tiers that no longer allow v1.1.
+type TwitterV2Client struct {
+	httpClient       *http.Client
+	baseURL          string
+	timelineMaxPages int
+	tweetFields      string
+	limiter          *rateLimiter
+
+	mu      sync.Mutex
+	userIDs map[string]string
+}
+
+func NewTwitterV2Client(
+	httpClient *http.Client,
+	baseURL string,
+	timelineMaxPages int,
+	rateLimitMaxWait time.Duration,
+	nonPublicMetrics bool,
+) *TwitterV2Client {
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	tweetFields := v2TweetFields
+	if nonPublicMetrics {
+		tweetFields += "," + v2NonPublicTweetFields
+	}
+	return &TwitterV2Client{
+		httpClient:       httpClient,
+		baseURL:          baseURL,
+		timelineMaxPages: timelineMaxPages,
+		tweetFields:      tweetFields,
+		limiter:          newRateLimiter(rateLimitMaxWait),
+		userIDs:          map[string]string{},
+	}
+}
+
+func (c *TwitterV2Client) VerifyCredentials(ctx context.Context) error {
+	var resp struct {
+		Data v2User `json:"data"`
+	}
+	return c.do(ctx, endpointV2UsersMe, http.MethodGet, "users/me", nil, nil, &resp)
+}
+
+// GetTweetsForUser returns the user's timeline, newest first. It pages back
+// like TwitterClient.GetTweetsForUser, then looks up tracked tweets it did
+// not come across, so tweets older than the timeline allows are still found.
+func (c *TwitterV2Client) GetTweetsForUser(ctx context.Context, userName string, trackedIDs ...int64) (tweettypes.Tweets, error) {
+	userID, err := c.userID(ctx, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := map[int64]bool{}
+	var sinceID int64
+	for _, id := range trackedIDs {
+		if id <= 0 {
+			continue
+		}
+		missing[id] = true
+		// since_id is exclusive
+		if sinceID == 0 || id-1 < sinceID {
+			sinceID = id - 1
+		}
+	}
+
+	result := tweettypes.Tweets{}
+	paginationToken := ""
+	for page := 0; page < c.timelineMaxPages; page++ {
+		query := url.Values{
+			"max_results":  {strconv.Itoa(v2TimelinePageSize)},
+			"tweet.fields": {c.tweetFields},
+		}
+		if sinceID > 0 {
+			query.Set("since_id", strconv.FormatInt(sinceID, 10))
+		}
+		if paginationToken != "" {
+			query.Set("pagination_token", paginationToken)
+		}
+		var resp struct {
+			Data []v2Tweet `json:"data"`
+			Meta struct {
+				NextToken string `json:"next_token"`
+			} `json:"meta"`
+		}
+		err := c.do(ctx, endpointV2UsersTweets, http.MethodGet, "users/"+url.PathEscape(userID)+"/tweets", query, nil, &resp)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range resp.Data {
+			tweet, err := t.toTweet()
+			if err != nil {
+				return nil, err
+			}
+			delete(missing, tweet.Status.ID)
+			result = append(result, tweet)
+		}
+		if len(missing) == 0 || resp.Meta.NextToken == "" {
+			break
+		}
+		paginationToken = resp.Meta.NextToken
+	}
+
+	found, err := c.lookupTweets(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	result = append(result, found...)
+	// Looked up tweets come in no particular order
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Status.ID > result[j].Status.ID
+	})
+	for i := range result {
+		result[i].Status.URL = TweetURL(userName, result[i].Status.ID)
+	}
+	return result, nil
+}
+
+func (c *TwitterV2Client) lookupTweets(ctx context.Context, ids map[int64]bool) (tweettypes.Tweets, error) {
+	result := tweettypes.Tweets{}
+	batch := []string{}
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		query := url.Values{
+			"ids":          {strings.Join(batch, ",")},
+			"tweet.fields": {c.tweetFields},
+		}
+		batch = []string{}
+		var resp struct {
+			Data []v2Tweet `json:"data"`
+		}
+		// Deleted tweets come back in "errors" next to the ones found,
+		// which is not a failure here
+		err := c.do(ctx, endpointV2TweetsLookup, http.MethodGet, "tweets", query, nil, &resp)
+		if err != nil {
+			return err
+		}
+		for _, t := range resp.Data {
+			tweet, err := t.toTweet()
+			if err != nil {
+				return err
+			}
+			result = append(result, tweet)
+		}
+		return nil
+	}
+	for id := range ids {
+		batch = append(batch, strconv.FormatInt(id, 10))
+		if len(batch) == v2LookupBatchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *TwitterV2Client) PostTweet(ctx context.Context, tweet *tweettypes.Tweet) (int64, error) {
+	type v2Reply struct {
+		InReplyToTweetID string `json:"in_reply_to_tweet_id"`
+	}
+	body := struct {
+		Text  string   `json:"text"`
+		Reply *v2Reply `json:"reply,omitempty"`
+	}{
+		Text: tweet.Spec.Text,
+	}
+	if tweet.Spec.InReplyTo != "" {
+		body.Reply = &v2Reply{InReplyToTweetID: tweet.Spec.InReplyTo}
+	}
+	var resp struct {
+		Data v2Tweet `json:"data"`
+	}
+	err := c.do(ctx, endpointV2TweetsCreate, http.MethodPost, "tweets", nil, body, &resp)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(resp.Data.ID, 10, 64)
+}
+
+func (c *TwitterV2Client) DeleteTweet(ctx context.Context, tweet *tweettypes.Tweet) error {
+	var resp struct {
+		Data struct {
+			Deleted bool `json:"deleted"`
+		} `json:"data"`
+	}
+	path := "tweets/" + strconv.FormatInt(tweet.Status.ID, 10)
+	err := c.do(ctx, endpointV2TweetsDelete, http.MethodDelete, path, nil, nil, &resp)
+	if err != nil {
+		return err
+	}
+	if !resp.Data.Deleted {
+		return fmt.Errorf("tweet %d was not deleted", tweet.Status.ID)
+	}
+	return nil
+}
+
+// GetReplies returns the most recent replies in the tweet's conversation,
+// found through recent search, which only covers the last 7 days.
+func (c *TwitterV2Client) GetReplies(ctx context.Context, tweet *tweettypes.Tweet, max int) ([]tweettypes.Reply, error) {
+	maxResults := max
+	if maxResults < v2SearchMinResults {
+		maxResults = v2SearchMinResults
+	}
+	if maxResults > v2SearchMaxResults {
+		maxResults = v2SearchMaxResults
+	}
+	id := strconv.FormatInt(tweet.Status.ID, 10)
+	query := url.Values{
+		"query":        {"conversation_id:" + id + " is:reply"},
+		"max_results":  {strconv.Itoa(maxResults)},
+		"tweet.fields": {"author_id,created_at"},
+		"expansions":   {"author_id"},
+	}
+	var resp struct {
+		Data []struct {
+			ID        string    `json:"id"`
+			Text      string    `json:"text"`
+			AuthorID  string    `json:"author_id"`
+			CreatedAt time.Time `json:"created_at"`
+		} `json:"data"`
+		Includes struct {
+			Users []v2User `json:"users"`
+		} `json:"includes"`
+	}
+	if err := c.do(ctx, endpointV2SearchRecent, http.MethodGet, "tweets/search/recent", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	userNames := map[string]string{}
+	for _, u := range resp.Includes.Users {
+		userNames[u.ID] = u.Username
+	}
+	replies := []tweettypes.Reply{}
+	for _, t := range resp.Data {
+		if t.ID == id || len(replies) == max {
+			continue
+		}
+		author := t.AuthorID
+		if userName, ok := userNames[t.AuthorID]; ok {
+			author = "@" + userName
+		}
+		replies = append(replies, tweettypes.Reply{
+			ID:     t.ID,
+			Author: author,
+			Text:   t.Text,
+			Time:   t.CreatedAt,
+		})
+	}
+	return replies, nil
+}
+
+// GetMentions returns the mentions of the user posted after sinceID, newest
+// first. Only the most recent page is read.
+func (c *TwitterV2Client) GetMentions(ctx context.Context, userName, sinceID string) ([]tweettypes.Mention, error) {
+	userID, err := c.userID(ctx, userName)
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{
+		"max_results":  {strconv.Itoa(v2TimelinePageSize)},
+		"tweet.fields": {"author_id,created_at"},
+		"expansions":   {"author_id"},
+	}
+	if sinceID != "" {
+		query.Set("since_id", sinceID)
+	}
+	var resp struct {
+		Data []struct {
+			ID        string    `json:"id"`
+			Text      string    `json:"text"`
+			AuthorID  string    `json:"author_id"`
+			CreatedAt time.Time `json:"created_at"`
+		} `json:"data"`
+		Includes struct {
+			Users []v2User `json:"users"`
+		} `json:"includes"`
+	}
+	if err := c.do(ctx, endpointV2UsersMentions, http.MethodGet, "users/"+url.PathEscape(userID)+"/mentions", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	userNames := map[string]string{}
+	for _, u := range resp.Includes.Users {
+		userNames[u.ID] = u.Username
+	}
+	mentions := []tweettypes.Mention{}
+	for _, t := range resp.Data {
+		mention := tweettypes.Mention{
+			ID:     t.ID,
+			PostID: t.ID,
+			Author: t.AuthorID,
+			Text:   t.Text,
+			Time:   t.CreatedAt,
+		}
+		if userName, ok := userNames[t.AuthorID]; ok {
+			mention.Author = "@" + userName
+			if id, err := strconv.ParseInt(t.ID, 10, 64); err == nil {
+				mention.URL = TweetURL(userName, id)
+			}
+		}
+		mentions = append(mentions, mention)
+	}
+	return mentions, nil
+}
+
+func (c *TwitterV2Client) userID(ctx context.Context, userName string) (string, error) {
+	c.mu.Lock()
+	id, ok := c.userIDs[userName]
+	c.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	var resp struct {
+		Data v2User `json:"data"`
+	}
+	err := c.do(ctx, endpointV2UsersByUsername, http.MethodGet, "users/by/username/"+url.PathEscape(userName), nil, nil, &resp)
+	if err != nil {
+		return "", err
+	}
+	if resp.Data.ID == "" {
+		return "", fmt.Errorf("user %s not found", userName)
+	}
+
+	c.mu.Lock()
+	c.userIDs[userName] = resp.Data.ID
+	c.mu.Unlock()
+	return resp.Data.ID, nil
+}
+
+// do sends a request within the endpoint's rate limit and decodes the JSON
+// response into out.
+func (c *TwitterV2Client) do(ctx context.Context, endpoint, method, path string, query url.Values, body, out interface{}) error {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return c.limiter.do(ctx, endpoint, func() (*http.Response, error) {
+		var reqBody io.Reader
+		if body != nil {
+			b, err := json.Marshal(body)
+			if err != nil {
+				return nil, err
+			}
+			reqBody = bytes.NewReader(b)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+		if err != nil {
+			return nil, err
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			apiErr := &V2APIError{}
+			if err := json.NewDecoder(resp.Body).Decode(apiErr); err != nil || apiErr.StatusCode == 0 {
+				apiErr.StatusCode = resp.StatusCode
+			}
+			if apiErr.Title == "" {
+				apiErr.Title = http.StatusText(resp.StatusCode)
+			}
+			return resp, apiErr
+		}
+		return resp, json.NewDecoder(resp.Body).Decode(out)
+	})
+}
+
+func (t v2Tweet) toTweet() (tweettypes.Tweet, error) {
+	id, err := strconv.ParseInt(t.ID, 10, 64)
+	if err != nil {
+		return tweettypes.Tweet{}, fmt.Errorf("invalid tweet ID %q", t.ID)
+	}
+	impressions := t.PublicMetrics.ImpressionCount
+	if t.NonPublicMetrics.ImpressionCount > impressions {
+		impressions = t.NonPublicMetrics.ImpressionCount
+	}
+	return tweettypes.Tweet{
+		Spec: tweettypes.TweetSpec{
+			Text: t.Text,
+		},
+		Status: tweettypes.TweetStatus{
+			ID:            id,
+			Likes:         t.PublicMetrics.LikeCount,
+			Retweets:      t.PublicMetrics.RetweetCount,
+			Replies:       t.PublicMetrics.ReplyCount,
+			Quotes:        t.PublicMetrics.QuoteCount,
+			Bookmarks:     t.PublicMetrics.BookmarkCount,
+			Impressions:   impressions,
+			URLLinkClicks: t.NonPublicMetrics.URLLinkClicks,
+			ProfileClicks: t.NonPublicMetrics.UserProfileClicks,
+		},
+	}, nil
+}
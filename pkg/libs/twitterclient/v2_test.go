@@ -0,0 +1,275 @@
+package twitterclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeV2API is a minimal in-memory stand-in for the v2 endpoints the
+// client uses. Timeline pages hold pageSize tweets.
+type fakeV2API struct {
+	mu       sync.Mutex
+	tweets   []v2Tweet
+	nextID   int64
+	pageSize int
+	requests []string
+}
+
+func newFakeV2API(texts ...string) *fakeV2API {
+	api := &fakeV2API{nextID: 1, pageSize: 100}
+	for _, text := range texts {
+		api.add(text)
+	}
+	return api
+}
+
+func (api *fakeV2API) add(text string) string {
+	id := strconv.FormatInt(api.nextID, 10)
+	api.nextID++
+	// Newest first
+	api.tweets = append([]v2Tweet{{ID: id, Text: text}}, api.tweets...)
+	return id
+}
+
+func (api *fakeV2API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.requests = append(api.requests, r.Method+" "+r.URL.Path)
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/2/users/by/username/bob":
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": v2User{ID: "42", Username: "bob"}})
+	case r.Method == http.MethodGet && r.URL.Path == "/2/users/by/username/nobody":
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(V2APIError{StatusCode: 404, Title: "Not Found Error", Detail: "Could not find user with username: [nobody]."})
+	case r.Method == http.MethodGet && r.URL.Path == "/2/users/42/tweets":
+		tweets := api.tweets
+		if sinceID, _ := strconv.ParseInt(r.URL.Query().Get("since_id"), 10, 64); sinceID > 0 {
+			tweets = []v2Tweet{}
+			for _, t := range api.tweets {
+				if id, _ := strconv.ParseInt(t.ID, 10, 64); id > sinceID {
+					tweets = append(tweets, t)
+				}
+			}
+		}
+		start, _ := strconv.Atoi(r.URL.Query().Get("pagination_token"))
+		end := start + api.pageSize
+		if end > len(tweets) {
+			end = len(tweets)
+		}
+		resp := map[string]interface{}{"meta": map[string]interface{}{"result_count": end - start}}
+		if end > start {
+			resp["data"] = tweets[start:end]
+		}
+		if end < len(tweets) {
+			resp["meta"].(map[string]interface{})["next_token"] = strconv.Itoa(end)
+		}
+		json.NewEncoder(w).Encode(resp)
+	case r.Method == http.MethodGet && r.URL.Path == "/2/tweets":
+		found := []v2Tweet{}
+		for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+			for _, t := range api.tweets {
+				if t.ID == id {
+					found = append(found, t)
+				}
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": found})
+	case r.Method == http.MethodPost && r.URL.Path == "/2/tweets":
+		var body struct {
+			Text string `json:"text"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		for _, t := range api.tweets {
+			if t.Text == body.Text {
+				w.WriteHeader(http.StatusForbidden)
+				json.NewEncoder(w).Encode(V2APIError{
+					StatusCode: 403,
+					Title:      "Forbidden",
+					Detail:     "You are not allowed to create a Tweet with duplicate content.",
+				})
+				return
+			}
+		}
+		id := api.add(body.Text)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": v2Tweet{ID: id, Text: body.Text}})
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/2/tweets/"):
+		id := strings.TrimPrefix(r.URL.Path, "/2/tweets/")
+		for i, t := range api.tweets {
+			if t.ID == id {
+				api.tweets = append(api.tweets[:i], api.tweets[i+1:]...)
+				break
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]bool{"deleted": true}})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestV2Client(api *fakeV2API, maxPages int) (*TwitterV2Client, func()) {
+	server := httptest.NewServer(api)
+	client := NewTwitterV2Client(server.Client(), server.URL+"/2", maxPages, DefaultRateLimitMaxWait, false)
+	return client, server.Close
+}
+
+func Test_V2GetTweetsForUser(t *testing.T) {
+	tests := map[string]struct {
+		api        *fakeV2API
+		pageSize   int
+		maxPages   int
+		name       string
+		trackedIDs []int64
+		want       []int64
+		requests   []string
+		err        string
+	}{
+		"empty timeline": {
+			api:      newFakeV2API(),
+			maxPages: DefaultTimelineMaxPages,
+			name:     "bob",
+			want:     []int64{},
+			requests: []string{"GET /2/users/by/username/bob", "GET /2/users/42/tweets"},
+		},
+		"one page": {
+			api:      newFakeV2API("First", "Second"),
+			maxPages: DefaultTimelineMaxPages,
+			name:     "bob",
+			want:     []int64{2, 1},
+			requests: []string{"GET /2/users/by/username/bob", "GET /2/users/42/tweets"},
+		},
+		"pages back to tracked tweets": {
+			api:        newFakeV2API("First", "Second", "Third"),
+			pageSize:   1,
+			maxPages:   DefaultTimelineMaxPages,
+			name:       "bob",
+			trackedIDs: []int64{2},
+			want:       []int64{3, 2},
+			requests: []string{
+				"GET /2/users/by/username/bob",
+				"GET /2/users/42/tweets",
+				"GET /2/users/42/tweets",
+			},
+		},
+		"stops at the oldest tracked tweet": {
+			api:        newFakeV2API("First", "Second", "Third"),
+			maxPages:   DefaultTimelineMaxPages,
+			name:       "bob",
+			trackedIDs: []int64{2},
+			want:       []int64{3, 2},
+			requests:   []string{"GET /2/users/by/username/bob", "GET /2/users/42/tweets"},
+		},
+		"looks up tracked tweets beyond max pages": {
+			api:        newFakeV2API("First", "Second", "Third"),
+			pageSize:   1,
+			maxPages:   1,
+			name:       "bob",
+			trackedIDs: []int64{1},
+			want:       []int64{3, 1},
+			requests: []string{
+				"GET /2/users/by/username/bob",
+				"GET /2/users/42/tweets",
+				"GET /2/tweets",
+			},
+		},
+		"keeps looked up tweets newest first": {
+			api:        newFakeV2API("First", "Second", "Third", "Fourth"),
+			pageSize:   1,
+			maxPages:   1,
+			name:       "bob",
+			trackedIDs: []int64{1, 2, 3},
+			want:       []int64{4, 3, 2, 1},
+			requests: []string{
+				"GET /2/users/by/username/bob",
+				"GET /2/users/42/tweets",
+				"GET /2/tweets",
+			},
+		},
+		"unknown user": {
+			api:      newFakeV2API(),
+			maxPages: DefaultTimelineMaxPages,
+			name:     "nobody",
+			requests: []string{"GET /2/users/by/username/nobody"},
+			err:      "twitter: 404 Not Found Error: Could not find user with username: [nobody].",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if test.pageSize > 0 {
+				test.api.pageSize = test.pageSize
+			}
+			client, close := newTestV2Client(test.api, test.maxPages)
+			defer close()
+
+			tweets, err := client.GetTweetsForUser(context.TODO(), test.name, test.trackedIDs...)
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+			} else {
+				assert.NoError(t, err)
+				ids := []int64{}
+				for _, tweet := range tweets {
+					ids = append(ids, tweet.Status.ID)
+				}
+				assert.Equal(t, test.want, ids)
+			}
+			assert.Equal(t, test.requests, test.api.requests)
+		})
+	}
+}
+
+func Test_V2PostAndDeleteTweet(t *testing.T) {
+	api := newFakeV2API("Hello World")
+	client, close := newTestV2Client(api, DefaultTimelineMaxPages)
+	defer close()
+
+	id, err := client.PostTweet(context.TODO(), &tweettypes.Tweet{Spec: tweettypes.TweetSpec{Text: "Good morning"}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), id)
+
+	_, err = client.PostTweet(context.TODO(), &tweettypes.Tweet{Spec: tweettypes.TweetSpec{Text: "Hello World"}})
+	assert.True(t, IsDuplicate(err))
+
+	err = client.DeleteTweet(context.TODO(), &tweettypes.Tweet{Status: tweettypes.TweetStatus{ID: 1}})
+	assert.NoError(t, err)
+
+	tweets, err := client.GetTweetsForUser(context.TODO(), "bob")
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		tweettypes.Tweets{{Spec: tweettypes.TweetSpec{Text: "Good morning"}, Status: tweettypes.TweetStatus{ID: 2, URL: "https://twitter.com/bob/status/2"}}},
+		tweets,
+	)
+}
+
+func Test_V2PublicMetrics(t *testing.T) {
+	api := newFakeV2API()
+	api.tweets = []v2Tweet{{ID: "1", Text: "Hello World"}}
+	api.tweets[0].PublicMetrics.LikeCount = 1
+	api.tweets[0].PublicMetrics.RetweetCount = 2
+	api.tweets[0].PublicMetrics.ReplyCount = 3
+	client, close := newTestV2Client(api, DefaultTimelineMaxPages)
+	defer close()
+
+	tweets, err := client.GetTweetsForUser(context.TODO(), "bob")
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		tweettypes.Tweets{{
+			Spec:   tweettypes.TweetSpec{Text: "Hello World"},
+			Status: tweettypes.TweetStatus{ID: 1, URL: "https://twitter.com/bob/status/1", Likes: 1, Retweets: 2, Replies: 3},
+		}},
+		tweets,
+	)
+}
@@ -0,0 +1,56 @@
+// Package webhookclient posts JSON payloads to webhooks, such as the ones
+// MentionWatches notify.
+package webhookclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// StatusError is returned when a webhook answers with a non-2xx status.
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("webhook %s: %d %s", e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+type WebhookClient struct {
+	httpClient *http.Client
+}
+
+func NewWebhookClient(httpClient *http.Client) *WebhookClient {
+	return &WebhookClient{
+		httpClient: httpClient,
+	}
+}
+
+// Post sends payload as JSON to the URL.
+func (c *WebhookClient) Post(ctx context.Context, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Drained, so the connection can be reused
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &StatusError{URL: url, StatusCode: resp.StatusCode}
+	}
+	return nil
+}
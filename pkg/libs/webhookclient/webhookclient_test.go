@@ -0,0 +1,47 @@
+package webhookclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Post(t *testing.T) {
+	tests := map[string]struct {
+		statusCode int
+		err        string
+	}{
+		"accepted": {
+			statusCode: http.StatusNoContent,
+		},
+		"rejected": {
+			statusCode: http.StatusServiceUnavailable,
+			err:        "503 Service Unavailable",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var received map[string]string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				json.NewDecoder(r.Body).Decode(&received)
+				w.WriteHeader(test.statusCode)
+			}))
+			defer server.Close()
+
+			err := NewWebhookClient(server.Client()).Post(context.TODO(), server.URL+"/mentions", map[string]string{"text": "Hi"})
+			if test.err != "" {
+				assert.ErrorContains(t, err, test.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, map[string]string{"text": "Hi"}, received)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package policy
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+)
+
+// Rules of a TweetPolicy, as named in a Violation
+const (
+	RuleDenyPatterns       = "denyPatterns"
+	RuleRequiredPatterns   = "requiredPatterns"
+	RuleAllowedLinkDomains = "allowedLinkDomains"
+	RuleMaxMentions        = "maxMentions"
+)
+
+var (
+	linkRegex = regexp.MustCompile(`https?://[^\s]+`)
+	// A mention is an @ not preceded by a word character, so email
+	// addresses do not count. Mastodon mentions of other instances, such
+	// as @alice@mastodon.example, count once.
+	mentionRegex = regexp.MustCompile(`(?:^|[^\w@])@\w+(?:@[\w.-]+\w)?`)
+)
+
+// Violation is the first rule of a TweetPolicy a Tweet breaks. Rules with
+// several entries are named by index, such as denyPatterns[1].
+type Violation struct {
+	Policy  string
+	Rule    string
+	Message string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("violates rule %s of TweetPolicy %s: %s", v.Rule, v.Policy, v.Message)
+}
+
+// Check returns the first violation of the policies that apply to the
+// namespace, or nil. Policies are checked in order of name. An invalid
+// pattern is a violation, so a broken policy holds Tweets back rather than
+// letting them through.
+func Check(policies []tweettypes.TweetPolicy, namespace, text string) *Violation {
+	sorted := append([]tweettypes.TweetPolicy{}, policies...)
+	sort.Slice(sorted, func(a, b int) bool {
+		return sorted[a].Name < sorted[b].Name
+	})
+	for _, p := range sorted {
+		if !applies(&p, namespace) {
+			continue
+		}
+		if rule, message := check(&p, text); rule != "" {
+			return &Violation{Policy: p.Name, Rule: rule, Message: message}
+		}
+	}
+	return nil
+}
+
+func applies(p *tweettypes.TweetPolicy, namespace string) bool {
+	if len(p.Namespaces) == 0 {
+		return true
+	}
+	for _, n := range p.Namespaces {
+		if n == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+func check(p *tweettypes.TweetPolicy, text string) (rule, message string) {
+	for i, pattern := range p.DenyPatterns {
+		rule := fmt.Sprintf("%s[%d]", RuleDenyPatterns, i)
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			return rule, fmt.Sprintf("invalid pattern: %v", err)
+		}
+		// The match itself is left out, as it may be a secret
+		if regex.MatchString(text) {
+			return rule, fmt.Sprintf("text matches %q", pattern)
+		}
+	}
+	for i, pattern := range p.RequiredPatterns {
+		rule := fmt.Sprintf("%s[%d]", RuleRequiredPatterns, i)
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			return rule, fmt.Sprintf("invalid pattern: %v", err)
+		}
+		if !regex.MatchString(text) {
+			return rule, fmt.Sprintf("text does not match %q", pattern)
+		}
+	}
+	if len(p.AllowedLinkDomains) > 0 {
+		for _, link := range linkRegex.FindAllString(text, -1) {
+			host := linkHost(link)
+			if !allowedDomain(host, p.AllowedLinkDomains) {
+				return RuleAllowedLinkDomains, fmt.Sprintf("links to %s are not allowed", host)
+			}
+		}
+	}
+	if p.MaxMentions != nil {
+		mentions := len(mentionRegex.FindAllString(text, -1))
+		if mentions > *p.MaxMentions {
+			return RuleMaxMentions, fmt.Sprintf("%d mentions, at most %d allowed", mentions, *p.MaxMentions)
+		}
+	}
+	return "", ""
+}
+
+func linkHost(link string) string {
+	// Trailing punctuation most likely ends the sentence, not the link
+	link = strings.TrimRight(link, ".,;:!?)\"'")
+	u, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+func allowedDomain(host string, domains []string) bool {
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package policy
+
+import (
+	"testing"
+
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Check(t *testing.T) {
+	two := 2
+	tests := map[string]struct {
+		policies  []tweettypes.TweetPolicy
+		namespace string
+		text      string
+		want      *Violation
+	}{
+		"no policies": {
+			text: "Hello World",
+			want: nil,
+		},
+		"deny pattern matched": {
+			policies: []tweettypes.TweetPolicy{{
+				Name:         "no-internal-hosts",
+				DenyPatterns: []string{`(?i)darn`, `\.internal\b`},
+			}},
+			text: "Deployed to api.internal today",
+			want: &Violation{Policy: "no-internal-hosts", Rule: "denyPatterns[1]", Message: `text matches "\\.internal\\b"`},
+		},
+		"required pattern missing": {
+			policies: []tweettypes.TweetPolicy{{
+				Name:             "disclosure",
+				RequiredPatterns: []string{`#ad\b`},
+			}},
+			text: "Buy our product",
+			want: &Violation{Policy: "disclosure", Rule: "requiredPatterns[0]", Message: `text does not match "#ad\\b"`},
+		},
+		"required pattern present": {
+			policies: []tweettypes.TweetPolicy{{
+				Name:             "disclosure",
+				RequiredPatterns: []string{`#ad\b`},
+			}},
+			text: "Buy our product #ad",
+			want: nil,
+		},
+		"invalid pattern": {
+			policies: []tweettypes.TweetPolicy{{
+				Name:         "broken",
+				DenyPatterns: []string{`(`},
+			}},
+			text: "Hello World",
+			want: &Violation{Policy: "broken", Rule: "denyPatterns[0]", Message: "invalid pattern: error parsing regexp: missing closing ): `(`"},
+		},
+		"link to allowed subdomain": {
+			policies: []tweettypes.TweetPolicy{{
+				Name:               "links",
+				AllowedLinkDomains: []string{"example.com"},
+			}},
+			text: "Read https://blog.example.com/post.",
+			want: nil,
+		},
+		"link to other domain": {
+			policies: []tweettypes.TweetPolicy{{
+				Name:               "links",
+				AllowedLinkDomains: []string{"example.com"},
+			}},
+			text: "Read https://example.com and https://notexample.com/post",
+			want: &Violation{Policy: "links", Rule: "allowedLinkDomains", Message: "links to notexample.com are not allowed"},
+		},
+		"too many mentions": {
+			policies: []tweettypes.TweetPolicy{{
+				Name:        "mentions",
+				MaxMentions: &two,
+			}},
+			text: "@alice @bob and @carol@mastodon.example, mail me at dave@example.com",
+			want: &Violation{Policy: "mentions", Rule: "maxMentions", Message: "3 mentions, at most 2 allowed"},
+		},
+		"mentions within limit": {
+			policies: []tweettypes.TweetPolicy{{
+				Name:        "mentions",
+				MaxMentions: &two,
+			}},
+			text: "@alice @bob@mastodon.example, mail me at dave@example.com",
+			want: nil,
+		},
+		"policy of other namespace": {
+			policies: []tweettypes.TweetPolicy{{
+				Name:         "marketing",
+				Namespaces:   []string{"marketing"},
+				DenyPatterns: []string{`Hello`},
+			}},
+			namespace: "default",
+			text:      "Hello World",
+			want:      nil,
+		},
+		"first policy by name": {
+			policies: []tweettypes.TweetPolicy{
+				{Name: "b", DenyPatterns: []string{`Hello`}},
+				{Name: "a", Namespaces: []string{"default"}, DenyPatterns: []string{`World`}},
+			},
+			namespace: "default",
+			text:      "Hello World",
+			want:      &Violation{Policy: "a", Rule: "denyPatterns[0]", Message: `text matches "World"`},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, Check(test.policies, test.namespace, test.text))
+		})
+	}
+}
+
+func Test_ViolationError(t *testing.T) {
+	v := &Violation{Policy: "disclosure", Rule: "requiredPatterns[0]", Message: `text does not match "#ad"`}
+	assert.EqualError(t, v, `violates rule requiredPatterns[0] of TweetPolicy disclosure: text does not match "#ad"`)
+}
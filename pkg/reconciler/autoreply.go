@@ -0,0 +1,337 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/twitterclient"
+	"github.com/jonatanblue/tweet-operator/pkg/policy"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type AutoReplyClient interface {
+	ListAutoReplies(ctx context.Context) ([]tweettypes.AutoReply, error)
+	UpdateAutoReplyStatus(ctx context.Context, name string, status tweettypes.AutoReplyStatus) error
+}
+
+type AutoReplyEventRecorder interface {
+	AutoReplyEvent(autoReply *tweettypes.AutoReply, eventType, reason, message string)
+}
+
+// Auto-reply event reasons
+const (
+	ReasonAutoReplied       = "AutoReplied"
+	ReasonAutoReplyFailed   = "AutoReplyFailed"
+	ReasonInvalidAutoReply  = "InvalidAutoReply"
+	ReasonDailyLimitReached = "DailyLimitReached"
+)
+
+// dailyLimitWindow is the window the daily limit of an AutoReply counts
+// replies in
+const dailyLimitWindow = 24 * time.Hour
+
+// AutoReplyData is what the template of an AutoReply is given
+type AutoReplyData struct {
+	Author string
+	Text   string
+	URL    string
+	// Groups holds the match of the regex and its submatches, and is empty
+	// when the mention matched a keyword
+	Groups []string
+}
+
+// AutoReplier answers the mentions of the account that match the rules of
+// each AutoReply. Replies have to comply with the TweetPolicies like Tweets.
+type AutoReplier struct {
+	autoReplyClient AutoReplyClient
+	mentionClient   MentionClient
+	twitterClient   TwitterClient
+	recorder        AutoReplyEventRecorder
+	policyClient    PolicyClient
+	userName        string
+	log             logr.Logger
+	now             func() time.Time
+}
+
+func NewAutoReplier(
+	autoReplyClient AutoReplyClient,
+	mentionClient MentionClient,
+	twitterClient TwitterClient,
+	recorder AutoReplyEventRecorder,
+	policyClient PolicyClient,
+	userName string,
+	log logr.Logger,
+) *AutoReplier {
+	return &AutoReplier{
+		autoReplyClient: autoReplyClient,
+		mentionClient:   mentionClient,
+		twitterClient:   twitterClient,
+		recorder:        recorder,
+		policyClient:    policyClient,
+		userName:        userName,
+		log:             log,
+		now:             time.Now,
+	}
+}
+
+// Reply answers the new mentions for every AutoReply. An AutoReply that
+// fails is logged and skipped, except when rate limited, which stops the
+// pass.
+func (a *AutoReplier) Reply(ctx context.Context) error {
+	autoReplies, err := a.autoReplyClient.ListAutoReplies(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get auto-reply list from k8s")
+	}
+	var policies []tweettypes.TweetPolicy
+	if a.policyClient != nil && len(autoReplies) > 0 {
+		policies, err = a.policyClient.ListTweetPolicies(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to get tweet policies")
+		}
+	}
+	for _, autoReply := range autoReplies {
+		err := a.replyOne(ctx, &autoReply, policies)
+		var rateLimitErr *twitterclient.RateLimitError
+		if errors.As(err, &rateLimitErr) {
+			return err
+		}
+		if err != nil {
+			a.log.Error(err, "Failed to auto-reply", "namespace", autoReply.Spec.Namespace, "name", autoReply.Spec.Name)
+		}
+	}
+	return nil
+}
+
+func (a *AutoReplier) replyOne(ctx context.Context, autoReply *tweettypes.AutoReply, policies []tweettypes.TweetPolicy) error {
+	rules, err := newAutoReplyRules(&autoReply.Spec)
+	if err != nil {
+		a.event(autoReply, corev1.EventTypeWarning, ReasonInvalidAutoReply, err.Error())
+		return errors.Wrapf(err, "invalid auto-reply %s", autoReply.Spec.Name)
+	}
+	mentions, err := a.mentionClient.GetMentions(ctx, a.userName, autoReply.Status.SinceID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get mentions for %s", autoReply.Spec.Name)
+	}
+	now := a.now()
+	status := autoReply.Status
+	status.Answered = recentlyAnswered(status.Answered, now, autoReply.Spec.Cooldown)
+
+	// The first poll only notes where the auto-reply starts, so that it
+	// does not answer the mentions that came before it
+	if status.StartedAt.IsZero() {
+		status.StartedAt = now
+		if len(mentions) > 0 {
+			status.SinceID = mentions[0].ID
+		}
+		return a.updateStatus(ctx, autoReply, status)
+	}
+
+	// Oldest first, in the order they came in
+	for n := len(mentions) - 1; n >= 0; n-- {
+		mention := mentions[n]
+		sinceID := status.SinceID
+		status.SinceID = mention.ID
+		if isAnswered(status.Answered, mention.ID) || mention.Author == "@"+a.userName {
+			continue
+		}
+		data, ok := rules.match(&mention)
+		if !ok {
+			continue
+		}
+		log := a.log.WithValues("namespace", autoReply.Spec.Namespace, "name", autoReply.Spec.Name, "mentionID", mention.ID, "author", mention.Author)
+		if repliesSince(status.Answered, now.Add(-dailyLimitWindow)) >= autoReply.Spec.DailyLimit {
+			log.Info("Not replying, daily limit reached")
+			a.event(autoReply, corev1.EventTypeWarning, ReasonDailyLimitReached, fmt.Sprintf("Not replying to %s, %d replies in the last 24 hours", mention.Author, autoReply.Spec.DailyLimit))
+			continue
+		}
+		if inCooldown(status.Answered, mention.Author, now, autoReply.Spec.Cooldown) {
+			log.Info("Not replying, author in cooldown")
+			continue
+		}
+		text, err := rules.render(data)
+		if err != nil {
+			a.event(autoReply, corev1.EventTypeWarning, ReasonAutoReplyFailed, fmt.Sprintf("Failed to render reply to %s: %s", mention.Author, err))
+			continue
+		}
+		// The reply may quote the mention, so it is only checked once rendered
+		if violation := policy.Check(policies, autoReply.Spec.Namespace, text); violation != nil {
+			log.Info("Not replying, reply violates policy", "policy", violation.Policy, "rule", violation.Rule)
+			a.event(autoReply, corev1.EventTypeWarning, ReasonPolicyViolation, fmt.Sprintf("Not replying to %s: reply %s", mention.Author, violation.Error()))
+			continue
+		}
+
+		// Recorded before posting, so that a restart in between cannot
+		// answer it twice
+		status.Answered = append([]tweettypes.AnsweredMention{{ID: mention.ID, Author: mention.Author, Time: now}}, status.Answered...)
+		if err := a.updateStatus(ctx, autoReply, status); err != nil {
+			return err
+		}
+		id, err := a.twitterClient.PostTweet(ctx, &tweettypes.Tweet{
+			Spec: tweettypes.TweetSpec{
+				Namespace: autoReply.Spec.Namespace,
+				Name:      autoReply.Spec.Name,
+				// Unique per mention, for platforms that deduplicate posts
+				UID:       autoReply.Spec.UID + "-" + mention.ID,
+				Target:    autoReply.Spec.Target,
+				Text:      text,
+				InReplyTo: mention.PostID,
+			},
+		})
+		if err != nil {
+			status.Answered = status.Answered[1:]
+			var rateLimitErr *twitterclient.RateLimitError
+			if errors.As(err, &rateLimitErr) {
+				// Answered on the next poll instead
+				status.SinceID = sinceID
+				if updateErr := a.updateStatus(ctx, autoReply, status); updateErr != nil {
+					return updateErr
+				}
+				return err
+			}
+			a.event(autoReply, corev1.EventTypeWarning, ReasonAutoReplyFailed, fmt.Sprintf("Failed to reply to %s: %s", mention.Author, err))
+			continue
+		}
+		status.Answered[0].ReplyID = strconv.FormatInt(id, 10)
+		status.Replies++
+		log.Info("Replied to mention", "replyID", id)
+		a.event(autoReply, corev1.EventTypeNormal, ReasonAutoReplied, fmt.Sprintf("Replied to %s: %s", mention.Author, text))
+		if err := a.updateStatus(ctx, autoReply, status); err != nil {
+			return err
+		}
+	}
+	return a.updateStatus(ctx, autoReply, status)
+}
+
+func (a *AutoReplier) updateStatus(ctx context.Context, autoReply *tweettypes.AutoReply, status tweettypes.AutoReplyStatus) error {
+	if err := a.autoReplyClient.UpdateAutoReplyStatus(ctx, autoReply.Spec.Name, status); err != nil {
+		return errors.Wrapf(err, "failed to update status of %s", autoReply.Spec.Name)
+	}
+	return nil
+}
+
+func (a *AutoReplier) event(autoReply *tweettypes.AutoReply, eventType, reason, message string) {
+	if a.recorder == nil {
+		return
+	}
+	a.recorder.AutoReplyEvent(autoReply, eventType, reason, message)
+}
+
+type autoReplyRules struct {
+	regex    *regexp.Regexp
+	keywords []string
+	template *template.Template
+}
+
+func newAutoReplyRules(spec *tweettypes.AutoReplySpec) (*autoReplyRules, error) {
+	if spec.Regex == "" && len(spec.Keywords) == 0 {
+		return nil, fmt.Errorf("neither regex nor keywords set")
+	}
+	rules := &autoReplyRules{}
+	if spec.Regex != "" {
+		regex, err := regexp.Compile(spec.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex: %w", err)
+		}
+		rules.regex = regex
+	}
+	for _, keyword := range spec.Keywords {
+		if keyword != "" {
+			rules.keywords = append(rules.keywords, strings.ToLower(keyword))
+		}
+	}
+	if strings.TrimSpace(spec.Template) == "" {
+		return nil, fmt.Errorf("template not set")
+	}
+	tmpl, err := template.New(spec.Name).Option("missingkey=error").Parse(spec.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	rules.template = tmpl
+	return rules, nil
+}
+
+// match returns what the template is given for the mention, if the regex
+// or one of the keywords matches it.
+func (r *autoReplyRules) match(mention *tweettypes.Mention) (*AutoReplyData, bool) {
+	data := &AutoReplyData{
+		Author: mention.Author,
+		Text:   mention.Text,
+		URL:    mention.URL,
+	}
+	if r.regex != nil {
+		if groups := r.regex.FindStringSubmatch(mention.Text); groups != nil {
+			data.Groups = groups
+			return data, true
+		}
+	}
+	text := strings.ToLower(mention.Text)
+	for _, keyword := range r.keywords {
+		if strings.Contains(text, keyword) {
+			return data, true
+		}
+	}
+	return nil, false
+}
+
+func (r *autoReplyRules) render(data *AutoReplyData) (string, error) {
+	var text strings.Builder
+	if err := r.template.Execute(&text, data); err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(text.String()) == "" {
+		return "", fmt.Errorf("reply is empty")
+	}
+	return text.String(), nil
+}
+
+// recentlyAnswered drops the answered mentions that no longer count
+// towards the daily limit or the cooldown.
+func recentlyAnswered(answered []tweettypes.AnsweredMention, now time.Time, cooldown time.Duration) []tweettypes.AnsweredMention {
+	keep := dailyLimitWindow
+	if cooldown > keep {
+		keep = cooldown
+	}
+	var result []tweettypes.AnsweredMention
+	for _, a := range answered {
+		if a.Time.After(now.Add(-keep)) {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+func repliesSince(answered []tweettypes.AnsweredMention, since time.Time) int {
+	count := 0
+	for _, a := range answered {
+		if a.Time.After(since) {
+			count++
+		}
+	}
+	return count
+}
+
+func isAnswered(answered []tweettypes.AnsweredMention, id string) bool {
+	for _, a := range answered {
+		if a.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func inCooldown(answered []tweettypes.AnsweredMention, author string, now time.Time, cooldown time.Duration) bool {
+	for _, a := range answered {
+		if a.Author == author && a.Time.After(now.Add(-cooldown)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,284 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/twitterclient"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type autoReplyClientStub struct {
+	autoReplies []tweettypes.AutoReply
+}
+
+func (stub *autoReplyClientStub) ListAutoReplies(ctx context.Context) ([]tweettypes.AutoReply, error) {
+	return append([]tweettypes.AutoReply{}, stub.autoReplies...), nil
+}
+
+func (stub *autoReplyClientStub) UpdateAutoReplyStatus(ctx context.Context, name string, status tweettypes.AutoReplyStatus) error {
+	for i := range stub.autoReplies {
+		if stub.autoReplies[i].Spec.Name == name {
+			stub.autoReplies[i].Status = status
+		}
+	}
+	return nil
+}
+
+// postStub records the posts, and calls onPost first if set
+type postStub struct {
+	twitterClientMock
+	err    error
+	posted []tweettypes.Tweet
+	onPost func()
+}
+
+func (stub *postStub) PostTweet(ctx context.Context, tweet *tweettypes.Tweet) (int64, error) {
+	if stub.onPost != nil {
+		stub.onPost()
+	}
+	if stub.err != nil {
+		return 0, stub.err
+	}
+	stub.posted = append(stub.posted, *tweet)
+	return int64(100 + len(stub.posted)), nil
+}
+
+type autoReplyEventRecorderMock struct {
+	events []string
+}
+
+func (mock *autoReplyEventRecorderMock) AutoReplyEvent(autoReply *tweettypes.AutoReply, eventType, reason, message string) {
+	mock.events = append(mock.events, eventType+" "+reason+" "+message)
+}
+
+func Test_AutoReply(t *testing.T) {
+	now := time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+	started := now.Add(-time.Hour)
+	mention := func(id, author, text string) tweettypes.Mention {
+		return tweettypes.Mention{ID: id, PostID: "post-" + id, Author: author, Text: text}
+	}
+
+	tests := map[string]struct {
+		spec     tweettypes.AutoReplySpec
+		status   tweettypes.AutoReplyStatus
+		mentions []tweettypes.Mention
+		policies policyClientStub
+		posted   []string
+		answered []string
+		events   []string
+	}{
+		"first poll starts after the latest mention": {
+			spec:     tweettypes.AutoReplySpec{Keywords: []string{"price"}},
+			mentions: []tweettypes.Mention{mention("2", "@alice", "@bob price?")},
+		},
+		"regex with groups": {
+			spec:     tweettypes.AutoReplySpec{Regex: `price of (\w+)`},
+			status:   tweettypes.AutoReplyStatus{StartedAt: started},
+			mentions: []tweettypes.Mention{mention("2", "@alice", "@bob price of apples?")},
+			posted:   []string{"@alice No idea about apples"},
+			answered: []string{"2"},
+			events:   []string{"Normal AutoReplied Replied to @alice: @alice No idea about apples"},
+		},
+		"reply violating a policy": {
+			spec:     tweettypes.AutoReplySpec{Regex: `price of (\w+)`},
+			status:   tweettypes.AutoReplyStatus{StartedAt: started},
+			mentions: []tweettypes.Mention{mention("2", "@alice", "@bob price of crypto?")},
+			policies: policyClientStub{{Name: "no-crypto", DenyPatterns: []string{"(?i)crypto"}}},
+			events:   []string{"Warning PolicyViolation Not replying to @alice: reply violates rule denyPatterns[0] of TweetPolicy no-crypto: text matches \"(?i)crypto\""},
+		},
+		"keywords ignore case": {
+			spec:     tweettypes.AutoReplySpec{Keywords: []string{"hours", "OPEN"}},
+			status:   tweettypes.AutoReplyStatus{StartedAt: started},
+			mentions: []tweettypes.Mention{mention("3", "@carol", "@bob Are you open?"), mention("2", "@alice", "@bob Hi")},
+			posted:   []string{"@carol No idea about "},
+			answered: []string{"3"},
+			events:   []string{"Normal AutoReplied Replied to @carol: @carol No idea about "},
+		},
+		"not its own mentions": {
+			spec:     tweettypes.AutoReplySpec{Keywords: []string{"open"}},
+			status:   tweettypes.AutoReplyStatus{StartedAt: started},
+			mentions: []tweettypes.Mention{mention("2", "@bob", "@bob open")},
+		},
+		"one reply per author in the cooldown": {
+			spec:     tweettypes.AutoReplySpec{Keywords: []string{"open"}},
+			status:   tweettypes.AutoReplyStatus{StartedAt: started},
+			mentions: []tweettypes.Mention{mention("4", "@carol", "open?"), mention("3", "@alice", "open now?"), mention("2", "@alice", "open?")},
+			posted:   []string{"@alice No idea about ", "@carol No idea about "},
+			answered: []string{"4", "2"},
+			events: []string{
+				"Normal AutoReplied Replied to @alice: @alice No idea about ",
+				"Normal AutoReplied Replied to @carol: @carol No idea about ",
+			},
+		},
+		"cooldown over": {
+			spec: tweettypes.AutoReplySpec{Keywords: []string{"open"}},
+			status: tweettypes.AutoReplyStatus{StartedAt: started, Answered: []tweettypes.AnsweredMention{
+				{ID: "1", Author: "@alice", ReplyID: "100", Time: now.Add(-2 * time.Hour)},
+			}},
+			mentions: []tweettypes.Mention{mention("2", "@alice", "open?")},
+			posted:   []string{"@alice No idea about "},
+			answered: []string{"2", "1"},
+			events:   []string{"Normal AutoReplied Replied to @alice: @alice No idea about "},
+		},
+		"daily limit": {
+			spec: tweettypes.AutoReplySpec{Keywords: []string{"open"}, DailyLimit: 1},
+			status: tweettypes.AutoReplyStatus{StartedAt: started, Answered: []tweettypes.AnsweredMention{
+				{ID: "1", Author: "@carol", ReplyID: "100", Time: now.Add(-23 * time.Hour)},
+			}},
+			mentions: []tweettypes.Mention{mention("2", "@alice", "open?")},
+			answered: []string{"1"},
+			events:   []string{"Warning DailyLimitReached Not replying to @alice, 1 replies in the last 24 hours"},
+		},
+		"answered mentions are not answered again": {
+			spec: tweettypes.AutoReplySpec{Keywords: []string{"open"}},
+			status: tweettypes.AutoReplyStatus{StartedAt: started, Answered: []tweettypes.AnsweredMention{
+				{ID: "2", Author: "@alice", Time: now.Add(-time.Minute)},
+			}},
+			mentions: []tweettypes.Mention{mention("2", "@alice", "open?")},
+			answered: []string{"2"},
+		},
+		"old answers are dropped": {
+			spec: tweettypes.AutoReplySpec{Keywords: []string{"open"}},
+			status: tweettypes.AutoReplyStatus{StartedAt: started, Answered: []tweettypes.AnsweredMention{
+				{ID: "1", Author: "@alice", ReplyID: "100", Time: now.Add(-25 * time.Hour)},
+			}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			spec := test.spec
+			spec.Name = "open"
+			spec.Template = `{{.Author}} No idea about {{if .Groups}}{{index .Groups 1}}{{end}}`
+			spec.Cooldown = time.Hour
+			if spec.DailyLimit == 0 {
+				spec.DailyLimit = 50
+			}
+			autoReplyClient := &autoReplyClientStub{autoReplies: []tweettypes.AutoReply{{Spec: spec, Status: test.status}}}
+			twitterClient := &postStub{}
+			recorder := &autoReplyEventRecorderMock{}
+
+			replier := NewAutoReplier(autoReplyClient, &mentionClientStub{mentions: test.mentions}, twitterClient, recorder, test.policies, "bob", logr.Discard())
+			replier.now = func() time.Time { return now }
+			assert.NoError(t, replier.Reply(context.TODO()))
+
+			posted := []string{}
+			for _, tweet := range twitterClient.posted {
+				posted = append(posted, tweet.Spec.Text)
+			}
+			assert.ElementsMatch(t, test.posted, posted)
+			status := autoReplyClient.autoReplies[0].Status
+			answered := []string{}
+			for _, a := range status.Answered {
+				answered = append(answered, a.ID)
+			}
+			assert.ElementsMatch(t, test.answered, answered)
+			assert.Equal(t, int64(len(test.posted)), status.Replies)
+			if len(test.mentions) > 0 {
+				assert.Equal(t, test.mentions[0].ID, status.SinceID)
+			}
+			assert.Equal(t, test.events, recorder.events)
+		})
+	}
+}
+
+func Test_AutoReplyPost(t *testing.T) {
+	started := time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+	autoReplyClient := &autoReplyClientStub{autoReplies: []tweettypes.AutoReply{{
+		Spec: tweettypes.AutoReplySpec{
+			Namespace:  "default",
+			Name:       "open",
+			UID:        "1234",
+			Target:     "fediverse",
+			Keywords:   []string{"open"},
+			Template:   "{{.Author}} Yes",
+			DailyLimit: 50,
+		},
+		Status: tweettypes.AutoReplyStatus{StartedAt: started},
+	}}}
+	twitterClient := &postStub{}
+	// The mention is recorded before the reply is posted
+	twitterClient.onPost = func() {
+		assert.Equal(t, "7", autoReplyClient.autoReplies[0].Status.Answered[0].ID)
+		assert.Empty(t, autoReplyClient.autoReplies[0].Status.Answered[0].ReplyID)
+	}
+	mentionClient := &mentionClientStub{mentions: []tweettypes.Mention{{ID: "7", PostID: "42", Author: "@alice", Text: "open?"}}}
+
+	replier := NewAutoReplier(autoReplyClient, mentionClient, twitterClient, nil, nil, "bob", logr.Discard())
+	assert.NoError(t, replier.Reply(context.TODO()))
+	assert.Equal(t, []tweettypes.Tweet{{Spec: tweettypes.TweetSpec{
+		Namespace: "default",
+		Name:      "open",
+		UID:       "1234-7",
+		Target:    "fediverse",
+		Text:      "@alice Yes",
+		InReplyTo: "42",
+	}}}, twitterClient.posted)
+	assert.Equal(t, "101", autoReplyClient.autoReplies[0].Status.Answered[0].ReplyID)
+}
+
+func Test_AutoReplyErrors(t *testing.T) {
+	started := time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		spec     tweettypes.AutoReplySpec
+		postErr  error
+		err      error
+		sinceID  string
+		answered int
+		events   []string
+	}{
+		"invalid regex": {
+			spec:    tweettypes.AutoReplySpec{Regex: "(", Template: "Hi"},
+			sinceID: "1",
+			events:  []string{"Warning InvalidAutoReply invalid regex: error parsing regexp: missing closing ): `(`"},
+		},
+		"no rules": {
+			spec:    tweettypes.AutoReplySpec{Template: "Hi"},
+			sinceID: "1",
+			events:  []string{"Warning InvalidAutoReply neither regex nor keywords set"},
+		},
+		"failed post is skipped": {
+			spec:    tweettypes.AutoReplySpec{Keywords: []string{"open"}, Template: "Hi"},
+			postErr: errors.New("duplicate"),
+			sinceID: "2",
+			events:  []string{"Warning AutoReplyFailed Failed to reply to @alice: duplicate"},
+		},
+		"rate limited post is retried": {
+			spec:    tweettypes.AutoReplySpec{Keywords: []string{"open"}, Template: "Hi"},
+			postErr: &twitterclient.RateLimitError{Endpoint: "statuses/update"},
+			err:     &twitterclient.RateLimitError{},
+			sinceID: "1",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			spec := test.spec
+			spec.Name = "open"
+			spec.DailyLimit = 50
+			autoReplyClient := &autoReplyClientStub{autoReplies: []tweettypes.AutoReply{{
+				Spec:   spec,
+				Status: tweettypes.AutoReplyStatus{StartedAt: started, SinceID: "1"},
+			}}}
+			mentionClient := &mentionClientStub{mentions: []tweettypes.Mention{{ID: "2", Author: "@alice", Text: "open?"}, {ID: "1"}}}
+			recorder := &autoReplyEventRecorderMock{}
+
+			replier := NewAutoReplier(autoReplyClient, mentionClient, &postStub{err: test.postErr}, recorder, nil, "bob", logr.Discard())
+			err := replier.Reply(context.TODO())
+			if test.err != nil {
+				assert.ErrorAs(t, err, &test.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.sinceID, autoReplyClient.autoReplies[0].Status.SinceID)
+			assert.Len(t, autoReplyClient.autoReplies[0].Status.Answered, test.answered)
+			assert.Equal(t, test.events, recorder.events)
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/twitterclient"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// MentionClient fetches the mentions of an account posted after sinceID,
+// newest first. An empty sinceID fetches the most recent ones. Not every
+// platform client offers it, so it is not part of TwitterClient.
+type MentionClient interface {
+	GetMentions(ctx context.Context, userName, sinceID string) ([]tweettypes.Mention, error)
+}
+
+type MentionWatchClient interface {
+	ListMentionWatches(ctx context.Context) ([]tweettypes.MentionWatch, error)
+	UpdateMentionWatchStatus(ctx context.Context, name string, status tweettypes.MentionWatchStatus) error
+}
+
+type MentionEventRecorder interface {
+	MentionWatchEvent(watch *tweettypes.MentionWatch, eventType, reason, message string)
+}
+
+// WebhookClient posts a JSON payload to a URL
+type WebhookClient interface {
+	Post(ctx context.Context, url string, payload interface{}) error
+}
+
+// Mention event reasons
+const (
+	ReasonMentioned     = "Mentioned"
+	ReasonWebhookFailed = "WebhookFailed"
+)
+
+// MentionPayload is what is posted to the webhook of a MentionWatch for
+// each new mention.
+type MentionPayload struct {
+	Watch     string    `json:"watch"`
+	Namespace string    `json:"namespace"`
+	Target    string    `json:"target"`
+	ID        string    `json:"id"`
+	Author    string    `json:"author"`
+	Text      string    `json:"text"`
+	Time      time.Time `json:"time"`
+	URL       string    `json:"url,omitempty"`
+}
+
+// MentionWatcher polls the mentions of the account for each MentionWatch,
+// and reports the new ones as events and to the watch's webhook.
+type MentionWatcher struct {
+	watchClient   MentionWatchClient
+	mentionClient MentionClient
+	webhookClient WebhookClient
+	recorder      MentionEventRecorder
+	userName      string
+	log           logr.Logger
+	now           func() time.Time
+}
+
+func NewMentionWatcher(
+	watchClient MentionWatchClient,
+	mentionClient MentionClient,
+	webhookClient WebhookClient,
+	recorder MentionEventRecorder,
+	userName string,
+	log logr.Logger,
+) *MentionWatcher {
+	return &MentionWatcher{
+		watchClient:   watchClient,
+		mentionClient: mentionClient,
+		webhookClient: webhookClient,
+		recorder:      recorder,
+		userName:      userName,
+		log:           log,
+		now:           time.Now,
+	}
+}
+
+// Watch polls the mentions for every MentionWatch. A watch that fails is
+// logged and skipped, except when rate limited, which stops the pass.
+func (w *MentionWatcher) Watch(ctx context.Context) error {
+	watches, err := w.watchClient.ListMentionWatches(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get mention watch list from k8s")
+	}
+	for _, watch := range watches {
+		err := w.watchOne(ctx, &watch)
+		var rateLimitErr *twitterclient.RateLimitError
+		if errors.As(err, &rateLimitErr) {
+			return err
+		}
+		if err != nil {
+			w.log.Error(err, "Failed to watch mentions", "namespace", watch.Spec.Namespace, "name", watch.Spec.Name)
+		}
+	}
+	return nil
+}
+
+func (w *MentionWatcher) watchOne(ctx context.Context, watch *tweettypes.MentionWatch) error {
+	mentions, err := w.mentionClient.GetMentions(ctx, w.userName, watch.Status.SinceID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get mentions for %s", watch.Spec.Name)
+	}
+	status := watch.Status
+
+	// The first poll only notes where the watch starts, so that a new
+	// watch does not report the mentions that came before it
+	if status.StartedAt.IsZero() {
+		status.StartedAt = w.now()
+		if len(mentions) > 0 {
+			status.SinceID = mentions[0].ID
+		}
+		return w.updateStatus(ctx, watch, status)
+	}
+
+	// Oldest first, in the order they came in
+	var webhookErr error
+	for n := len(mentions) - 1; n >= 0; n-- {
+		mention := mentions[n]
+		if watch.Spec.WebhookURL != "" {
+			if webhookErr = w.webhookClient.Post(ctx, watch.Spec.WebhookURL, w.payload(watch, &mention)); webhookErr != nil {
+				// Not marked as seen, so it is delivered on the next poll
+				w.event(watch, corev1.EventTypeWarning, ReasonWebhookFailed, webhookErr.Error())
+				break
+			}
+		}
+		w.log.Info("New mention", "namespace", watch.Spec.Namespace, "name", watch.Spec.Name, "mentionID", mention.ID, "author", mention.Author)
+		w.event(watch, corev1.EventTypeNormal, ReasonMentioned, fmt.Sprintf("Mention from %s: %s", mention.Author, mention.Text))
+		status.SinceID = mention.ID
+		status.Mentions++
+		status.LastMentionTime = mention.Time
+	}
+	if err := w.updateStatus(ctx, watch, status); err != nil {
+		return err
+	}
+	return errors.Wrapf(webhookErr, "failed to deliver mention to webhook of %s", watch.Spec.Name)
+}
+
+func (w *MentionWatcher) updateStatus(ctx context.Context, watch *tweettypes.MentionWatch, status tweettypes.MentionWatchStatus) error {
+	if status == watch.Status {
+		return nil
+	}
+	if err := w.watchClient.UpdateMentionWatchStatus(ctx, watch.Spec.Name, status); err != nil {
+		return errors.Wrapf(err, "failed to update status of %s", watch.Spec.Name)
+	}
+	return nil
+}
+
+func (w *MentionWatcher) payload(watch *tweettypes.MentionWatch, mention *tweettypes.Mention) MentionPayload {
+	return MentionPayload{
+		Watch:     watch.Spec.Name,
+		Namespace: watch.Spec.Namespace,
+		Target:    watch.Spec.Target,
+		ID:        mention.ID,
+		Author:    mention.Author,
+		Text:      mention.Text,
+		Time:      mention.Time,
+		URL:       mention.URL,
+	}
+}
+
+func (w *MentionWatcher) event(watch *tweettypes.MentionWatch, eventType, reason, message string) {
+	if w.recorder == nil {
+		return
+	}
+	w.recorder.MentionWatchEvent(watch, eventType, reason, message)
+}
@@ -0,0 +1,178 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/twitterclient"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// mentionClientStub returns the mentions after sinceID, newest first
+type mentionClientStub struct {
+	mentions []tweettypes.Mention
+	err      error
+	calls    []string
+}
+
+func (stub *mentionClientStub) GetMentions(ctx context.Context, userName, sinceID string) ([]tweettypes.Mention, error) {
+	stub.calls = append(stub.calls, sinceID)
+	result := []tweettypes.Mention{}
+	for _, m := range stub.mentions {
+		if m.ID == sinceID {
+			break
+		}
+		result = append(result, m)
+	}
+	return result, stub.err
+}
+
+type mentionWatchClientStub struct {
+	watches []tweettypes.MentionWatch
+}
+
+func (stub *mentionWatchClientStub) ListMentionWatches(ctx context.Context) ([]tweettypes.MentionWatch, error) {
+	return append([]tweettypes.MentionWatch{}, stub.watches...), nil
+}
+
+func (stub *mentionWatchClientStub) UpdateMentionWatchStatus(ctx context.Context, name string, status tweettypes.MentionWatchStatus) error {
+	for i := range stub.watches {
+		if stub.watches[i].Spec.Name == name {
+			stub.watches[i].Status = status
+		}
+	}
+	return nil
+}
+
+type webhookClientStub struct {
+	err      error
+	payloads []interface{}
+}
+
+func (stub *webhookClientStub) Post(ctx context.Context, url string, payload interface{}) error {
+	if stub.err != nil {
+		return stub.err
+	}
+	stub.payloads = append(stub.payloads, payload)
+	return nil
+}
+
+type mentionEventRecorderMock struct {
+	events []string
+}
+
+func (mock *mentionEventRecorderMock) MentionWatchEvent(watch *tweettypes.MentionWatch, eventType, reason, message string) {
+	mock.events = append(mock.events, eventType+" "+reason+" "+message)
+}
+
+func Test_WatchMentions(t *testing.T) {
+	started := time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+	mention := func(id string, minutes int) tweettypes.Mention {
+		return tweettypes.Mention{
+			ID:     id,
+			Author: "@alice",
+			Text:   "@bob mention " + id,
+			Time:   started.Add(time.Duration(minutes) * time.Minute),
+		}
+	}
+	mentions := []tweettypes.Mention{mention("3", 3), mention("2", 2), mention("1", 1)}
+
+	tests := map[string]struct {
+		status     tweettypes.MentionWatchStatus
+		webhookURL string
+		webhookErr error
+		expected   tweettypes.MentionWatchStatus
+		events     []string
+		payloads   int
+	}{
+		"first poll starts after the latest mention": {
+			expected: tweettypes.MentionWatchStatus{StartedAt: started, SinceID: "3"},
+		},
+		"new mentions, oldest event first": {
+			status:   tweettypes.MentionWatchStatus{StartedAt: started, SinceID: "1", Mentions: 4},
+			expected: tweettypes.MentionWatchStatus{StartedAt: started, SinceID: "3", Mentions: 6, LastMentionTime: started.Add(3 * time.Minute)},
+			events: []string{
+				"Normal Mentioned Mention from @alice: @bob mention 2",
+				"Normal Mentioned Mention from @alice: @bob mention 3",
+			},
+		},
+		"nothing new": {
+			status:   tweettypes.MentionWatchStatus{StartedAt: started, SinceID: "3"},
+			expected: tweettypes.MentionWatchStatus{StartedAt: started, SinceID: "3"},
+		},
+		"posts to the webhook": {
+			status:     tweettypes.MentionWatchStatus{StartedAt: started, SinceID: "2"},
+			webhookURL: "http://receiver.default.svc/mentions",
+			expected:   tweettypes.MentionWatchStatus{StartedAt: started, SinceID: "3", Mentions: 1, LastMentionTime: started.Add(3 * time.Minute)},
+			events:     []string{"Normal Mentioned Mention from @alice: @bob mention 3"},
+			payloads:   1,
+		},
+		"failed webhook is retried on the next poll": {
+			status:     tweettypes.MentionWatchStatus{StartedAt: started, SinceID: "2"},
+			webhookURL: "http://receiver.default.svc/mentions",
+			webhookErr: errors.New("connection refused"),
+			expected:   tweettypes.MentionWatchStatus{StartedAt: started, SinceID: "2"},
+			events:     []string{"Warning WebhookFailed connection refused"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			watchClient := &mentionWatchClientStub{watches: []tweettypes.MentionWatch{{
+				Spec:   tweettypes.MentionWatchSpec{Namespace: "default", Name: "mentions", Target: "twitter", WebhookURL: test.webhookURL},
+				Status: test.status,
+			}}}
+			webhookClient := &webhookClientStub{err: test.webhookErr}
+			recorder := &mentionEventRecorderMock{}
+
+			watcher := NewMentionWatcher(watchClient, &mentionClientStub{mentions: mentions}, webhookClient, recorder, "bob", logr.Discard())
+			watcher.now = func() time.Time { return started }
+			assert.NoError(t, watcher.Watch(context.TODO()))
+			assert.Equal(t, test.expected, watchClient.watches[0].Status)
+			assert.Equal(t, test.events, recorder.events)
+			assert.Len(t, webhookClient.payloads, test.payloads)
+		})
+	}
+}
+
+func Test_WatchMentionsPayload(t *testing.T) {
+	at := time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+	watchClient := &mentionWatchClientStub{watches: []tweettypes.MentionWatch{{
+		Spec:   tweettypes.MentionWatchSpec{Namespace: "default", Name: "mentions", Target: "twitter", WebhookURL: "http://receiver/"},
+		Status: tweettypes.MentionWatchStatus{StartedAt: at},
+	}}}
+	mentionClient := &mentionClientStub{mentions: []tweettypes.Mention{
+		{ID: "7", Author: "@alice", Text: "@bob Hi", Time: at, URL: "https://twitter.com/alice/status/7"},
+	}}
+	webhookClient := &webhookClientStub{}
+
+	watcher := NewMentionWatcher(watchClient, mentionClient, webhookClient, nil, "bob", logr.Discard())
+	assert.NoError(t, watcher.Watch(context.TODO()))
+	assert.Equal(t, []interface{}{MentionPayload{
+		Watch:     "mentions",
+		Namespace: "default",
+		Target:    "twitter",
+		ID:        "7",
+		Author:    "@alice",
+		Text:      "@bob Hi",
+		Time:      at,
+		URL:       "https://twitter.com/alice/status/7",
+	}}, webhookClient.payloads)
+}
+
+func Test_WatchMentionsRateLimited(t *testing.T) {
+	watchClient := &mentionWatchClientStub{watches: []tweettypes.MentionWatch{
+		{Spec: tweettypes.MentionWatchSpec{Name: "first"}},
+		{Spec: tweettypes.MentionWatchSpec{Name: "second"}},
+	}}
+	mentionClient := &mentionClientStub{err: &twitterclient.RateLimitError{Endpoint: "mentions"}}
+
+	watcher := NewMentionWatcher(watchClient, mentionClient, nil, nil, "bob", logr.Discard())
+	var expected *twitterclient.RateLimitError
+	assert.ErrorAs(t, watcher.Watch(context.TODO()), &expected)
+	assert.Equal(t, []string{""}, mentionClient.calls)
+}
@@ -0,0 +1,149 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type Action string
+
+const (
+	// ActionCreate posts a tweet for a Tweet resource
+	ActionCreate = Action("create")
+	// ActionAdopt links a Tweet resource to a tweet already on the timeline
+	ActionAdopt = Action("adopt")
+	// ActionDelete deletes a tweet that no Tweet resource asks for
+	ActionDelete = Action("delete")
+	// ActionOrphan marks a Tweet resource whose recorded tweet is gone from
+	// the timeline. It is posted again.
+	ActionOrphan = Action("orphan")
+)
+
+type PlannedChange struct {
+	Action    Action `json:"action"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	TweetID   int64  `json:"tweetID,omitempty"`
+	Text      string `json:"text"`
+}
+
+// Plan lists the changes a reconciliation would make, without making them.
+type Plan struct {
+	Target  string          `json:"target,omitempty"`
+	Account string          `json:"account"`
+	Changes []PlannedChange `json:"changes"`
+}
+
+func (reconciler *TweetReconciler) Plan(ctx context.Context) (*Plan, error) {
+	desiredTweetList, err := reconciler.k8sClient.ListTweets(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get tweet list from k8s")
+	}
+	// A plan is for review, so it is always made from a fresh read
+	reconciler.invalidateSnapshot()
+	tracked := trackedIDs(*desiredTweetList)
+	snapshot, err := reconciler.getSnapshot(ctx, tracked)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get tweets for user %s", reconciler.twitterUserName)
+	}
+
+	plan := &Plan{
+		Account: reconciler.twitterUserName,
+		Changes: []PlannedChange{},
+	}
+	desiredTexts := map[string]bool{}
+	for _, d := range *desiredTweetList {
+		// The tweet of a Tweet being deleted shows up as a delete below
+		if d.Spec.Text == "" || d.Spec.Deleting {
+			continue
+		}
+		desiredTexts[normalizeText(d.Spec.Text)] = true
+		change := PlannedChange{
+			Namespace: d.Spec.Namespace,
+			Name:      d.Spec.Name,
+			Text:      d.Spec.Text,
+		}
+		actual, err := reconciler.find(ctx, snapshot, &d, tracked)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get tweets for user %s", reconciler.twitterUserName)
+		}
+		switch {
+		case actual == nil && d.Status.ID == 0:
+			change.Action = ActionCreate
+		case actual == nil:
+			change.Action = ActionOrphan
+			change.TweetID = d.Status.ID
+		case actual.Status.ID != d.Status.ID:
+			change.Action = ActionAdopt
+			change.TweetID = actual.Status.ID
+		default:
+			continue
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+	for _, t := range snapshot.tweets {
+		if desiredTexts[normalizeText(t.Spec.Text)] {
+			continue
+		}
+		plan.Changes = append(plan.Changes, PlannedChange{
+			Action:  ActionDelete,
+			TweetID: t.Status.ID,
+			Text:    t.Spec.Text,
+		})
+	}
+	return plan, nil
+}
+
+func (plan *Plan) Count(action Action) int {
+	count := 0
+	for _, change := range plan.Changes {
+		if change.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// WriteText renders the plan for humans, in the style of terraform plan.
+func (plan *Plan) WriteText(w io.Writer) error {
+	var b strings.Builder
+	timeline := "@" + plan.Account
+	if plan.Target != "" {
+		timeline += " (" + plan.Target + ")"
+	}
+	if len(plan.Changes) == 0 {
+		fmt.Fprintf(&b, "No changes. The Tweets in the cluster match the timeline of %s.\n", timeline)
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	fmt.Fprintf(&b, "The operator would make the following changes to the timeline of %s:\n\n", timeline)
+	for _, change := range plan.Changes {
+		resource := change.Namespace + "/" + change.Name
+		switch change.Action {
+		case ActionCreate:
+			fmt.Fprintf(&b, "  + create  %s\n", resource)
+		case ActionAdopt:
+			fmt.Fprintf(&b, "  ~ adopt   %s (tweet %d)\n", resource, change.TweetID)
+		case ActionDelete:
+			fmt.Fprintf(&b, "  - delete  tweet %d\n", change.TweetID)
+		case ActionOrphan:
+			fmt.Fprintf(&b, "  ! orphan  %s (tweet %d is gone, will be posted again)\n", resource, change.TweetID)
+		}
+		fmt.Fprintf(&b, "      text: %q\n", change.Text)
+	}
+	fmt.Fprintf(
+		&b,
+		"\nPlan: %d to create, %d to adopt, %d to delete, %d orphaned.\n",
+		plan.Count(ActionCreate),
+		plan.Count(ActionAdopt),
+		plan.Count(ActionDelete),
+		plan.Count(ActionOrphan),
+	)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
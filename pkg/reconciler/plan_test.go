@@ -0,0 +1,130 @@
+package reconciler
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Plan(t *testing.T) {
+	tests := map[string]struct {
+		k8sMock     *k8sClientMock
+		twitterMock *twitterClientMock
+		want        []PlannedChange
+	}{
+		"nothing to do": {
+			k8sMock: newK8sClientMock(
+				"ListTweets",
+				[]interface{}{},
+				&tweettypes.Tweets{*newTweet("hello-world", "Hello World", 1)},
+				nil,
+			),
+			twitterMock: newTwitterClientMock(
+				"GetTweetsForUser",
+				[]interface{}{"bob", []int64{1}},
+				tweettypes.Tweets{*newTweet("", "Hello World", 1)},
+				nil,
+			),
+			want: []PlannedChange{},
+		},
+		"one of each": {
+			k8sMock: newK8sClientMock(
+				"ListTweets",
+				[]interface{}{},
+				&tweettypes.Tweets{
+					*newTweet("good-morning", "Good morning", 0),
+					*newTweet("hello-world", "Hello World", 0),
+					*newTweet("gone", "Gone", 3),
+				},
+				nil,
+			),
+			twitterMock: newTwitterClientMock(
+				"GetTweetsForUser",
+				[]interface{}{"bob", []int64{3}},
+				tweettypes.Tweets{
+					*newTweet("", "Hello World", 1),
+					*newTweet("", "Unmanaged", 2),
+				},
+				nil,
+			).addMethod(
+				// Good morning is looked for past the oldest tracked tweet
+				"GetTweetsForUser",
+				[]interface{}{"bob", []int64(nil)},
+				tweettypes.Tweets{
+					*newTweet("", "Hello World", 1),
+					*newTweet("", "Unmanaged", 2),
+				},
+				nil,
+			),
+			want: []PlannedChange{
+				{Action: ActionCreate, Name: "good-morning", Text: "Good morning"},
+				{Action: ActionAdopt, Name: "hello-world", TweetID: 1, Text: "Hello World"},
+				{Action: ActionOrphan, Name: "gone", TweetID: 3, Text: "Gone"},
+				{Action: ActionDelete, TweetID: 2, Text: "Unmanaged"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reconciler := TweetReconciler{
+				k8sClient:       test.k8sMock,
+				twitterClient:   test.twitterMock,
+				twitterUserName: "bob",
+				now:             time.Now,
+			}
+			plan, err := reconciler.Plan(context.TODO())
+			assert.NoError(t, err)
+			assert.Equal(t, "bob", plan.Account)
+			assert.Equal(t, test.want, plan.Changes)
+			test.twitterMock.AssertNotCalled(t, "PostTweet")
+			test.twitterMock.AssertNotCalled(t, "DeleteTweet")
+		})
+	}
+}
+
+func Test_PlanWriteText(t *testing.T) {
+	tests := map[string]struct {
+		plan *Plan
+		want string
+	}{
+		"no changes": {
+			plan: &Plan{Account: "bob", Changes: []PlannedChange{}},
+			want: "No changes. The Tweets in the cluster match the timeline of @bob.\n",
+		},
+		"no changes on target": {
+			plan: &Plan{Target: "fediverse", Account: "bob", Changes: []PlannedChange{}},
+			want: "No changes. The Tweets in the cluster match the timeline of @bob (fediverse).\n",
+		},
+		"changes": {
+			plan: &Plan{
+				Account: "bob",
+				Changes: []PlannedChange{
+					{Action: ActionCreate, Namespace: "default", Name: "good-morning", Text: "Good morning"},
+					{Action: ActionDelete, TweetID: 2, Text: "Unmanaged"},
+				},
+			},
+			want: `The operator would make the following changes to the timeline of @bob:
+
+  + create  default/good-morning
+      text: "Good morning"
+  - delete  tweet 2
+      text: "Unmanaged"
+
+Plan: 1 to create, 0 to adopt, 1 to delete, 0 orphaned.
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			assert.NoError(t, test.plan.WriteText(&out))
+			assert.Equal(t, test.want, out.String())
+		})
+	}
+}
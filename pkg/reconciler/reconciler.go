@@ -1,68 +1,201 @@
 package reconciler
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"time"
 
+	"github.com/go-logr/logr"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/logging"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/twitterclient"
+	"github.com/jonatanblue/tweet-operator/pkg/policy"
 	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 )
 
 type K8sClient interface {
-	GetTweet(name string) (*tweettypes.Tweet, error)
-	UpdateStatus(name string, tweet *tweettypes.Tweet) (updated bool, err error)
-	ListTweets() (*tweettypes.Tweets, error)
+	GetTweet(ctx context.Context, name string) (*tweettypes.Tweet, error)
+	UpdateStatus(ctx context.Context, name string, tweet *tweettypes.Tweet) (updated bool, err error)
+	ListTweets(ctx context.Context) (*tweettypes.Tweets, error)
+	RemoveFinalizer(ctx context.Context, name string) error
+	SetCondition(ctx context.Context, name string, condition tweettypes.Condition) error
 }
 
 type TwitterClient interface {
-	GetTweetsForUser(userName string) (result tweettypes.Tweets, err error)
-	PostTweet(tweet *tweettypes.Tweet) error
-	DeleteTweet(tweet *tweettypes.Tweet) error
+	GetTweetsForUser(ctx context.Context, userName string, trackedIDs ...int64) (result tweettypes.Tweets, err error)
+	PostTweet(ctx context.Context, tweet *tweettypes.Tweet) (id int64, err error)
+	DeleteTweet(ctx context.Context, tweet *tweettypes.Tweet) error
 }
 
+// PolicyClient lists the TweetPolicies a Tweet has to comply with before
+// it is posted.
+type PolicyClient interface {
+	ListTweetPolicies(ctx context.Context) ([]tweettypes.TweetPolicy, error)
+}
+
+type EventRecorder interface {
+	Event(tweet *tweettypes.Tweet, eventType, reason, message string)
+}
+
+// Event reasons
+const (
+	ReasonPosted        = "Posted"
+	ReasonDeleted       = "Deleted"
+	ReasonMetricsSynced = "MetricsSynced"
+	ReasonAdopted       = "Adopted"
+	ReasonPostFailed    = "PostFailed"
+	ReasonDeleteFailed  = "DeleteFailed"
+	ReasonRateLimited   = "RateLimited"
+	ReasonDuplicate     = "Duplicate"
+	ReasonUnauthorized  = "Unauthorized"
+	ReasonSuspended     = "Suspended"
+	// ReasonPendingApproval is only recorded on the Ready condition, as it
+	// is set on every pass until the Tweet is approved
+	ReasonPendingApproval = "PendingApproval"
+	// ReasonPolicyViolation is also only recorded on the Ready condition,
+	// with the rule that was broken. Auto-replies record it as an event.
+	ReasonPolicyViolation = "PolicyViolation"
+)
+
+// ConditionReady reports whether the post on the target matches its Tweet.
+// The reason is that of the event recorded with it.
+const ConditionReady = "Ready"
+
+// Requeue delays for errors that are likely to go away, when the platform
+// does not say how long to wait
+const (
+	// rateLimitRequeue is the length of a Twitter rate limit window
+	rateLimitRequeue = 15 * time.Minute
+	transientRequeue = time.Minute
+)
+
 type TweetReconciler struct {
 	k8sClient       K8sClient
 	twitterClient   TwitterClient
+	recorder        EventRecorder
+	policyClient    PolicyClient
 	twitterUserName string
+	snapshotTTL     time.Duration
+	snapshot        *timelineSnapshot
+	now             func() time.Time
+	log             logr.Logger
+	// failed holds the text of Tweets that cannot be posted, by namespace
+	// and name. They are not tried again until their text changes.
+	failed map[string]string
 }
 
 func NewTweetReconciler(
 	k8sClient K8sClient,
 	twitterClient TwitterClient,
+	recorder EventRecorder,
+	policyClient PolicyClient,
 	twitterUserName string,
+	snapshotTTL time.Duration,
+	log logr.Logger,
 ) *TweetReconciler {
 	return &TweetReconciler{
 		k8sClient:       k8sClient,
 		twitterClient:   twitterClient,
+		recorder:        recorder,
+		policyClient:    policyClient,
 		twitterUserName: twitterUserName,
+		snapshotTTL:     snapshotTTL,
+		now:             time.Now,
+		log:             log.WithValues("account", twitterUserName),
+		failed:          map[string]string{},
+	}
+}
+
+// RequeueError asks the caller to run the reconciliation again after a
+// delay, instead of treating the error as fatal.
+type RequeueError struct {
+	After time.Duration
+	Err   error
+}
+
+func (e *RequeueError) Error() string {
+	return fmt.Sprintf("requeue after %s: %v", e.After, e.Err)
+}
+
+func (e *RequeueError) Unwrap() error {
+	return e.Err
+}
+
+func (reconciler *TweetReconciler) Reconcile(ctx context.Context) (bool, error) {
+	reconciled, err := reconciler.reconcile(ctx)
+	var rateLimitErr *twitterclient.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		after := rateLimitErr.Reset.Sub(reconciler.now())
+		reconciler.log.Info("Rate limited, requeueing", "endpoint", rateLimitErr.Endpoint, "after", after.String())
+		return false, &RequeueError{After: after, Err: err}
 	}
+	if after, ok := requeueAfter(err); ok {
+		reconciler.log.Info("Temporary failure, requeueing", "kind", twitterclient.KindOf(err), "after", after.String())
+		return false, &RequeueError{After: after, Err: err}
+	}
+	return reconciled, err
+}
+
+// requeueAfter returns how long to wait before trying again after an error
+// that may go away by itself.
+func requeueAfter(err error) (time.Duration, bool) {
+	switch twitterclient.KindOf(err) {
+	case twitterclient.KindRateLimited:
+		return rateLimitRequeue, true
+	case twitterclient.KindTransient:
+		return transientRequeue, true
+	}
+	return 0, false
 }
 
-func (reconciler *TweetReconciler) Reconcile() (bool, error) {
-	desiredTweetList, err := reconciler.k8sClient.ListTweets()
+func (reconciler *TweetReconciler) reconcile(ctx context.Context) (bool, error) {
+	desiredTweetList, err := reconciler.k8sClient.ListTweets(ctx)
 	if err != nil {
 		return false, errors.Wrapf(err, "failed to get tweet list from k8s")
 	}
-	log.Printf("Got tweets from k8s, %+v", desiredTweetList)
+	reconciler.log.V(logging.LevelDebug).Info("Got tweets from k8s", "count", len(*desiredTweetList))
+	trackedIDs := trackedIDs(*desiredTweetList)
 
 	for _, t := range *desiredTweetList {
-		log.Printf("Reconciling tweet, %+v", t)
-		desired, err := reconciler.getDesiredState(t.Spec.Name)
+		log := logging.WithTweet(reconciler.log, &t)
+		log.V(logging.LevelDebug).Info("Reconciling tweet")
+		desired, err := reconciler.getDesiredState(ctx, t.Spec.Name)
 		if err != nil {
 			return false, errors.Wrapf(err, "failed to get desired state for %s", t.Spec.Name)
 		}
-		log.Printf("Got desired state, %+v", desired)
+		log.V(logging.LevelDebug).Info("Got desired state", "text", logging.Text(log, desired.Spec.Text))
 
-		actual, err := reconciler.getActualState(desired.Spec.Text)
+		actual, err := reconciler.getActualState(ctx, desired, trackedIDs)
 		if err != nil {
 			return false, errors.Wrapf(err, "failed to get actual state for %s", t.Spec.Name)
 		}
 
-		// Name only exists in Kubernetes so patching this on here
+		// Namespace and Name only exist in Kubernetes so patching them on here
+		actual.Spec.Namespace = desired.Spec.Namespace
 		actual.Spec.Name = desired.Spec.Name
+		actual.Spec.Target = desired.Spec.Target
 
-		log.Printf("Got actual state, %+v", actual)
+		log.V(logging.LevelDebug).Info(
+			"Got actual state",
+			"actualID", actual.Status.ID,
+			"likes", actual.Status.Likes,
+			"retweets", actual.Status.Retweets,
+			"replies", actual.Status.Replies,
+		)
 
-		reconciled, err := reconciler.ReconcileOne(desired, actual)
+		if desired.Spec.Deleting {
+			finalized, err := reconciler.finalize(ctx, desired, actual)
+			if err != nil {
+				return false, errors.Wrapf(err, "failed to finalize %s", t.Spec.Name)
+			}
+			if !finalized {
+				return false, nil
+			}
+			continue
+		}
+
+		reconciled, err := reconciler.ReconcileOne(ctx, desired, actual)
 		if err != nil {
 			return false, errors.Wrapf(err, "failed to reconcile %s", t.Spec.Name)
 		}
@@ -72,42 +205,55 @@ func (reconciler *TweetReconciler) Reconcile() (bool, error) {
 		}
 
 		// Update custom resource with latest status
-		updated, err := reconciler.k8sClient.UpdateStatus(t.Spec.Name, actual)
+		updated, err := reconciler.k8sClient.UpdateStatus(ctx, t.Spec.Name, actual)
 		if err != nil {
 			return false, errors.Wrapf(err, "failed to update status for %s", t.Spec.Name)
 		}
 		if updated {
+			// A tweet other than the recorded one is adopted too, like when
+			// the recorded tweet is gone but another has the same text. The
+			// plan reports it the same way.
+			if actual.Status.ID != 0 && actual.Status.ID != desired.Status.ID {
+				log.Info("Adopted existing tweet", "actualID", actual.Status.ID)
+				reconciler.event(desired, corev1.EventTypeNormal, ReasonAdopted, fmt.Sprintf("Adopted existing tweet %d", actual.Status.ID))
+				reconciler.setReady(ctx, desired, true, ReasonAdopted, fmt.Sprintf("Adopted existing tweet %d", actual.Status.ID))
+			} else {
+				reconciler.event(desired, corev1.EventTypeNormal, ReasonMetricsSynced, fmt.Sprintf(
+					"Synced metrics: likes=%d retweets=%d replies=%d",
+					actual.Status.Likes,
+					actual.Status.Retweets,
+					actual.Status.Replies,
+				))
+			}
 			return false, nil
 		}
 	}
 
 	// Clean up deleted tweets
-	desiredTweetList, err = reconciler.k8sClient.ListTweets()
+	desiredTweetList, err = reconciler.k8sClient.ListTweets(ctx)
 	if err != nil {
 		return false, errors.Wrapf(err, "failed to get tweet list from k8s")
 	}
-	log.Printf("Got refreshed list of tweets from k8s, %+v", desiredTweetList)
+	reconciler.log.V(logging.LevelDebug).Info("Got refreshed list of tweets from k8s", "count", len(*desiredTweetList))
 
-	actualTweetList, err := reconciler.twitterClient.GetTweetsForUser(reconciler.twitterUserName)
+	snapshot, err := reconciler.getSnapshot(ctx, trackedIDs)
 	if err != nil {
 		return false, errors.Wrapf(err, "failed to get tweets for user %s", reconciler.twitterUserName)
 	}
-	log.Printf("Got tweets from twitter, %+v", actualTweetList)
 
-	for _, t := range actualTweetList {
-		found := false
-		for _, d := range *desiredTweetList {
-			// Compare the Text instead of the Name, because the Name is only in Kubernetes
-			if d.Spec.Text == t.Spec.Text {
-				found = true
-				break
-			}
+	// Compare the Text instead of the Name, because the Name is only in Kubernetes
+	desiredTexts := map[string]bool{}
+	for _, d := range *desiredTweetList {
+		if !d.Spec.Deleting {
+			desiredTexts[normalizeText(d.Spec.Text)] = true
 		}
-		if !found {
-			log.Printf("Deleting tweet, %+v", t)
-			err = reconciler.twitterClient.DeleteTweet(&t)
+	}
+	for _, t := range snapshot.tweets {
+		if !desiredTexts[normalizeText(t.Spec.Text)] {
+			logging.WithTweet(reconciler.log, &t).Info("Deleting unmanaged tweet", "text", logging.Text(reconciler.log, t.Spec.Text))
+			err = reconciler.deleteTweet(ctx, &t)
 			if err != nil {
-				return false, errors.Wrapf(err, "failed to delete tweet %s", t.Spec.Name)
+				return false, errors.Wrapf(err, "failed to delete tweet %d", t.Status.ID)
 			}
 			return false, nil
 		}
@@ -116,48 +262,254 @@ func (reconciler *TweetReconciler) Reconcile() (bool, error) {
 	return true, nil
 }
 
-func (reconciler *TweetReconciler) ReconcileOne(desired, actual *tweettypes.Tweet) (reconciled bool, err error) {
+func (reconciler *TweetReconciler) ReconcileOne(ctx context.Context, desired, actual *tweettypes.Tweet) (reconciled bool, err error) {
 	if desired.Spec.Text == "" {
 		if actual.Spec.Text != "" {
-			log.Printf("Deleting tweet with ID, %v", actual.Status.ID)
-			err := reconciler.twitterClient.DeleteTweet(actual)
+			logging.WithTweet(reconciler.log, actual).Info("Deleting tweet")
+			err := reconciler.deleteTweet(ctx, actual)
 			if err != nil {
+				reconciler.fail(ctx, desired, failureReason(err, ReasonDeleteFailed), err)
 				return false, errors.Wrap(err, "failed to delete tweet")
 			}
+			reconciler.event(desired, corev1.EventTypeNormal, ReasonDeleted, fmt.Sprintf("Deleted tweet %d", actual.Status.ID))
 			return false, nil
 		}
 	} else {
 		if actual.Spec.Text == "" {
-			err := reconciler.twitterClient.PostTweet(desired)
+			log := logging.WithTweet(reconciler.log, desired)
+			if reconciler.failed[failedKey(desired)] == desired.Spec.Text {
+				log.V(logging.LevelDebug).Info("Skipping tweet that cannot be posted")
+				return true, nil
+			}
+			violation, err := reconciler.checkPolicies(ctx, desired)
+			if err != nil {
+				return false, err
+			}
+			if violation != nil {
+				log.V(logging.LevelDebug).Info("Tweet violates policy", "policy", violation.Policy, "rule", violation.Rule)
+				reconciler.setReady(ctx, desired, false, ReasonPolicyViolation, violation.Error())
+				return true, nil
+			}
+			if len(desired.Spec.Approvals) < desired.Spec.RequiredApprovals {
+				// Other Tweets can go ahead meanwhile
+				log.V(logging.LevelDebug).Info("Waiting for approvals", "approvals", len(desired.Spec.Approvals))
+				reconciler.setReady(ctx, desired, false, ReasonPendingApproval, fmt.Sprintf(
+					"Waiting for approvals: %d of %d",
+					len(desired.Spec.Approvals),
+					desired.Spec.RequiredApprovals,
+				))
+				return true, nil
+			}
+			log.Info("Posting tweet", "text", logging.Text(log, desired.Spec.Text))
+			reconciler.invalidateSnapshot()
+			id, err := reconciler.twitterClient.PostTweet(ctx, desired)
 			if err != nil {
+				reconciler.fail(ctx, desired, failureReason(err, ReasonPostFailed), err)
+				if twitterclient.IsDuplicate(err) {
+					// Posting the same text again fails the same way, so
+					// the other Tweets go ahead
+					log.Info("Tweet cannot be posted until its text changes", "reason", ReasonDuplicate)
+					reconciler.markFailed(desired)
+					return true, nil
+				}
 				return false, err
 			}
+			log.Info("Posted tweet", "postedID", id)
+			reconciler.event(desired, corev1.EventTypeNormal, ReasonPosted, fmt.Sprintf("Posted tweet %d", id))
+
+			// Record the ID right away, so the next pass does not mistake
+			// the tweet for one that existed before
+			posted := *desired
+			posted.Status = tweettypes.TweetStatus{ID: id, ApprovedBy: desired.Spec.Approvals}
+			_, err = reconciler.k8sClient.UpdateStatus(ctx, desired.Spec.Name, &posted)
+			if err != nil {
+				return false, errors.Wrapf(err, "failed to record ID of posted tweet %d", id)
+			}
+			reconciler.setReady(ctx, desired, true, ReasonPosted, fmt.Sprintf("Posted tweet %d", id))
 			return false, nil
 		}
 	}
 	return true, nil
 }
 
-func (reconciler *TweetReconciler) getDesiredState(name string) (*tweettypes.Tweet, error) {
-	desired, err := reconciler.k8sClient.GetTweet(name)
+// deleteTweet deletes the post. One that is already gone counts as deleted.
+func (reconciler *TweetReconciler) deleteTweet(ctx context.Context, tweet *tweettypes.Tweet) error {
+	reconciler.invalidateSnapshot()
+	err := reconciler.twitterClient.DeleteTweet(ctx, tweet)
+	if twitterclient.IsNotFound(err) {
+		logging.WithTweet(reconciler.log, tweet).Info("Tweet already deleted", "actualID", tweet.Status.ID)
+		return nil
+	}
+	return err
+}
+
+func (reconciler *TweetReconciler) markFailed(tweet *tweettypes.Tweet) {
+	if reconciler.failed == nil {
+		reconciler.failed = map[string]string{}
+	}
+	reconciler.failed[failedKey(tweet)] = tweet.Spec.Text
+}
+
+func failedKey(tweet *tweettypes.Tweet) string {
+	return tweet.Spec.Namespace + "/" + tweet.Spec.Name
+}
+
+// checkPolicies returns the first TweetPolicy rule the Tweet breaks, if any.
+func (reconciler *TweetReconciler) checkPolicies(ctx context.Context, tweet *tweettypes.Tweet) (*policy.Violation, error) {
+	if reconciler.policyClient == nil {
+		return nil, nil
+	}
+	policies, err := reconciler.policyClient.ListTweetPolicies(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get tweet policies")
+	}
+	return policy.Check(policies, tweet.Spec.Namespace, tweet.Spec.Text), nil
+}
+
+// finalize deletes the tweet of a Tweet resource that is being deleted. Once
+// the tweet is gone, in a later pass, it removes the finalizer so Kubernetes
+// can let the resource go.
+func (reconciler *TweetReconciler) finalize(ctx context.Context, desired, actual *tweettypes.Tweet) (finalized bool, err error) {
+	log := logging.WithTweet(reconciler.log, desired)
+	if actual.Spec.Text != "" {
+		log.Info("Deleting tweet of deleted resource", "actualID", actual.Status.ID)
+		err := reconciler.deleteTweet(ctx, actual)
+		if err != nil {
+			reconciler.fail(ctx, desired, failureReason(err, ReasonDeleteFailed), err)
+			return false, errors.Wrap(err, "failed to delete tweet")
+		}
+		reconciler.event(desired, corev1.EventTypeNormal, ReasonDeleted, fmt.Sprintf("Deleted tweet %d", actual.Status.ID))
+		return false, nil
+	}
+	log.V(logging.LevelDebug).Info("Removing finalizer")
+	return true, reconciler.k8sClient.RemoveFinalizer(ctx, desired.Spec.Name)
+}
+
+func (reconciler *TweetReconciler) event(tweet *tweettypes.Tweet, eventType, reason, message string) {
+	if reconciler.recorder == nil {
+		return
+	}
+	reconciler.recorder.Event(tweet, eventType, reason, message)
+}
+
+// fail records a failed change to the post, both as an event and on the
+// target's status.
+func (reconciler *TweetReconciler) fail(ctx context.Context, tweet *tweettypes.Tweet, reason string, err error) {
+	reconciler.event(tweet, corev1.EventTypeWarning, reason, err.Error())
+	reconciler.setReady(ctx, tweet, false, reason, err.Error())
+}
+
+// setReady records the Ready condition. Failing to do so is only logged, so
+// it does not hide the outcome being recorded.
+func (reconciler *TweetReconciler) setReady(ctx context.Context, tweet *tweettypes.Tweet, ready bool, reason, message string) {
+	err := reconciler.k8sClient.SetCondition(ctx, tweet.Spec.Name, tweettypes.Condition{
+		Type:    ConditionReady,
+		Status:  ready,
+		Reason:  reason,
+		Message: message,
+	})
+	if err != nil {
+		logging.WithTweet(reconciler.log, tweet).Error(err, "Failed to set condition", "type", ConditionReady)
+	}
+}
+
+// failureReason refines the event reason for errors twitterclient
+// classifies, including those of the other backends, falling back to the
+// given reason otherwise.
+func failureReason(err error, fallback string) string {
+	switch {
+	case twitterclient.IsRateLimited(err):
+		return ReasonRateLimited
+	case twitterclient.IsDuplicate(err):
+		return ReasonDuplicate
+	case twitterclient.IsUnauthorized(err):
+		return ReasonUnauthorized
+	case twitterclient.IsSuspended(err):
+		return ReasonSuspended
+	}
+	return fallback
+}
+
+func (reconciler *TweetReconciler) getDesiredState(ctx context.Context, name string) (*tweettypes.Tweet, error) {
+	desired, err := reconciler.k8sClient.GetTweet(ctx, name)
 	if err != nil {
 		return nil, err
 	}
 	return desired, nil
 }
 
-func (reconciler *TweetReconciler) getActualState(text string) (*tweettypes.Tweet, error) {
-	log.Printf("Getting tweets for user %s...", reconciler.twitterUserName)
-	tweets, err := reconciler.twitterClient.GetTweetsForUser(reconciler.twitterUserName)
+func (reconciler *TweetReconciler) getActualState(ctx context.Context, desired *tweettypes.Tweet, trackedIDs []int64) (*tweettypes.Tweet, error) {
+	snapshot, err := reconciler.getSnapshot(ctx, trackedIDs)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get tweets")
 	}
 
-	for _, tweet := range tweets {
-		if tweet.Spec.Text == text {
-			return &tweet, nil
-		}
+	tweet, err := reconciler.find(ctx, snapshot, desired, trackedIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get tweets")
+	}
+	if tweet != nil {
+		actual := *tweet
+		return &actual, nil
 	}
 
 	return &tweettypes.Tweet{}, nil
 }
+
+// find returns the tweet on the timeline for a desired Tweet. Timeline reads
+// stop at the oldest tracked ID, but a tweet to adopt usually existed before
+// the operator posted anything, so for a Tweet with no recorded ID the
+// newest page is read once more without that bound.
+func (reconciler *TweetReconciler) find(ctx context.Context, snapshot *timelineSnapshot, desired *tweettypes.Tweet, trackedIDs []int64) (*tweettypes.Tweet, error) {
+	if tweet := snapshot.find(desired); tweet != nil {
+		return tweet, nil
+	}
+	if desired.Status.ID != 0 || desired.Spec.Text == "" || len(trackedIDs) == 0 || snapshot.older != nil {
+		return nil, nil
+	}
+
+	reconciler.log.V(logging.LevelDebug).Info("Getting newest tweets for user to adopt from")
+	tweets, err := reconciler.twitterClient.GetTweetsForUser(ctx, reconciler.twitterUserName)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.addOlder(tweets)
+	return snapshot.find(desired), nil
+}
+
+// getSnapshot returns the cached timeline, fetching it again only when it
+// is missing or stale.
+func (reconciler *TweetReconciler) getSnapshot(ctx context.Context, trackedIDs []int64) (*timelineSnapshot, error) {
+	now := reconciler.now()
+	if reconciler.snapshot != nil && !reconciler.snapshot.stale(now, reconciler.snapshotTTL) {
+		return reconciler.snapshot, nil
+	}
+
+	reconciler.log.V(logging.LevelDebug).Info("Getting tweets for user")
+	tweets, err := reconciler.twitterClient.GetTweetsForUser(ctx, reconciler.twitterUserName, trackedIDs...)
+	if err != nil {
+		return nil, err
+	}
+	reconciler.log.V(logging.LevelDebug).Info("Got tweets from twitter", "count", len(tweets))
+	reconciler.snapshot = newTimelineSnapshot(tweets, now)
+	return reconciler.snapshot, nil
+}
+
+// invalidateSnapshot must be called before every change to the timeline.
+// It is called before rather than after, so a change that fails halfway
+// still forces a fresh read.
+func (reconciler *TweetReconciler) invalidateSnapshot() {
+	reconciler.snapshot = nil
+}
+
+// trackedIDs returns the IDs of the tweets already recorded in Kubernetes,
+// so the timeline can be paged back far enough to find them all.
+func trackedIDs(tweets tweettypes.Tweets) []int64 {
+	ids := []int64{}
+	for _, t := range tweets {
+		if t.Status.ID != 0 {
+			ids = append(ids, t.Status.ID)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,452 @@
+package reconciler
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	v1 "github.com/jonatanblue/tweet-operator/pkg/apis/example.com/v1"
+	"github.com/jonatanblue/tweet-operator/pkg/client/clientset/versioned/fake"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/blueskyclient"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/k8sclient"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/mastodonclient"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/twitterclient"
+	"github.com/jonatanblue/tweet-operator/pkg/testing/fakebluesky"
+	"github.com/jonatanblue/tweet-operator/pkg/testing/fakemastodon"
+	"github.com/jonatanblue/tweet-operator/pkg/testing/faketwitter"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const maxTestPasses = 20
+
+var twitterClients = map[string]func(*http.Client) TwitterClient{
+	"v1.1": func(httpClient *http.Client) TwitterClient {
+		goTwitterClient := twitterclient.NewGoTwitterClient(httpClient)
+		return twitterclient.NewTwitterClient(goTwitterClient, goTwitterClient, twitterclient.DefaultTimelineMaxPages, 0)
+	},
+	"v2": func(httpClient *http.Client) TwitterClient {
+		return twitterclient.NewTwitterV2Client(httpClient, twitterclient.DefaultV2BaseURL, twitterclient.DefaultTimelineMaxPages, 0, false)
+	},
+}
+
+func newTweetObject(name, text string) *v1.Tweet {
+	return &v1.Tweet{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       v1.TweetSpec{Text: text},
+	}
+}
+
+// reconcileUntilDone runs passes like the main loop does, until one
+// reports the state as reconciled.
+func reconcileUntilDone(t *testing.T, reconciler *TweetReconciler) {
+	for pass := 0; pass < maxTestPasses; pass++ {
+		reconciled, err := reconciler.Reconcile(context.TODO())
+		if !assert.NoError(t, err) || reconciled {
+			return
+		}
+	}
+	t.Fatalf("not reconciled after %d passes", maxTestPasses)
+}
+
+// targetStatus returns the status of a Tweet on a target, without its
+// conditions and history, whose times differ from run to run.
+func targetStatus(t *testing.T, tweets tweetGetter, name, target string) v1.TargetStatus {
+	status := targetStatusWithConditions(t, tweets, name, target)
+	status.Conditions = nil
+	status.History = nil
+	return status
+}
+
+func targetStatusWithConditions(t *testing.T, tweets tweetGetter, name, target string) v1.TargetStatus {
+	tweet, err := tweets.Get(context.TODO(), name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	for _, status := range tweet.Status.Targets {
+		if status.Name == target {
+			return status
+		}
+	}
+	return v1.TargetStatus{}
+}
+
+func mustParseInt(t *testing.T, s string) int64 {
+	i, err := strconv.ParseInt(s, 10, 64)
+	assert.NoError(t, err)
+	return i
+}
+
+type tweetGetter interface {
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Tweet, error)
+}
+
+func timelineTexts(server *faketwitter.Server) []string {
+	texts := []string{}
+	for _, t := range server.Tweets() {
+		texts = append(texts, t.Text)
+	}
+	sort.Strings(texts)
+	return texts
+}
+
+func Test_ReconcileOverHTTP(t *testing.T) {
+	for version, newTwitterClient := range twitterClients {
+		t.Run(version, func(t *testing.T) {
+			server := faketwitter.New("bob", faketwitter.Credentials{
+				ConsumerKey:       "consumer-key",
+				ConsumerSecret:    "consumer-secret",
+				AccessToken:       "access-token",
+				AccessTokenSecret: "access-token-secret",
+			})
+			defer server.Close()
+			adoptedID := server.AddTweet("Hello World")
+			server.AddTweet("Unmanaged")
+
+			tweets := fake.NewSimpleClientset(
+				newTweetObject("hello-world", "Hello World"),
+				newTweetObject("see-you", "See you"),
+			).ExampleV1().Tweets("default")
+			reconciler := NewTweetReconciler(
+				k8sclient.NewK8sClient(tweets),
+				newTwitterClient(server.OAuth1Client()),
+				nil,
+				nil,
+				"bob",
+				0,
+				logr.Discard(),
+			)
+
+			// Posts the missing tweet, adopts the existing one and deletes
+			// the unmanaged one
+			reconcileUntilDone(t, reconciler)
+			assert.Equal(t, []string{"Hello World", "See you"}, timelineTexts(server))
+			helloWorld, err := tweets.Get(context.TODO(), "hello-world", metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.Equal(t, adoptedID, targetStatus(t, tweets, "hello-world", k8sclient.DefaultTarget).ID)
+			assert.Equal(t, []string{k8sclient.Finalizer}, helloWorld.Finalizers)
+
+			// Syncs metrics
+			server.SetMetrics(adoptedID, 5, 6, 7)
+			reconcileUntilDone(t, reconciler)
+			assert.Equal(t, v1.TargetStatus{
+				Name:     k8sclient.DefaultTarget,
+				ID:       adoptedID,
+				URL:      twitterclient.TweetURL("bob", adoptedID),
+				Likes:    5,
+				Retweets: 6,
+				Replies:  7,
+				// Counted from the snapshot taken on adoption
+				LikesLastHour:       5,
+				RetweetsLastHour:    6,
+				PeakLikesPerHour:    5,
+				PeakRetweetsPerHour: 6,
+			}, targetStatus(t, tweets, "hello-world", k8sclient.DefaultTarget))
+			helloWorld, err = tweets.Get(context.TODO(), "hello-world", metav1.GetOptions{})
+			assert.NoError(t, err)
+
+			// Deletes the tweet of a deleted resource
+			err = tweets.Delete(context.TODO(), "see-you", metav1.DeleteOptions{})
+			assert.NoError(t, err)
+			reconcileUntilDone(t, reconciler)
+			assert.Equal(t, []string{"Hello World"}, timelineTexts(server))
+
+			// Deletes the tweet of a resource held by the finalizer, then
+			// releases it. The fake clientset does not act on finalizers, so
+			// the deletion is marked by hand.
+			deletedAt := metav1.Now()
+			helloWorld.DeletionTimestamp = &deletedAt
+			_, err = tweets.Update(context.TODO(), helloWorld, metav1.UpdateOptions{})
+			assert.NoError(t, err)
+			reconcileUntilDone(t, reconciler)
+			assert.Equal(t, []string{}, timelineTexts(server))
+			helloWorld, err = tweets.Get(context.TODO(), "hello-world", metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.Empty(t, helloWorld.Finalizers)
+		})
+	}
+}
+
+func Test_ReconcileOverHTTPRateLimited(t *testing.T) {
+	server := faketwitter.New("bob", faketwitter.Credentials{
+		ConsumerKey:       "consumer-key",
+		ConsumerSecret:    "consumer-secret",
+		AccessToken:       "access-token",
+		AccessTokenSecret: "access-token-secret",
+	})
+	defer server.Close()
+	server.InjectFault(faketwitter.EndpointStatusesUpdate, faketwitter.Fault{StatusCode: http.StatusTooManyRequests, Code: 88})
+
+	tweets := fake.NewSimpleClientset(newTweetObject("hello-world", "Hello World")).ExampleV1().Tweets("default")
+	reconciler := NewTweetReconciler(
+		k8sclient.NewK8sClient(tweets),
+		twitterClients["v1.1"](server.OAuth1Client()),
+		nil,
+		nil,
+		"bob",
+		0,
+		logr.Discard(),
+	)
+
+	_, err := reconciler.Reconcile(context.TODO())
+	var requeue *RequeueError
+	assert.ErrorAs(t, err, &requeue)
+	assert.Empty(t, server.Tweets())
+
+	reconcileUntilDone(t, reconciler)
+	assert.Equal(t, []string{"Hello World"}, timelineTexts(server))
+}
+
+func Test_ReconcileOverHTTPMastodon(t *testing.T) {
+	server := fakemastodon.New("bob", "token")
+	defer server.Close()
+	adopted := server.AddStatus("Hello World")
+	server.AddStatus("Unmanaged")
+
+	tweets := fake.NewSimpleClientset(
+		newTweetObject("hello-world", "Hello World"),
+		newTweetObject("good-morning", "Good morning"),
+	).ExampleV1().Tweets("default")
+	reconciler := NewTweetReconciler(
+		k8sclient.NewK8sClient(tweets).ForTarget("fediverse", true),
+		mastodonclient.NewMastodonClient(http.DefaultClient, server.URL, "token", twitterclient.DefaultTimelineMaxPages),
+		nil,
+		nil,
+		"bob",
+		0,
+		logr.Discard(),
+	)
+
+	reconcileUntilDone(t, reconciler)
+	texts := []string{}
+	for _, status := range server.Statuses() {
+		texts = append(texts, status.Text)
+	}
+	assert.ElementsMatch(t, []string{"Good morning", "Hello World"}, texts)
+
+	server.SetCounts(adopted, 5, 6, 7)
+	reconcileUntilDone(t, reconciler)
+	assert.Equal(t, v1.TargetStatus{
+		Name:     "fediverse",
+		ID:       mustParseInt(t, adopted),
+		URL:      "https://mastodon.example/@bob/" + adopted,
+		Likes:    5,
+		Retweets: 6,
+		Replies:  7,
+		// Counted from the snapshot taken on adoption
+		LikesLastHour:       5,
+		RetweetsLastHour:    6,
+		PeakLikesPerHour:    5,
+		PeakRetweetsPerHour: 6,
+	}, targetStatus(t, tweets, "hello-world", "fediverse"))
+}
+
+func Test_ReconcileOverHTTPMastodonEdited(t *testing.T) {
+	server := fakemastodon.New("bob", "token")
+	defer server.Close()
+
+	tweet := newTweetObject("hello-world", "Hello World")
+	tweet.UID = "1234"
+	tweets := fake.NewSimpleClientset(tweet).ExampleV1().Tweets("default")
+	reconciler := NewTweetReconciler(
+		k8sclient.NewK8sClient(tweets).ForTarget("fediverse", true),
+		mastodonclient.NewMastodonClient(http.DefaultClient, server.URL, "token", twitterclient.DefaultTimelineMaxPages),
+		nil,
+		nil,
+		"bob",
+		0,
+		logr.Discard(),
+	)
+	reconcileUntilDone(t, reconciler)
+	first := targetStatus(t, tweets, "hello-world", "fediverse").ID
+
+	// Edited well within the hour the first post's idempotency key is kept
+	edited, err := tweets.Get(context.TODO(), "hello-world", metav1.GetOptions{})
+	assert.NoError(t, err)
+	edited.Spec.Text = "Hello again"
+	_, err = tweets.Update(context.TODO(), edited, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	reconcileUntilDone(t, reconciler)
+
+	statuses := server.Statuses()
+	if assert.Len(t, statuses, 1) {
+		assert.Equal(t, "Hello again", statuses[0].Text)
+		assert.Equal(t, mustParseInt(t, statuses[0].ID), targetStatus(t, tweets, "hello-world", "fediverse").ID)
+	}
+	assert.NotEqual(t, first, targetStatus(t, tweets, "hello-world", "fediverse").ID)
+}
+
+func Test_ReconcileOverHTTPBluesky(t *testing.T) {
+	server := fakebluesky.New("bob.example.com", "app-password")
+	defer server.Close()
+	adopted := server.AddPost("Hello World")
+	server.AddPost("Unmanaged")
+
+	tweets := fake.NewSimpleClientset(
+		newTweetObject("hello-world", "Hello World"),
+		newTweetObject("good-morning", "Good morning"),
+	).ExampleV1().Tweets("default")
+	reconciler := NewTweetReconciler(
+		k8sclient.NewK8sClient(tweets).ForTarget("bluesky", true),
+		blueskyclient.NewBlueskyClient(http.DefaultClient, server.URL, "bob.example.com", "app-password", twitterclient.DefaultTimelineMaxPages),
+		nil,
+		nil,
+		"bob.example.com",
+		0,
+		logr.Discard(),
+	)
+
+	reconcileUntilDone(t, reconciler)
+	uris := map[string]string{}
+	for _, post := range server.Posts() {
+		uris[post.Text] = post.URI
+	}
+	assert.Equal(t, map[string]string{"Hello World": adopted, "Good morning": uris["Good morning"]}, uris)
+
+	// The record URI is recorded next to the ID
+	server.SetCounts(adopted, 5, 6, 7)
+	reconcileUntilDone(t, reconciler)
+	for name, text := range map[string]string{"hello-world": "Hello World", "good-morning": "Good morning"} {
+		status := targetStatus(t, tweets, name, "bluesky")
+		assert.Equal(t, uris[text], status.RemoteID)
+		assert.NotZero(t, status.ID)
+	}
+	assert.Equal(t, int64(5), targetStatus(t, tweets, "hello-world", "bluesky").Likes)
+
+	err := tweets.Delete(context.TODO(), "good-morning", metav1.DeleteOptions{})
+	assert.NoError(t, err)
+	reconcileUntilDone(t, reconciler)
+	assert.Len(t, server.Posts(), 1)
+}
+
+func Test_ReconcileOverHTTPCrossPosted(t *testing.T) {
+	twitterServer := faketwitter.New("bob", faketwitter.Credentials{
+		ConsumerKey:       "consumer-key",
+		ConsumerSecret:    "consumer-secret",
+		AccessToken:       "access-token",
+		AccessTokenSecret: "access-token-secret",
+	})
+	defer twitterServer.Close()
+	mastodonServer := fakemastodon.New("bob", "token")
+	defer mastodonServer.Close()
+	mastodonServer.InjectFault(fakemastodon.EndpointStatusesCreate, fakemastodon.Fault{StatusCode: http.StatusUnauthorized, Message: "The access token is invalid"})
+
+	tweet := newTweetObject("hello-world", "Hello World")
+	tweet.Spec.Targets = []string{k8sclient.DefaultTarget, "fediverse"}
+	tweets := fake.NewSimpleClientset(tweet).ExampleV1().Tweets("default")
+	k8sClient := k8sclient.NewK8sClient(tweets)
+	twitterReconciler := NewTweetReconciler(
+		k8sClient,
+		twitterClients["v1.1"](twitterServer.OAuth1Client()),
+		nil,
+		nil,
+		"bob",
+		0,
+		logr.Discard(),
+	)
+	mastodonReconciler := NewTweetReconciler(
+		k8sClient.ForTarget("fediverse", false),
+		mastodonclient.NewMastodonClient(http.DefaultClient, mastodonServer.URL, "token", twitterclient.DefaultTimelineMaxPages),
+		nil,
+		nil,
+		"bob",
+		0,
+		logr.Discard(),
+	)
+
+	// Failing on one target does not hold up the other
+	_, err := mastodonReconciler.Reconcile(context.TODO())
+	assert.True(t, mastodonclient.IsUnauthorized(err), "unauthorized: %v", err)
+	reconcileUntilDone(t, twitterReconciler)
+	assert.Equal(t, []string{"Hello World"}, timelineTexts(twitterServer))
+	assert.NotZero(t, targetStatus(t, tweets, "hello-world", k8sclient.DefaultTarget).ID)
+	failed := targetStatusWithConditions(t, tweets, "hello-world", "fediverse")
+	assert.Zero(t, failed.ID)
+	assert.Equal(t, metav1.ConditionFalse, failed.Conditions[0].Status)
+	assert.Equal(t, ReasonUnauthorized, failed.Conditions[0].Reason)
+
+	reconcileUntilDone(t, mastodonReconciler)
+	assert.Len(t, mastodonServer.Statuses(), 1)
+	recovered := targetStatusWithConditions(t, tweets, "hello-world", "fediverse")
+	assert.NotZero(t, recovered.ID)
+	assert.Equal(t, metav1.ConditionTrue, recovered.Conditions[0].Status)
+
+	// Dropping a target deletes the post there only
+	posted, err := tweets.Get(context.TODO(), "hello-world", metav1.GetOptions{})
+	assert.NoError(t, err)
+	posted.Spec.Targets = []string{k8sclient.DefaultTarget}
+	_, err = tweets.Update(context.TODO(), posted, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	reconcileUntilDone(t, mastodonReconciler)
+	reconcileUntilDone(t, twitterReconciler)
+	assert.Empty(t, mastodonServer.Statuses())
+	assert.Equal(t, []string{"Hello World"}, timelineTexts(twitterServer))
+	retargeted, err := tweets.Get(context.TODO(), "hello-world", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, retargeted.Status.Targets, 1)
+}
+
+func Test_ReconcileOverHTTPErrorKinds(t *testing.T) {
+	tests := map[string]func() (client TwitterClient, userName string, failNextPost, failNextDelete func(statusCode int), close func()){
+		"mastodon": func() (TwitterClient, string, func(int), func(int), func()) {
+			server := fakemastodon.New("bob", "token")
+			fail := func(endpoint string) func(int) {
+				return func(statusCode int) {
+					server.InjectFault(endpoint, fakemastodon.Fault{StatusCode: statusCode, Message: http.StatusText(statusCode)})
+				}
+			}
+			client := mastodonclient.NewMastodonClient(http.DefaultClient, server.URL, "token", twitterclient.DefaultTimelineMaxPages)
+			return client, "bob", fail(fakemastodon.EndpointStatusesCreate), fail(fakemastodon.EndpointStatusesDelete), server.Close
+		},
+		"bluesky": func() (TwitterClient, string, func(int), func(int), func()) {
+			server := fakebluesky.New("bob.example.com", "app-password")
+			fail := func(endpoint string) func(int) {
+				return func(statusCode int) {
+					server.InjectFault(endpoint, fakebluesky.Fault{StatusCode: statusCode, Error: strings.ReplaceAll(http.StatusText(statusCode), " ", ""), Message: http.StatusText(statusCode)})
+				}
+			}
+			client := blueskyclient.NewBlueskyClient(http.DefaultClient, server.URL, "bob.example.com", "app-password", twitterclient.DefaultTimelineMaxPages)
+			return client, "bob.example.com", fail(fakebluesky.EndpointCreateRecord), fail(fakebluesky.EndpointDeleteRecord), server.Close
+		},
+	}
+
+	for name, newServer := range tests {
+		t.Run(name, func(t *testing.T) {
+			twitterClient, userName, failNextPost, failNextDelete, close := newServer()
+			defer close()
+			tweets := fake.NewSimpleClientset(newTweetObject("hello-world", "Hello World")).ExampleV1().Tweets("default")
+			reconciler := NewTweetReconciler(
+				k8sclient.NewK8sClient(tweets).ForTarget(name, true),
+				twitterClient,
+				nil,
+				nil,
+				userName,
+				0,
+				logr.Discard(),
+			)
+
+			// An unavailable server is tried again later, like Twitter
+			failNextPost(http.StatusServiceUnavailable)
+			_, err := reconciler.Reconcile(context.TODO())
+			var requeue *RequeueError
+			assert.ErrorAs(t, err, &requeue)
+			reconcileUntilDone(t, reconciler)
+			assert.NotZero(t, targetStatus(t, tweets, "hello-world", name).ID)
+
+			// A post that is already gone counts as deleted
+			failNextDelete(http.StatusNotFound)
+			helloWorld, err := tweets.Get(context.TODO(), "hello-world", metav1.GetOptions{})
+			assert.NoError(t, err)
+			deletedAt := metav1.Now()
+			helloWorld.DeletionTimestamp = &deletedAt
+			_, err = tweets.Update(context.TODO(), helloWorld, metav1.UpdateOptions{})
+			assert.NoError(t, err)
+			reconcileUntilDone(t, reconciler)
+			helloWorld, err = tweets.Get(context.TODO(), "hello-world", metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.Empty(t, helloWorld.Finalizers)
+		})
+	}
+}
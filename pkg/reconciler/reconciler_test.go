@@ -1,8 +1,13 @@
 package reconciler
 
 import (
+	"context"
 	"testing"
+	"time"
 
+	"github.com/dghubble/go-twitter/twitter"
+	"github.com/go-logr/logr"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/twitterclient"
 	"github.com/pkg/errors"
 
 	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
@@ -48,7 +53,7 @@ func Test_ReconcileDeleteTweet(t *testing.T) {
 			),
 			twitterMock: newTwitterClientMock(
 				"GetTweetsForUser",
-				"bob",
+				[]interface{}{"bob", []int64{1}},
 				tweettypes.Tweets{*newTweet("", "Hello World", 1)},
 				nil,
 			),
@@ -70,7 +75,7 @@ func Test_ReconcileDeleteTweet(t *testing.T) {
 			),
 			twitterMock: newTwitterClientMock(
 				"GetTweetsForUser",
-				"bob",
+				[]interface{}{"bob", []int64{}},
 				tweettypes.Tweets{*newTweet("", "Hello World", 1)},
 				nil,
 			).addMethod(
@@ -83,12 +88,39 @@ func Test_ReconcileDeleteTweet(t *testing.T) {
 			reconciled: false,
 			err:        nil,
 		},
+		"tweet already deleted counts as deleted": {
+			k8sMock: newK8sClientMock(
+				"ListTweets",
+				[]interface{}{},
+				&tweettypes.Tweets{},
+				nil,
+			).addMethod(
+				"ListTweets",
+				[]interface{}{},
+				&tweettypes.Tweets{},
+				nil,
+			),
+			twitterMock: newTwitterClientMock(
+				"GetTweetsForUser",
+				[]interface{}{"bob", []int64{}},
+				tweettypes.Tweets{*newTweet("", "Hello World", 1)},
+				nil,
+			).addMethod(
+				"DeleteTweet",
+				[]interface{}{newTweet("", "Hello World", 1)},
+				nil,
+				twitter.APIError{Errors: []twitter.ErrorDetail{{Code: 144, Message: "No status found with that ID."}}},
+			),
+			username:   "bob",
+			reconciled: false,
+			err:        nil,
+		},
 	}
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			reconciler := NewTweetReconciler(test.k8sMock, test.twitterMock, test.username)
-			reconciled, err := reconciler.Reconcile()
+			reconciler := NewTweetReconciler(test.k8sMock, test.twitterMock, nil, nil, test.username, DefaultSnapshotTTL, logr.Discard())
+			reconciled, err := reconciler.Reconcile(context.TODO())
 			if err != nil {
 				assert.EqualError(t, test.err, err.Error())
 			}
@@ -99,6 +131,214 @@ func Test_ReconcileDeleteTweet(t *testing.T) {
 	}
 }
 
+func Test_ReconcileEvents(t *testing.T) {
+	tests := map[string]struct {
+		k8sMock     *k8sClientMock
+		twitterMock *twitterClientMock
+		events      []string
+	}{
+		"existing tweet adopted": {
+			k8sMock: newK8sClientMock(
+				"ListTweets",
+				[]interface{}{},
+				&tweettypes.Tweets{*newTweet("hello-world", "Hello World", 0)},
+				nil,
+			).addMethod(
+				"GetTweet",
+				[]interface{}{"hello-world"},
+				newTweet("hello-world", "Hello World", 0),
+				nil,
+			).addMethod(
+				"UpdateStatus",
+				[]interface{}{newTweet("hello-world", "Hello World", 1)},
+				true,
+				nil,
+			).addMethod(
+				"SetCondition",
+				[]interface{}{"hello-world", tweettypes.Condition{
+					Type:    ConditionReady,
+					Status:  true,
+					Reason:  ReasonAdopted,
+					Message: "Adopted existing tweet 1",
+				}},
+				nil,
+				nil,
+			),
+			twitterMock: newTwitterClientMock(
+				"GetTweetsForUser",
+				[]interface{}{"bob", []int64{}},
+				tweettypes.Tweets{*newTweet("", "Hello World", 1)},
+				nil,
+			),
+			events: []string{"Normal Adopted Adopted existing tweet 1"},
+		},
+		"tweet other than the recorded one adopted": {
+			k8sMock: newK8sClientMock(
+				"ListTweets",
+				[]interface{}{},
+				&tweettypes.Tweets{*newTweet("hello-world", "Hello World", 1)},
+				nil,
+			).addMethod(
+				"GetTweet",
+				[]interface{}{"hello-world"},
+				newTweet("hello-world", "Hello World", 1),
+				nil,
+			).addMethod(
+				"UpdateStatus",
+				[]interface{}{newTweet("hello-world", "Hello World", 2)},
+				true,
+				nil,
+			).addMethod(
+				"SetCondition",
+				[]interface{}{"hello-world", tweettypes.Condition{
+					Type:    ConditionReady,
+					Status:  true,
+					Reason:  ReasonAdopted,
+					Message: "Adopted existing tweet 2",
+				}},
+				nil,
+				nil,
+			),
+			twitterMock: newTwitterClientMock(
+				"GetTweetsForUser",
+				[]interface{}{"bob", []int64{1}},
+				tweettypes.Tweets{*newTweet("", "Hello World", 2)},
+				nil,
+			),
+			events: []string{"Normal Adopted Adopted existing tweet 2"},
+		},
+		"metrics synced": {
+			k8sMock: newK8sClientMock(
+				"ListTweets",
+				[]interface{}{},
+				&tweettypes.Tweets{*newTweet("hello-world", "Hello World", 1)},
+				nil,
+			).addMethod(
+				"GetTweet",
+				[]interface{}{"hello-world"},
+				newTweet("hello-world", "Hello World", 1),
+				nil,
+			).addMethod(
+				"UpdateStatus",
+				[]interface{}{newTweet("hello-world", "Hello World", 1)},
+				true,
+				nil,
+			),
+			twitterMock: newTwitterClientMock(
+				"GetTweetsForUser",
+				[]interface{}{"bob", []int64{1}},
+				tweettypes.Tweets{*newTweet("", "Hello World", 1)},
+				nil,
+			),
+			events: []string{"Normal MetricsSynced Synced metrics: likes=0 retweets=0 replies=0"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := &eventRecorderMock{}
+			reconciler := NewTweetReconciler(test.k8sMock, test.twitterMock, recorder, nil, "bob", DefaultSnapshotTTL, logr.Discard())
+			reconciled, err := reconciler.Reconcile(context.TODO())
+			assert.NoError(t, err)
+			assert.False(t, reconciled)
+			assert.Equal(t, test.events, recorder.events)
+		})
+	}
+}
+
+func Test_ReconcileRateLimited(t *testing.T) {
+	now := time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+	rateLimitErr := &twitterclient.RateLimitError{
+		Endpoint: "statuses/user_timeline",
+		Reset:    now.Add(5 * time.Minute),
+	}
+	k8sMock := newK8sClientMock(
+		"ListTweets",
+		[]interface{}{},
+		&tweettypes.Tweets{*newTweet("hello-world", "Hello World", 1)},
+		nil,
+	).addMethod(
+		"GetTweet",
+		[]interface{}{"hello-world"},
+		newTweet("hello-world", "Hello World", 1),
+		nil,
+	)
+	twitterMock := newTwitterClientMock(
+		"GetTweetsForUser",
+		[]interface{}{"bob", []int64{1}},
+		nil,
+		rateLimitErr,
+	)
+	reconciler := NewTweetReconciler(k8sMock, twitterMock, nil, nil, "bob", DefaultSnapshotTTL, logr.Discard())
+	reconciler.now = func() time.Time { return now }
+
+	reconciled, err := reconciler.Reconcile(context.TODO())
+	assert.False(t, reconciled)
+	var requeue *RequeueError
+	if assert.True(t, errors.As(err, &requeue)) {
+		assert.Equal(t, 5*time.Minute, requeue.After)
+		assert.True(t, errors.Is(err, rateLimitErr))
+	}
+}
+
+func Test_ReconcileRequeue(t *testing.T) {
+	tests := map[string]struct {
+		err   error
+		after time.Duration
+	}{
+		"transient": {
+			err:   &twitterclient.Error{Kind: twitterclient.KindTransient, StatusCode: 503, Err: errors.New("twitter: 503 Service Unavailable")},
+			after: transientRequeue,
+		},
+		"over daily post limit": {
+			err:   twitter.APIError{Errors: []twitter.ErrorDetail{{Code: 185, Message: "User is over daily status update limit."}}},
+			after: rateLimitRequeue,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			k8sMock := newK8sClientMock(
+				"ListTweets",
+				[]interface{}{},
+				&tweettypes.Tweets{*newTweet("hello-world", "Hello World", 1)},
+				nil,
+			).addMethod(
+				"GetTweet",
+				[]interface{}{"hello-world"},
+				newTweet("hello-world", "Hello World", 1),
+				nil,
+			)
+			twitterMock := newTwitterClientMock("GetTweetsForUser", []interface{}{"bob", []int64{1}}, nil, test.err)
+			reconciler := NewTweetReconciler(k8sMock, twitterMock, nil, nil, "bob", DefaultSnapshotTTL, logr.Discard())
+
+			_, err := reconciler.Reconcile(context.TODO())
+			var requeue *RequeueError
+			if assert.True(t, errors.As(err, &requeue)) {
+				assert.Equal(t, test.after, requeue.After)
+			}
+		})
+	}
+}
+
+func Test_ReconcileOneDuplicateNotRetried(t *testing.T) {
+	k8sMock := newK8sClientMock("SetCondition", []interface{}{"hello-world", mock.Anything}, nil, nil)
+	twitterMock := newTwitterClientMock("PostTweet", []interface{}{mock.Anything}, nil, duplicateError)
+	reconciler := NewTweetReconciler(k8sMock, twitterMock, nil, nil, "bob", DefaultSnapshotTTL, logr.Discard())
+
+	for i := 0; i < 2; i++ {
+		reconciled, err := reconciler.ReconcileOne(context.TODO(), newTweet("hello-world", "Hello World", 0), &tweettypes.Tweet{})
+		assert.NoError(t, err)
+		assert.True(t, reconciled)
+	}
+	twitterMock.AssertNumberOfCalls(t, "PostTweet", 1)
+
+	// A new text is tried
+	_, err := reconciler.ReconcileOne(context.TODO(), newTweet("hello-world", "Hello again", 0), &tweettypes.Tweet{})
+	assert.NoError(t, err)
+	twitterMock.AssertNumberOfCalls(t, "PostTweet", 2)
+}
+
 func Test_ReconcileOne(t *testing.T) {
 	tests := map[string]struct {
 		reconciler TweetReconciler
@@ -107,6 +347,7 @@ func Test_ReconcileOne(t *testing.T) {
 		reconciled bool
 		method     string
 		calls      int
+		events     []string
 		err        error
 	}{
 		"tweet exists reconciled": {
@@ -143,33 +384,146 @@ func Test_ReconcileOne(t *testing.T) {
 		},
 		"tweet does not exist tweet created not reconciled": {
 			reconciler: TweetReconciler{
+				k8sClient: newK8sClientMock(
+					"UpdateStatus",
+					[]interface{}{newTweet("hello-world", "Hello World", 12345)},
+					true,
+					nil,
+				).addMethod(
+					"SetCondition",
+					[]interface{}{"hello-world", tweettypes.Condition{
+						Type:    ConditionReady,
+						Status:  true,
+						Reason:  ReasonPosted,
+						Message: "Posted tweet 12345",
+					}},
+					nil,
+					nil,
+				),
 				twitterClient: newTwitterClientMock(
 					"PostTweet",
-					&tweettypes.Tweet{
-						Spec: tweettypes.TweetSpec{
-							Text: "Hello World",
-						},
-					},
+					[]interface{}{newTweet("hello-world", "Hello World", 0)},
+					int64(12345),
+					nil,
+				),
+			},
+			desired:    newTweet("hello-world", "Hello World", 0),
+			actual:     &tweettypes.Tweet{},
+			reconciled: false,
+			method:     "PostTweet",
+			calls:      1,
+			events:     []string{"Normal Posted Posted tweet 12345"},
+			err:        nil,
+		},
+		"tweet pending approval not posted reconciled": {
+			reconciler: TweetReconciler{
+				k8sClient: newK8sClientMock(
+					"SetCondition",
+					[]interface{}{"hello-world", tweettypes.Condition{
+						Type:    ConditionReady,
+						Reason:  ReasonPendingApproval,
+						Message: "Waiting for approvals: 1 of 2",
+					}},
 					nil,
 					nil,
 				),
+				twitterClient: &twitterClientMock{},
 			},
-			desired: &tweettypes.Tweet{
-				Spec: tweettypes.TweetSpec{
-					Text: "Hello World",
+			desired:    newApprovedTweet("hello-world", "Hello World", 2, "bob"),
+			actual:     &tweettypes.Tweet{},
+			reconciled: true,
+			err:        nil,
+		},
+		"tweet violating policy not posted reconciled": {
+			reconciler: TweetReconciler{
+				k8sClient: newK8sClientMock(
+					"SetCondition",
+					[]interface{}{"hello-world", tweettypes.Condition{
+						Type:    ConditionReady,
+						Reason:  ReasonPolicyViolation,
+						Message: `violates rule requiredPatterns[0] of TweetPolicy disclosure: text does not match "#ad"`,
+					}},
+					nil,
+					nil,
+				),
+				twitterClient: &twitterClientMock{},
+				policyClient: policyClientStub{
+					{Name: "disclosure", RequiredPatterns: []string{"#ad"}},
 				},
 			},
+			desired:    newTweet("hello-world", "Hello World", 0),
+			actual:     &tweettypes.Tweet{},
+			reconciled: true,
+			err:        nil,
+		},
+		"approved tweet created with approvers recorded": {
+			reconciler: TweetReconciler{
+				k8sClient: newK8sClientMock(
+					"UpdateStatus",
+					[]interface{}{&tweettypes.Tweet{
+						Spec:   newApprovedTweet("hello-world", "Hello World", 2, "bob", "carol").Spec,
+						Status: tweettypes.TweetStatus{ID: 12345, ApprovedBy: []string{"bob", "carol"}},
+					}},
+					true,
+					nil,
+				).addMethod(
+					"SetCondition",
+					[]interface{}{"hello-world", tweettypes.Condition{
+						Type:    ConditionReady,
+						Status:  true,
+						Reason:  ReasonPosted,
+						Message: "Posted tweet 12345",
+					}},
+					nil,
+					nil,
+				),
+				twitterClient: newTwitterClientMock(
+					"PostTweet",
+					[]interface{}{newApprovedTweet("hello-world", "Hello World", 2, "bob", "carol")},
+					int64(12345),
+					nil,
+				),
+			},
+			desired:    newApprovedTweet("hello-world", "Hello World", 2, "bob", "carol"),
 			actual:     &tweettypes.Tweet{},
 			reconciled: false,
 			method:     "PostTweet",
 			calls:      1,
+			events:     []string{"Normal Posted Posted tweet 12345"},
+			err:        nil,
+		},
+		"duplicate tweet post failed": {
+			reconciler: TweetReconciler{
+				k8sClient: newK8sClientMock(
+					"SetCondition",
+					[]interface{}{"hello-world", tweettypes.Condition{
+						Type:    ConditionReady,
+						Reason:  ReasonDuplicate,
+						Message: duplicateError.Error(),
+					}},
+					nil,
+					nil,
+				),
+				twitterClient: newTwitterClientMock(
+					"PostTweet",
+					[]interface{}{newTweet("hello-world", "Hello World", 0)},
+					nil,
+					duplicateError,
+				),
+			},
+			desired:    newTweet("hello-world", "Hello World", 0),
+			actual:     &tweettypes.Tweet{},
+			reconciled: true,
+			method:     "PostTweet",
+			calls:      1,
+			events:     []string{"Warning Duplicate twitter: 187 Status is a duplicate."},
 			err:        nil,
 		},
 		"desired not found tweet deleted not reconciled": {
 			reconciler: TweetReconciler{
 				twitterClient: newTwitterClientMock(
 					"DeleteTweet",
-					&tweettypes.Tweet{
+					[]interface{}{&tweettypes.Tweet{
 						Spec: tweettypes.TweetSpec{
 							Text: "Hello World",
 						},
@@ -179,7 +533,7 @@ func Test_ReconcileOne(t *testing.T) {
 							Retweets: 0,
 							Replies:  0,
 						},
-					},
+					}},
 					nil,
 					nil,
 				),
@@ -199,19 +553,21 @@ func Test_ReconcileOne(t *testing.T) {
 			reconciled: false,
 			method:     "DeleteTweet",
 			calls:      1,
+			events:     []string{"Normal Deleted Deleted tweet 12345"},
 			err:        nil,
 		},
 	}
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			reconciled, err := test.reconciler.ReconcileOne(test.desired, test.actual)
-			if err != test.err {
-				t.Errorf("expected error %v, got %v", test.err, err)
-			}
+			recorder := &eventRecorderMock{}
+			test.reconciler.recorder = recorder
+			reconciled, err := test.reconciler.ReconcileOne(context.TODO(), test.desired, test.actual)
+			assertError(t, test.err, err)
 			if reconciled != test.reconciled {
 				t.Errorf("expected reconciled %v, got %v", test.reconciled, reconciled)
 			}
+			assert.Equal(t, test.events, recorder.events)
 		})
 		if test.calls > 0 {
 			test.reconciler.twitterClient.(*twitterClientMock).AssertNumberOfCalls(t, test.method, test.calls)
@@ -261,7 +617,7 @@ func Test_getDesiredState(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			actual, err := test.reconciler.getDesiredState(test.tweetName)
+			actual, err := test.reconciler.getDesiredState(context.TODO(), test.tweetName)
 			assert.NoError(t, err)
 			assert.Equal(t, test.desired, actual)
 		})
@@ -272,6 +628,7 @@ func Test_getActualState(t *testing.T) {
 	tests := map[string]struct {
 		reconciler TweetReconciler
 		text       string
+		trackedIDs []int64
 		expected   *tweettypes.Tweet
 		calls      int
 		err        error
@@ -280,11 +637,12 @@ func Test_getActualState(t *testing.T) {
 			reconciler: TweetReconciler{
 				twitterClient: newTwitterClientMock(
 					"GetTweetsForUser",
-					"bob",
+					[]interface{}{"bob", []int64(nil)},
 					tweettypes.Tweets{},
 					nil,
 				),
 				twitterUserName: "bob",
+				now:             time.Now,
 			},
 			text:     "Hello World",
 			expected: &tweettypes.Tweet{},
@@ -295,7 +653,7 @@ func Test_getActualState(t *testing.T) {
 			reconciler: TweetReconciler{
 				twitterClient: newTwitterClientMock(
 					"GetTweetsForUser",
-					"bob",
+					[]interface{}{"bob", []int64{12345}},
 					tweettypes.Tweets{
 						{
 							Spec: tweettypes.TweetSpec{
@@ -312,8 +670,10 @@ func Test_getActualState(t *testing.T) {
 					nil,
 				),
 				twitterUserName: "bob",
+				now:             time.Now,
 			},
-			text: "Hello World",
+			text:       "Hello World",
+			trackedIDs: []int64{12345},
 			expected: &tweettypes.Tweet{
 				Spec: tweettypes.TweetSpec{
 					Text: "Hello World",
@@ -332,11 +692,12 @@ func Test_getActualState(t *testing.T) {
 			reconciler: TweetReconciler{
 				twitterClient: newTwitterClientMock(
 					"GetTweetsForUser",
-					"bob",
+					[]interface{}{"bob", []int64(nil)},
 					nil,
 					errors.New("some error"),
 				),
 				twitterUserName: "bob",
+				now:             time.Now,
 			},
 			text:     "Hello World",
 			expected: nil,
@@ -347,7 +708,8 @@ func Test_getActualState(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			actual, err := test.reconciler.getActualState(test.text)
+			desired := &tweettypes.Tweet{Spec: tweettypes.TweetSpec{Text: test.text}}
+			actual, err := test.reconciler.getActualState(context.TODO(), desired, test.trackedIDs)
 			assertError(t, test.err, err)
 			assert.Equal(t, test.expected, actual)
 		})
@@ -366,9 +728,9 @@ func assertError(t *testing.T, expected error, actual error) {
 	}
 }
 
-func newTwitterClientMock(methodName string, arg interface{}, ret interface{}, err error) *twitterClientMock {
+func newTwitterClientMock(methodName string, args []interface{}, ret interface{}, err error) *twitterClientMock {
 	client := new(twitterClientMock)
-	client.On(methodName, arg).Return(ret, err)
+	client.On(methodName, args...).Return(ret, err)
 	return client
 }
 
@@ -386,24 +748,45 @@ func (mock *twitterClientMock) addMethod(
 	return mock
 }
 
-func (mock *twitterClientMock) GetTweetsForUser(userName string) (tweettypes.Tweets, error) {
-	args := mock.Called(userName)
+func (mock *twitterClientMock) GetTweetsForUser(ctx context.Context, userName string, trackedIDs ...int64) (tweettypes.Tweets, error) {
+	args := mock.Called(userName, trackedIDs)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(tweettypes.Tweets), args.Error(1)
 }
 
-func (mock *twitterClientMock) PostTweet(tweet *tweettypes.Tweet) error {
+func (mock *twitterClientMock) PostTweet(ctx context.Context, tweet *tweettypes.Tweet) (int64, error) {
 	args := mock.Called(tweet)
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return 0, args.Error(1)
+	}
+	return args.Get(0).(int64), args.Error(1)
 }
 
-func (mock *twitterClientMock) DeleteTweet(tweet *tweettypes.Tweet) error {
+func (mock *twitterClientMock) DeleteTweet(ctx context.Context, tweet *tweettypes.Tweet) error {
 	args := mock.Called(tweet)
 	return args.Error(0)
 }
 
+var duplicateError = twitter.APIError{
+	Errors: []twitter.ErrorDetail{{Code: 187, Message: "Status is a duplicate."}},
+}
+
+type policyClientStub []tweettypes.TweetPolicy
+
+func (stub policyClientStub) ListTweetPolicies(ctx context.Context) ([]tweettypes.TweetPolicy, error) {
+	return stub, nil
+}
+
+type eventRecorderMock struct {
+	events []string
+}
+
+func (mock *eventRecorderMock) Event(tweet *tweettypes.Tweet, eventType, reason, message string) {
+	mock.events = append(mock.events, eventType+" "+reason+" "+message)
+}
+
 func newK8sClientMock(methodName string, args []interface{}, ret interface{}, err error) *k8sClientMock {
 	client := new(k8sClientMock)
 	client.On(methodName, args...).Return(ret, err)
@@ -424,21 +807,31 @@ func (mock *k8sClientMock) addMethod(
 	return mock
 }
 
-func (mock *k8sClientMock) GetTweet(name string) (*tweettypes.Tweet, error) {
+func (mock *k8sClientMock) GetTweet(ctx context.Context, name string) (*tweettypes.Tweet, error) {
 	args := mock.Called(name)
 	return args.Get(0).(*tweettypes.Tweet), args.Error(1)
 }
 
-func (mock *k8sClientMock) UpdateStatus(name string, tweet *tweettypes.Tweet) (updated bool, err error) {
+func (mock *k8sClientMock) UpdateStatus(ctx context.Context, name string, tweet *tweettypes.Tweet) (updated bool, err error) {
 	args := mock.Called(tweet)
 	return args.Get(0).(bool), args.Error(1)
 }
 
-func (mock *k8sClientMock) ListTweets() (*tweettypes.Tweets, error) {
+func (mock *k8sClientMock) ListTweets(ctx context.Context) (*tweettypes.Tweets, error) {
 	args := mock.Called()
 	return args.Get(0).(*tweettypes.Tweets), args.Error(1)
 }
 
+func (mock *k8sClientMock) RemoveFinalizer(ctx context.Context, name string) error {
+	args := mock.Called(name)
+	return args.Error(0)
+}
+
+func (mock *k8sClientMock) SetCondition(ctx context.Context, name string, condition tweettypes.Condition) error {
+	args := mock.Called(name, condition)
+	return args.Error(0)
+}
+
 func NewK8sClientMockGetTweetNoError(tweetName string, tweet *tweettypes.Tweet) *k8sClientMock {
 	client := new(k8sClientMock)
 	client.On("GetTweet", tweetName).Return(tweet, nil)
@@ -456,3 +849,10 @@ func newTweet(name, text string, id int64) *tweettypes.Tweet {
 		},
 	}
 }
+
+func newApprovedTweet(name, text string, required int, approvals ...string) *tweettypes.Tweet {
+	tweet := newTweet(name, text, 0)
+	tweet.Spec.Approvals = approvals
+	tweet.Spec.RequiredApprovals = required
+	return tweet
+}
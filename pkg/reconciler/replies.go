@@ -0,0 +1,146 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/go-logr/logr"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/logging"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/twitterclient"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ReplyClient fetches the replies to a post. Not every platform client
+// offers it, so it is not part of TwitterClient.
+type ReplyClient interface {
+	GetReplies(ctx context.Context, tweet *tweettypes.Tweet, max int) ([]tweettypes.Reply, error)
+}
+
+// ReplyStore keeps the replies ingested for each Tweet, newest first.
+type ReplyStore interface {
+	GetReplies(ctx context.Context, tweet *tweettypes.Tweet) ([]tweettypes.Reply, error)
+	SetReplies(ctx context.Context, tweet *tweettypes.Tweet, replies []tweettypes.Reply) error
+}
+
+// ReasonReplied is the reason of the event recorded for a new reply
+const ReasonReplied = "Replied"
+
+// DefaultMaxReplies is how many replies are kept per post by default
+const DefaultMaxReplies = 10
+
+// ReplyIngester keeps the most recent replies to each posted Tweet in the
+// ReplyStore, and records an event for each reply it has not seen before.
+type ReplyIngester struct {
+	k8sClient   K8sClient
+	replyClient ReplyClient
+	store       ReplyStore
+	recorder    EventRecorder
+	maxReplies  int
+	log         logr.Logger
+}
+
+func NewReplyIngester(
+	k8sClient K8sClient,
+	replyClient ReplyClient,
+	store ReplyStore,
+	recorder EventRecorder,
+	maxReplies int,
+	log logr.Logger,
+) *ReplyIngester {
+	return &ReplyIngester{
+		k8sClient:   k8sClient,
+		replyClient: replyClient,
+		store:       store,
+		recorder:    recorder,
+		maxReplies:  maxReplies,
+		log:         log,
+	}
+}
+
+// Ingest fetches the replies to every posted Tweet. A Tweet whose replies
+// cannot be fetched is logged and skipped, except when rate limited, which
+// stops the pass.
+func (i *ReplyIngester) Ingest(ctx context.Context) error {
+	tweets, err := i.k8sClient.ListTweets(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get tweet list from k8s")
+	}
+	for _, t := range *tweets {
+		if t.Status.ID == 0 || t.Spec.Deleting {
+			continue
+		}
+		err := i.ingestOne(ctx, &t)
+		var rateLimitErr *twitterclient.RateLimitError
+		if errors.As(err, &rateLimitErr) {
+			return err
+		}
+		if err != nil {
+			logging.WithTweet(i.log, &t).Error(err, "Failed to ingest replies")
+		}
+	}
+	return nil
+}
+
+func (i *ReplyIngester) ingestOne(ctx context.Context, tweet *tweettypes.Tweet) error {
+	replies, err := i.replyClient.GetReplies(ctx, tweet, i.maxReplies)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get replies to %s", tweet.Spec.Name)
+	}
+	sort.SliceStable(replies, func(a, b int) bool {
+		return replies[a].Time.After(replies[b].Time)
+	})
+	if len(replies) > i.maxReplies {
+		replies = replies[:i.maxReplies]
+	}
+
+	stored, err := i.store.GetReplies(ctx, tweet)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get stored replies to %s", tweet.Spec.Name)
+	}
+	newReplies := newReplies(stored, replies, i.maxReplies)
+	if len(newReplies) == 0 && reflect.DeepEqual(stored, replies) {
+		return nil
+	}
+	if err := i.store.SetReplies(ctx, tweet, replies); err != nil {
+		return errors.Wrapf(err, "failed to store replies to %s", tweet.Spec.Name)
+	}
+	// Oldest first, in the order they came in
+	for n := len(newReplies) - 1; n >= 0; n-- {
+		reply := newReplies[n]
+		logging.WithTweet(i.log, tweet).Info("New reply", "replyID", reply.ID, "author", reply.Author)
+		i.event(tweet, corev1.EventTypeNormal, ReasonReplied, fmt.Sprintf("Reply from %s: %s", reply.Author, reply.Text))
+	}
+	return nil
+}
+
+// newReplies returns the fetched replies that were not stored before. Once
+// the store is full, replies older than all stored ones are not new, only
+// no longer among the most recent.
+func newReplies(stored, fetched []tweettypes.Reply, max int) []tweettypes.Reply {
+	known := map[string]bool{}
+	for _, r := range stored {
+		known[r.ID] = true
+	}
+	result := []tweettypes.Reply{}
+	for _, r := range fetched {
+		if known[r.ID] {
+			continue
+		}
+		if len(stored) >= max && !r.Time.After(stored[len(stored)-1].Time) {
+			continue
+		}
+		result = append(result, r)
+	}
+	return result
+}
+
+func (i *ReplyIngester) event(tweet *tweettypes.Tweet, eventType, reason, message string) {
+	if i.recorder == nil {
+		return
+	}
+	i.recorder.Event(tweet, eventType, reason, message)
+}
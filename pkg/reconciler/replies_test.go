@@ -0,0 +1,154 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/twitterclient"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// replyClientStub returns the replies, or the error, set for each Tweet
+type replyClientStub struct {
+	replies map[string][]tweettypes.Reply
+	errs    map[string]error
+	calls   []string
+}
+
+func (stub *replyClientStub) GetReplies(ctx context.Context, tweet *tweettypes.Tweet, max int) ([]tweettypes.Reply, error) {
+	stub.calls = append(stub.calls, tweet.Spec.Name)
+	return append([]tweettypes.Reply{}, stub.replies[tweet.Spec.Name]...), stub.errs[tweet.Spec.Name]
+}
+
+type replyStoreStub struct {
+	replies map[string][]tweettypes.Reply
+}
+
+func (stub *replyStoreStub) GetReplies(ctx context.Context, tweet *tweettypes.Tweet) ([]tweettypes.Reply, error) {
+	return stub.replies[tweet.Spec.Name], nil
+}
+
+func (stub *replyStoreStub) SetReplies(ctx context.Context, tweet *tweettypes.Tweet, replies []tweettypes.Reply) error {
+	stub.replies[tweet.Spec.Name] = replies
+	return nil
+}
+
+func Test_IngestReplies(t *testing.T) {
+	posted := time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+	reply := func(id string, minutes int) tweettypes.Reply {
+		return tweettypes.Reply{
+			ID:     id,
+			Author: "alice",
+			Text:   "reply " + id,
+			Time:   posted.Add(time.Duration(minutes) * time.Minute),
+		}
+	}
+
+	tests := map[string]struct {
+		stored   []tweettypes.Reply
+		fetched  []tweettypes.Reply
+		expected []tweettypes.Reply
+		events   []string
+	}{
+		"no replies": {
+			fetched: []tweettypes.Reply{},
+			events:  nil,
+		},
+		"first replies, oldest event first": {
+			fetched:  []tweettypes.Reply{reply("1", 1), reply("2", 2)},
+			expected: []tweettypes.Reply{reply("2", 2), reply("1", 1)},
+			events: []string{
+				"Normal Replied Reply from alice: reply 1",
+				"Normal Replied Reply from alice: reply 2",
+			},
+		},
+		"nothing new": {
+			stored:   []tweettypes.Reply{reply("2", 2), reply("1", 1)},
+			fetched:  []tweettypes.Reply{reply("2", 2), reply("1", 1)},
+			expected: []tweettypes.Reply{reply("2", 2), reply("1", 1)},
+		},
+		"keeps the most recent": {
+			stored:   []tweettypes.Reply{reply("3", 3), reply("2", 2), reply("1", 1)},
+			fetched:  []tweettypes.Reply{reply("1", 1), reply("2", 2), reply("3", 3), reply("4", 4)},
+			expected: []tweettypes.Reply{reply("4", 4), reply("3", 3), reply("2", 2)},
+			events:   []string{"Normal Replied Reply from alice: reply 4"},
+		},
+		"older replies are not new once the store is full": {
+			stored:   []tweettypes.Reply{reply("4", 4), reply("3", 3), reply("2", 2)},
+			fetched:  []tweettypes.Reply{reply("1", 1), reply("2", 2), reply("4", 4)},
+			expected: []tweettypes.Reply{reply("4", 4), reply("2", 2), reply("1", 1)},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			k8sMock := newK8sClientMock("ListTweets", []interface{}{}, &tweettypes.Tweets{
+				*newTweet("hello-world", "Hello World", 1),
+				*newTweet("unposted", "Not yet", 0),
+			}, nil)
+			replyClient := &replyClientStub{replies: map[string][]tweettypes.Reply{"hello-world": test.fetched}}
+			store := &replyStoreStub{replies: map[string][]tweettypes.Reply{}}
+			if test.stored != nil {
+				store.replies["hello-world"] = test.stored
+			}
+			recorder := &eventRecorderMock{}
+
+			ingester := NewReplyIngester(k8sMock, replyClient, store, recorder, 3, logr.Discard())
+			assert.NoError(t, ingester.Ingest(context.TODO()))
+			assert.Equal(t, []string{"hello-world"}, replyClient.calls)
+			if len(test.expected) > 0 {
+				assert.Equal(t, test.expected, store.replies["hello-world"])
+			}
+			assert.Equal(t, test.events, recorder.events)
+		})
+	}
+}
+
+func Test_IngestRepliesErrors(t *testing.T) {
+	tweets := &tweettypes.Tweets{
+		*newTweet("failing", "Failing", 1),
+		*newTweet("hello-world", "Hello World", 2),
+	}
+	replies := map[string][]tweettypes.Reply{"hello-world": {{ID: "3", Author: "alice", Text: "Hi"}}}
+
+	tests := map[string]struct {
+		err      error
+		expected error
+		calls    []string
+	}{
+		"skips the failing tweet": {
+			err:   errors.New("not found"),
+			calls: []string{"failing", "hello-world"},
+		},
+		"stops when rate limited": {
+			err:      &twitterclient.RateLimitError{Endpoint: "search"},
+			expected: &twitterclient.RateLimitError{},
+			calls:    []string{"failing"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			replyClient := &replyClientStub{replies: replies, errs: map[string]error{"failing": test.err}}
+			ingester := NewReplyIngester(
+				newK8sClientMock("ListTweets", []interface{}{}, tweets, nil),
+				replyClient,
+				&replyStoreStub{replies: map[string][]tweettypes.Reply{}},
+				nil,
+				DefaultMaxReplies,
+				logr.Discard(),
+			)
+			err := ingester.Ingest(context.TODO())
+			if test.expected != nil {
+				assert.ErrorAs(t, err, &test.expected)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.calls, replyClient.calls)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package reconciler
+
+import (
+	"html"
+	"strings"
+	"time"
+
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+)
+
+// DefaultSnapshotTTL is how long a timeline snapshot is trusted before it is
+// fetched again. Posts and deletes made by the reconciler invalidate it
+// straight away.
+const DefaultSnapshotTTL = time.Minute
+
+// timelineSnapshot is the account's timeline as read at one point in time,
+// indexed so every Tweet in a pass can be looked up without another fetch.
+type timelineSnapshot struct {
+	tweets    tweettypes.Tweets
+	byID      map[int64]*tweettypes.Tweet
+	byText    map[string]*tweettypes.Tweet
+	fetchedAt time.Time
+	// older are the tweets past the oldest tracked ID, by normalized text,
+	// once the newest page has been read without that bound. They are only
+	// looked in for a tweet to adopt, never cleaned up, so what is deleted
+	// does not depend on whether some Tweet is still waiting to be posted.
+	older map[string]*tweettypes.Tweet
+}
+
+func newTimelineSnapshot(tweets tweettypes.Tweets, fetchedAt time.Time) *timelineSnapshot {
+	snapshot := &timelineSnapshot{
+		tweets:    tweets,
+		byID:      map[int64]*tweettypes.Tweet{},
+		byText:    map[string]*tweettypes.Tweet{},
+		fetchedAt: fetchedAt,
+	}
+	snapshot.index()
+	return snapshot
+}
+
+func (s *timelineSnapshot) index() {
+	for i := range s.tweets {
+		tweet := &s.tweets[i]
+		s.byID[tweet.Status.ID] = tweet
+		// The timeline is newest first, so the newest tweet wins
+		text := normalizeText(tweet.Spec.Text)
+		if _, ok := s.byText[text]; !ok {
+			s.byText[text] = tweet
+		}
+	}
+}
+
+// addOlder indexes tweets read without the tracked ID bound for adoption.
+// Those already in the snapshot are skipped.
+func (s *timelineSnapshot) addOlder(tweets tweettypes.Tweets) {
+	s.older = map[string]*tweettypes.Tweet{}
+	for i := range tweets {
+		tweet := &tweets[i]
+		if _, ok := s.byID[tweet.Status.ID]; ok {
+			continue
+		}
+		// Newest first here too
+		text := normalizeText(tweet.Spec.Text)
+		if _, ok := s.older[text]; !ok {
+			s.older[text] = tweet
+		}
+	}
+}
+
+func (s *timelineSnapshot) stale(now time.Time, ttl time.Duration) bool {
+	return now.Sub(s.fetchedAt) >= ttl
+}
+
+// find returns the tweet on the timeline for a desired Tweet. The recorded
+// ID is preferred, as long as the text still matches, so duplicates of the
+// same text resolve to the tweet already tracked. Older tweets are only
+// found by text.
+func (s *timelineSnapshot) find(desired *tweettypes.Tweet) *tweettypes.Tweet {
+	text := normalizeText(desired.Spec.Text)
+	if tweet, ok := s.byID[desired.Status.ID]; ok && normalizeText(tweet.Spec.Text) == text {
+		return tweet
+	}
+	if tweet, ok := s.byText[text]; ok {
+		return tweet
+	}
+	return s.older[text]
+}
+
+// normalizeText makes text typed into a Tweet resource comparable with text
+// read back from Twitter, which HTML-escapes some characters and may differ
+// in whitespace.
+func normalizeText(text string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(text)), " ")
+}
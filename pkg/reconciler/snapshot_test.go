@@ -0,0 +1,316 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_normalizeText(t *testing.T) {
+	tests := map[string]struct {
+		in   string
+		want string
+	}{
+		"unchanged":          {in: "Hello World", want: "Hello World"},
+		"html escaped":       {in: "Fish &amp; chips &lt;3", want: "Fish & chips <3"},
+		"surrounding spaces": {in: "  Hello World\n", want: "Hello World"},
+		"inner whitespace":   {in: "Hello \n\t World", want: "Hello World"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, normalizeText(test.in))
+		})
+	}
+}
+
+func Test_timelineSnapshotFind(t *testing.T) {
+	snapshot := newTimelineSnapshot(
+		tweettypes.Tweets{
+			*newTweet("", "Fish &amp; chips", 3),
+			*newTweet("", "Hello World", 2),
+			*newTweet("", "Hello World", 1),
+		},
+		time.Now(),
+	)
+
+	tests := map[string]struct {
+		desired *tweettypes.Tweet
+		want    *tweettypes.Tweet
+	}{
+		"by normalized text": {
+			desired: newTweet("fish", "Fish & chips", 0),
+			want:    newTweet("", "Fish &amp; chips", 3),
+		},
+		"newest duplicate when untracked": {
+			desired: newTweet("hello-world", "Hello World", 0),
+			want:    newTweet("", "Hello World", 2),
+		},
+		"tracked duplicate": {
+			desired: newTweet("hello-world", "Hello World", 1),
+			want:    newTweet("", "Hello World", 1),
+		},
+		"tracked ID with edited text": {
+			desired: newTweet("hello-world", "Goodbye World", 1),
+			want:    nil,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, snapshot.find(test.desired))
+		})
+	}
+}
+
+func Test_ReconcileFetchesTimelineOnce(t *testing.T) {
+	desired := tweettypes.Tweets{
+		*newTweet("hello-world", "Hello World", 1),
+		*newTweet("good-morning", "Good morning", 2),
+	}
+	k8sMock := newK8sClientMock(
+		"ListTweets",
+		[]interface{}{},
+		&desired,
+		nil,
+	).addMethod(
+		"GetTweet",
+		[]interface{}{"hello-world"},
+		newTweet("hello-world", "Hello World", 1),
+		nil,
+	).addMethod(
+		"GetTweet",
+		[]interface{}{"good-morning"},
+		newTweet("good-morning", "Good morning", 2),
+		nil,
+	).addMethod(
+		"UpdateStatus",
+		[]interface{}{newTweet("hello-world", "Hello World", 1)},
+		false,
+		nil,
+	).addMethod(
+		"UpdateStatus",
+		[]interface{}{newTweet("good-morning", "Good morning", 2)},
+		false,
+		nil,
+	)
+	twitterMock := newTwitterClientMock(
+		"GetTweetsForUser",
+		[]interface{}{"bob", []int64{1, 2}},
+		tweettypes.Tweets{
+			*newTweet("", "Good morning", 2),
+			*newTweet("", "Hello World", 1),
+		},
+		nil,
+	)
+
+	now := time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+	reconciler := NewTweetReconciler(k8sMock, twitterMock, nil, nil, "bob", time.Minute, logr.Discard())
+	reconciler.now = func() time.Time { return now }
+
+	reconciled, err := reconciler.Reconcile(context.TODO())
+	assert.NoError(t, err)
+	assert.True(t, reconciled)
+	twitterMock.AssertNumberOfCalls(t, "GetTweetsForUser", 1)
+
+	// Still fresh on the next pass
+	now = now.Add(30 * time.Second)
+	_, err = reconciler.Reconcile(context.TODO())
+	assert.NoError(t, err)
+	twitterMock.AssertNumberOfCalls(t, "GetTweetsForUser", 1)
+
+	// Stale once the TTL has passed
+	now = now.Add(30 * time.Second)
+	_, err = reconciler.Reconcile(context.TODO())
+	assert.NoError(t, err)
+	twitterMock.AssertNumberOfCalls(t, "GetTweetsForUser", 2)
+}
+
+func Test_ReconcileInvalidatesSnapshotAfterPost(t *testing.T) {
+	k8sMock := newK8sClientMock(
+		"ListTweets",
+		[]interface{}{},
+		&tweettypes.Tweets{*newTweet("hello-world", "Hello World", 0)},
+		nil,
+	).addMethod(
+		"GetTweet",
+		[]interface{}{"hello-world"},
+		newTweet("hello-world", "Hello World", 0),
+		nil,
+	).addMethod(
+		"UpdateStatus",
+		[]interface{}{newTweet("hello-world", "Hello World", 1)},
+		true,
+		nil,
+	).addMethod(
+		"SetCondition",
+		[]interface{}{"hello-world", mock.Anything},
+		nil,
+		nil,
+	)
+	twitterMock := newTwitterClientMock(
+		"GetTweetsForUser",
+		[]interface{}{"bob", []int64{}},
+		tweettypes.Tweets{},
+		nil,
+	).addMethod(
+		"PostTweet",
+		[]interface{}{newTweet("hello-world", "Hello World", 0)},
+		int64(1),
+		nil,
+	)
+
+	reconciler := NewTweetReconciler(k8sMock, twitterMock, nil, nil, "bob", time.Hour, logr.Discard())
+
+	reconciled, err := reconciler.Reconcile(context.TODO())
+	assert.NoError(t, err)
+	assert.False(t, reconciled)
+	assert.Nil(t, reconciler.snapshot)
+}
+
+func Test_ReconcileAdoptsTweetOlderThanTracked(t *testing.T) {
+	k8sMock := newK8sClientMock(
+		"ListTweets",
+		[]interface{}{},
+		&tweettypes.Tweets{
+			*newTweet("good-morning", "Good morning", 3),
+			*newTweet("hello-world", "Hello World", 0),
+		},
+		nil,
+	).addMethod(
+		"GetTweet",
+		[]interface{}{"good-morning"},
+		newTweet("good-morning", "Good morning", 3),
+		nil,
+	).addMethod(
+		"GetTweet",
+		[]interface{}{"hello-world"},
+		newTweet("hello-world", "Hello World", 0),
+		nil,
+	).addMethod(
+		"UpdateStatus",
+		[]interface{}{newTweet("good-morning", "Good morning", 3)},
+		false,
+		nil,
+	).addMethod(
+		"UpdateStatus",
+		[]interface{}{newTweet("hello-world", "Hello World", 1)},
+		true,
+		nil,
+	).addMethod(
+		"SetCondition",
+		[]interface{}{"hello-world", mock.Anything},
+		nil,
+		nil,
+	)
+	// The timeline stops at the oldest tracked tweet, so Hello World,
+	// posted before it, only shows up without the bound
+	twitterMock := newTwitterClientMock(
+		"GetTweetsForUser",
+		[]interface{}{"bob", []int64{3}},
+		tweettypes.Tweets{*newTweet("", "Good morning", 3)},
+		nil,
+	).addMethod(
+		"GetTweetsForUser",
+		[]interface{}{"bob", []int64(nil)},
+		tweettypes.Tweets{
+			*newTweet("", "Good morning", 3),
+			*newTweet("", "Hello World", 1),
+		},
+		nil,
+	)
+	recorder := &eventRecorderMock{}
+	reconciler := NewTweetReconciler(k8sMock, twitterMock, recorder, nil, "bob", time.Minute, logr.Discard())
+
+	reconciled, err := reconciler.Reconcile(context.TODO())
+	assert.NoError(t, err)
+	assert.False(t, reconciled)
+	assert.Equal(t, []string{"Normal Adopted Adopted existing tweet 1"}, recorder.events)
+	twitterMock.AssertNumberOfCalls(t, "GetTweetsForUser", 2)
+}
+
+func Test_ReconcileLeavesTweetsOlderThanTracked(t *testing.T) {
+	k8sMock := newK8sClientMock(
+		"ListTweets",
+		[]interface{}{},
+		&tweettypes.Tweets{
+			*newTweet("good-morning", "Good morning", 3),
+			*newApprovedTweet("pending", "Not yet", 1),
+		},
+		nil,
+	).addMethod(
+		"GetTweet",
+		[]interface{}{"good-morning"},
+		newTweet("good-morning", "Good morning", 3),
+		nil,
+	).addMethod(
+		"GetTweet",
+		[]interface{}{"pending"},
+		newApprovedTweet("pending", "Not yet", 1),
+		nil,
+	).addMethod(
+		"UpdateStatus",
+		[]interface{}{newTweet("good-morning", "Good morning", 3)},
+		false,
+		nil,
+	).addMethod(
+		"UpdateStatus",
+		[]interface{}{newTweet("pending", "", 0)},
+		false,
+		nil,
+	).addMethod(
+		"SetCondition",
+		[]interface{}{"pending", mock.Anything},
+		nil,
+		nil,
+	)
+	// The pending Tweet has the newest page read without the bound, which
+	// shows an unmanaged tweet that cleanup does not reach otherwise
+	twitterMock := newTwitterClientMock(
+		"GetTweetsForUser",
+		[]interface{}{"bob", []int64{3}},
+		tweettypes.Tweets{*newTweet("", "Good morning", 3)},
+		nil,
+	).addMethod(
+		"GetTweetsForUser",
+		[]interface{}{"bob", []int64(nil)},
+		tweettypes.Tweets{
+			*newTweet("", "Good morning", 3),
+			*newTweet("", "Unmanaged", 2),
+		},
+		nil,
+	)
+	reconciler := NewTweetReconciler(k8sMock, twitterMock, nil, nil, "bob", time.Minute, logr.Discard())
+
+	reconciled, err := reconciler.Reconcile(context.TODO())
+	assert.NoError(t, err)
+	assert.True(t, reconciled)
+	twitterMock.AssertNumberOfCalls(t, "GetTweetsForUser", 2)
+	twitterMock.AssertNotCalled(t, "DeleteTweet", mock.Anything)
+}
+
+func Test_findReadsUnboundedOncePerSnapshot(t *testing.T) {
+	twitterMock := newTwitterClientMock(
+		"GetTweetsForUser",
+		[]interface{}{"bob", []int64(nil)},
+		tweettypes.Tweets{*newTweet("", "Good morning", 3)},
+		nil,
+	)
+	reconciler := NewTweetReconciler(nil, twitterMock, nil, nil, "bob", time.Minute, logr.Discard())
+	snapshot := newTimelineSnapshot(tweettypes.Tweets{*newTweet("", "Good morning", 3)}, time.Now())
+
+	for _, desired := range []*tweettypes.Tweet{
+		newTweet("hello-world", "Hello World", 0),
+		newTweet("goodbye", "Goodbye", 0),
+	} {
+		tweet, err := reconciler.find(context.TODO(), snapshot, desired, []int64{3})
+		assert.NoError(t, err)
+		assert.Nil(t, tweet)
+	}
+	twitterMock.AssertNumberOfCalls(t, "GetTweetsForUser", 1)
+}
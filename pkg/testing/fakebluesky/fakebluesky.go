@@ -0,0 +1,514 @@
+// Package fakebluesky serves the parts of the Bluesky XRPC API the operator
+// uses, backed by an in-memory repository, so clients can be tested over
+// real HTTP.
+package fakebluesky
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Endpoints, named by their XRPC method
+const (
+	EndpointCreateSession = "com.atproto.server.createSession"
+	EndpointResolveHandle = "com.atproto.identity.resolveHandle"
+	EndpointCreateRecord  = "com.atproto.repo.createRecord"
+	EndpointDeleteRecord  = "com.atproto.repo.deleteRecord"
+	EndpointGetAuthorFeed = "app.bsky.feed.getAuthorFeed"
+	EndpointGetPosts      = "app.bsky.feed.getPosts"
+	EndpointGetPostThread = "app.bsky.feed.getPostThread"
+)
+
+const (
+	collectionPost = "app.bsky.feed.post"
+	maxPageSize    = 100
+	maxPostURIs    = 25
+	tidAlphabet    = "234567abcdefghijklmnopqrstuvwxyz"
+)
+
+type Post struct {
+	URI       string
+	Text      string
+	Facets    []Facet
+	Likes     int64
+	Reposts   int64
+	Replies   int64
+	Quotes    int64
+	CreatedAt time.Time
+}
+
+// Reply is a post by another account in the thread below one of the
+// account's posts.
+type Reply struct {
+	URI       string
+	ParentURI string
+	Handle    string
+	Text      string
+	CreatedAt time.Time
+}
+
+// Facet is one link or mention annotation of a post, by byte range.
+type Facet struct {
+	ByteStart int
+	ByteEnd   int
+	// Type is the facet feature type, such as app.bsky.richtext.facet#link
+	Type string
+	URI  string
+	DID  string
+}
+
+// Fault replaces the response to one call with an error response.
+// RetryAfter sets the ratelimit-reset header of a 429.
+type Fault struct {
+	StatusCode int
+	Error      string
+	Message    string
+	RetryAfter time.Duration
+}
+
+type Server struct {
+	server *httptest.Server
+	URL    string
+
+	mu          sync.Mutex
+	did         string
+	handle      string
+	appPassword string
+	sessions    map[string]bool
+	handles     map[string]string
+	posts       []Post
+	replies     []Reply
+	nextTID     uint64
+	faults      map[string][]Fault
+	requests    []string
+	now         func() time.Time
+}
+
+// New starts a server for the account handle, which accepts sessions
+// created with appPassword.
+func New(handle, appPassword string) *Server {
+	s := &Server{
+		did:         "did:plc:" + strings.ReplaceAll(handle, ".", ""),
+		handle:      handle,
+		appPassword: appPassword,
+		sessions:    map[string]bool{},
+		handles:     map[string]string{},
+		// A TID from mid 2023, so the keys look like real ones
+		nextTID:  1688000000000000 << 10,
+		faults:   map[string][]Fault{},
+		now:      time.Now,
+		requests: []string{},
+	}
+	s.handles[handle] = s.did
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+	return s
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// DID returns the account's DID.
+func (s *Server) DID() string {
+	return s.did
+}
+
+// AddHandle makes another account's handle resolvable, for mentions.
+func (s *Server) AddHandle(handle, did string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handles[handle] = did
+}
+
+// ExpireSessions makes the access tokens handed out so far expire.
+func (s *Server) ExpireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for token := range s.sessions {
+		s.sessions[token] = false
+	}
+}
+
+// AddReply answers a post, or another reply, as the account with the
+// handle. A reply to one of the account's posts counts in its replies.
+func (s *Server) AddReply(parentURI, handle, text string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextTID += 1 << 10
+	uri := "at://did:plc:" + strings.ReplaceAll(handle, ".", "") + "/" + collectionPost + "/" + encodeTID(s.nextTID)
+	s.replies = append(s.replies, Reply{URI: uri, ParentURI: parentURI, Handle: handle, Text: text, CreatedAt: s.now()})
+	for i := range s.posts {
+		if s.posts[i].URI == parentURI {
+			s.posts[i].Replies++
+		}
+	}
+	return uri
+}
+
+// AddPost puts a post in the repository as if it was made elsewhere.
+func (s *Server) AddPost(text string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addPost(text, nil)
+}
+
+func (s *Server) addPost(text string, facets []Facet) string {
+	s.nextTID += 1 << 10
+	uri := "at://" + s.did + "/" + collectionPost + "/" + encodeTID(s.nextTID)
+	// Posts are kept newest first
+	s.posts = append([]Post{{URI: uri, Text: text, Facets: facets, CreatedAt: s.now()}}, s.posts...)
+	return uri
+}
+
+// SetCounts sets the engagement counts of a post.
+func (s *Server) SetCounts(uri string, likes, reposts, replies int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.posts {
+		if s.posts[i].URI == uri {
+			s.posts[i].Likes = likes
+			s.posts[i].Reposts = reposts
+			s.posts[i].Replies = replies
+		}
+	}
+}
+
+// Posts returns the account's posts, newest first.
+func (s *Server) Posts() []Post {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Post{}, s.posts...)
+}
+
+// InjectFault makes the next calls to the endpoint fail, one fault per call.
+func (s *Server) InjectFault(endpoint string, faults ...Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[endpoint] = append(s.faults[endpoint], faults...)
+}
+
+// Requests returns the endpoints called so far, in order.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.requests...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	endpoint := strings.TrimPrefix(r.URL.Path, "/xrpc/")
+	handler := s.route(r.Method, endpoint)
+	if handler == nil {
+		writeError(w, http.StatusNotImplemented, "MethodNotImplemented", "Method Not Implemented")
+		return
+	}
+	s.requests = append(s.requests, endpoint)
+
+	if endpoint != EndpointCreateSession {
+		valid, ok := s.sessions[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "AuthenticationRequired", "Invalid identifier or password")
+			return
+		}
+		if !valid {
+			writeError(w, http.StatusBadRequest, "ExpiredToken", "Token has expired")
+			return
+		}
+	}
+	if faults := s.faults[endpoint]; len(faults) > 0 {
+		s.faults[endpoint] = faults[1:]
+		fault := faults[0]
+		if fault.StatusCode == http.StatusTooManyRequests {
+			w.Header().Set("ratelimit-remaining", "0")
+			w.Header().Set("ratelimit-reset", strconv.FormatInt(s.now().Add(fault.RetryAfter).Unix(), 10))
+		}
+		name := fault.Error
+		if name == "" {
+			name = strings.ReplaceAll(http.StatusText(fault.StatusCode), " ", "")
+		}
+		writeError(w, fault.StatusCode, name, fault.Message)
+		return
+	}
+	handler(w, r)
+}
+
+func (s *Server) route(method, endpoint string) http.HandlerFunc {
+	switch {
+	case method == http.MethodPost && endpoint == EndpointCreateSession:
+		return s.createSession
+	case method == http.MethodGet && endpoint == EndpointResolveHandle:
+		return s.resolveHandle
+	case method == http.MethodPost && endpoint == EndpointCreateRecord:
+		return s.createRecord
+	case method == http.MethodPost && endpoint == EndpointDeleteRecord:
+		return s.deleteRecord
+	case method == http.MethodGet && endpoint == EndpointGetAuthorFeed:
+		return s.getAuthorFeed
+	case method == http.MethodGet && endpoint == EndpointGetPosts:
+		return s.getPosts
+	case method == http.MethodGet && endpoint == EndpointGetPostThread:
+		return s.getPostThread
+	}
+	return nil
+}
+
+func (s *Server) createSession(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Identifier string `json:"identifier"`
+		Password   string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", err.Error())
+		return
+	}
+	if (body.Identifier != s.handle && body.Identifier != s.did) || body.Password != s.appPassword {
+		writeError(w, http.StatusUnauthorized, "AuthenticationRequired", "Invalid identifier or password")
+		return
+	}
+	token := "access-" + strconv.Itoa(len(s.sessions)+1)
+	s.sessions[token] = true
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"accessJwt":  token,
+		"refreshJwt": "refresh-" + strconv.Itoa(len(s.sessions)),
+		"handle":     s.handle,
+		"did":        s.did,
+	})
+}
+
+func (s *Server) resolveHandle(w http.ResponseWriter, r *http.Request) {
+	did, ok := s.handles[r.URL.Query().Get("handle")]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Unable to resolve handle")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"did": did})
+}
+
+func (s *Server) createRecord(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Repo       string `json:"repo"`
+		Collection string `json:"collection"`
+		Record     struct {
+			Type   string `json:"$type"`
+			Text   string `json:"text"`
+			Facets []struct {
+				Index struct {
+					ByteStart int `json:"byteStart"`
+					ByteEnd   int `json:"byteEnd"`
+				} `json:"index"`
+				Features []struct {
+					Type string `json:"$type"`
+					URI  string `json:"uri"`
+					DID  string `json:"did"`
+				} `json:"features"`
+			} `json:"facets"`
+			CreatedAt string `json:"createdAt"`
+		} `json:"record"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", err.Error())
+		return
+	}
+	if body.Repo != s.did && body.Repo != s.handle {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Could not find repo")
+		return
+	}
+	if body.Collection != collectionPost || body.Record.Type != collectionPost {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Unsupported collection")
+		return
+	}
+	if _, err := time.Parse(time.RFC3339Nano, body.Record.CreatedAt); err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Record/createdAt must be a valid datetime")
+		return
+	}
+	facets := []Facet{}
+	for _, f := range body.Record.Facets {
+		if f.Index.ByteStart < 0 || f.Index.ByteEnd > len(body.Record.Text) || f.Index.ByteStart >= f.Index.ByteEnd {
+			writeError(w, http.StatusBadRequest, "InvalidRequest", "Invalid facet index")
+			return
+		}
+		for _, feature := range f.Features {
+			facets = append(facets, Facet{
+				ByteStart: f.Index.ByteStart,
+				ByteEnd:   f.Index.ByteEnd,
+				Type:      feature.Type,
+				URI:       feature.URI,
+				DID:       feature.DID,
+			})
+		}
+	}
+	uri := s.addPost(body.Record.Text, facets)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"uri": uri, "cid": "bafyrei" + strconv.Itoa(len(s.posts))})
+}
+
+func (s *Server) deleteRecord(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Repo       string `json:"repo"`
+		Collection string `json:"collection"`
+		RKey       string `json:"rkey"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", err.Error())
+		return
+	}
+	// Deleting a record that does not exist succeeds, like on a real PDS
+	uri := "at://" + s.did + "/" + body.Collection + "/" + body.RKey
+	for i, post := range s.posts {
+		if post.URI == uri {
+			s.posts = append(s.posts[:i], s.posts[i+1:]...)
+			break
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{})
+}
+
+func (s *Server) getAuthorFeed(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if actor := query.Get("actor"); actor != s.handle && actor != s.did {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Profile not found")
+		return
+	}
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 || limit > maxPageSize {
+		limit = 50
+	}
+	// The cursor is the URI of the last post on the previous page
+	cursor := query.Get("cursor")
+	start := 0
+	if cursor != "" {
+		for i, post := range s.posts {
+			if post.URI == cursor {
+				start = i + 1
+			}
+		}
+	}
+	end := start + limit
+	if end > len(s.posts) {
+		end = len(s.posts)
+	}
+
+	feed := []map[string]interface{}{}
+	for _, post := range s.posts[start:end] {
+		feed = append(feed, map[string]interface{}{"post": s.render(post)})
+	}
+	resp := map[string]interface{}{"feed": feed}
+	if end < len(s.posts) {
+		resp["cursor"] = s.posts[end-1].URI
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) getPosts(w http.ResponseWriter, r *http.Request) {
+	uris := r.URL.Query()["uris"]
+	if len(uris) > maxPostURIs {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "uris must not have more than 25 elements")
+		return
+	}
+	// Posts that do not exist are left out
+	posts := []map[string]interface{}{}
+	for _, uri := range uris {
+		for _, post := range s.posts {
+			if post.URI == uri {
+				posts = append(posts, s.render(post))
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"posts": posts})
+}
+
+// getPostThread returns one of the account's posts with the replies below
+// it, up to depth levels down.
+func (s *Server) getPostThread(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	depth, err := strconv.Atoi(query.Get("depth"))
+	if err != nil {
+		depth = 6
+	}
+	uri := query.Get("uri")
+	for _, post := range s.posts {
+		if post.URI == uri {
+			thread := map[string]interface{}{
+				"$type":   "app.bsky.feed.defs#threadViewPost",
+				"post":    s.render(post),
+				"replies": s.threadReplies(uri, depth),
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{"thread": thread})
+			return
+		}
+	}
+	writeError(w, http.StatusBadRequest, "NotFound", "Post not found: "+uri)
+}
+
+func (s *Server) threadReplies(parentURI string, depth int) []map[string]interface{} {
+	replies := []map[string]interface{}{}
+	if depth <= 0 {
+		return replies
+	}
+	for _, reply := range s.replies {
+		if reply.ParentURI != parentURI {
+			continue
+		}
+		replies = append(replies, map[string]interface{}{
+			"$type": "app.bsky.feed.defs#threadViewPost",
+			"post": map[string]interface{}{
+				"uri":    reply.URI,
+				"cid":    "bafyrei",
+				"author": map[string]interface{}{"handle": reply.Handle},
+				"record": map[string]interface{}{
+					"$type":     collectionPost,
+					"text":      reply.Text,
+					"createdAt": reply.CreatedAt.UTC().Format(time.RFC3339Nano),
+				},
+				"indexedAt": reply.CreatedAt.UTC().Format(time.RFC3339Nano),
+			},
+			"replies": s.threadReplies(reply.URI, depth-1),
+		})
+	}
+	return replies
+}
+
+func (s *Server) render(post Post) map[string]interface{} {
+	return map[string]interface{}{
+		"uri":    post.URI,
+		"cid":    "bafyrei",
+		"author": map[string]interface{}{"did": s.did, "handle": s.handle},
+		"record": map[string]interface{}{
+			"$type":     collectionPost,
+			"text":      post.Text,
+			"createdAt": post.CreatedAt.UTC().Format(time.RFC3339Nano),
+		},
+		"likeCount":   post.Likes,
+		"repostCount": post.Reposts,
+		"replyCount":  post.Replies,
+		"quoteCount":  post.Quotes,
+		"indexedAt":   post.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+// encodeTID writes a timestamp identifier, the record key format of posts.
+func encodeTID(tid uint64) string {
+	b := make([]byte, 13)
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i] = tidAlphabet[tid&31]
+		tid >>= 5
+	}
+	return string(b)
+}
+
+func writeError(w http.ResponseWriter, status int, name, message string) {
+	writeJSON(w, status, map[string]interface{}{"error": name, "message": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
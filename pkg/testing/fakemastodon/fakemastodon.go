@@ -0,0 +1,462 @@
+// Package fakemastodon serves the parts of the Mastodon API the operator
+// uses, backed by an in-memory account, so clients can be tested over real
+// HTTP.
+package fakemastodon
+
+import (
+	"encoding/json"
+	"html"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Endpoints, named like the operator's Mastodon client names them
+const (
+	EndpointVerifyCredentials = "GET /api/v1/accounts/verify_credentials"
+	EndpointAccountsLookup    = "GET /api/v1/accounts/lookup"
+	EndpointAccountStatuses   = "GET /api/v1/accounts/:id/statuses"
+	EndpointStatusesGet       = "GET /api/v1/statuses/:id"
+	EndpointStatusesContext   = "GET /api/v1/statuses/:id/context"
+	EndpointStatusesCreate    = "POST /api/v1/statuses"
+	EndpointStatusesDelete    = "DELETE /api/v1/statuses/:id"
+	EndpointNotifications     = "GET /api/v1/notifications"
+)
+
+// maxPageSize is the most statuses an account timeline page holds
+const maxPageSize = 40
+
+// idempotencyKeyTTL is how long an Idempotency-Key returns the status it
+// created, which Mastodon keeps even after the status is deleted
+const idempotencyKeyTTL = time.Hour
+
+type Status struct {
+	ID   string
+	Text string
+	// InReplyToID is the status this one was posted in reply to
+	InReplyToID string
+	Favourites  int64
+	Reblogs     int64
+	Replies     int64
+	CreatedAt   time.Time
+}
+
+type idempotentPost struct {
+	status Status
+	at     time.Time
+}
+
+// Reply is a status by another account in the thread below one of the
+// account's statuses, or only mentioning the account when InReplyToID is
+// empty. Replies by other accounts notify the account of a mention.
+type Reply struct {
+	ID          string
+	InReplyToID string
+	Author      string
+	Text        string
+	CreatedAt   time.Time
+}
+
+// Fault replaces the response to one call with an error response.
+// RetryAfter sets the X-RateLimit-Reset header of a 429.
+type Fault struct {
+	StatusCode int
+	Message    string
+	RetryAfter time.Duration
+}
+
+type Server struct {
+	server *httptest.Server
+	URL    string
+
+	mu          sync.Mutex
+	accountID   string
+	username    string
+	accessToken string
+	statuses    []Status
+	replies     []Reply
+	nextID      int64
+	idempotent  map[string]idempotentPost
+	faults      map[string][]Fault
+	requests    []string
+	now         func() time.Time
+}
+
+// New starts a server for the account username, which accepts requests
+// with accessToken as bearer token.
+func New(username, accessToken string) *Server {
+	s := &Server{
+		accountID:   "109000000000000001",
+		username:    username,
+		accessToken: accessToken,
+		nextID:      109000000000001000,
+		idempotent:  map[string]idempotentPost{},
+		faults:      map[string][]Fault{},
+		now:         time.Now,
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+	return s
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// AddStatus puts a status on the account as if it was posted elsewhere.
+func (s *Server) AddStatus(text string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addStatus(text)
+}
+
+func (s *Server) addStatus(text string) string {
+	s.nextID++
+	id := strconv.FormatInt(s.nextID, 10)
+	// Statuses are kept newest first
+	s.statuses = append([]Status{{ID: id, Text: text, CreatedAt: s.now()}}, s.statuses...)
+	return id
+}
+
+// AddReply answers a status, or another reply, as another account. A reply
+// to one of the account's statuses counts in its replies.
+func (s *Server) AddReply(inReplyToID, author, text string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	id := strconv.FormatInt(s.nextID, 10)
+	s.replies = append(s.replies, Reply{ID: id, InReplyToID: inReplyToID, Author: author, Text: text, CreatedAt: s.now()})
+	if i, ok := s.findStatus(inReplyToID); ok {
+		s.statuses[i].Replies++
+	}
+	return id
+}
+
+// AddMention posts a status mentioning the account as another account.
+func (s *Server) AddMention(author, text string) string {
+	return s.AddReply("", author, text)
+}
+
+// SetCounts sets the engagement counts of a status.
+func (s *Server) SetCounts(id string, favourites, reblogs, replies int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.statuses {
+		if s.statuses[i].ID == id {
+			s.statuses[i].Favourites = favourites
+			s.statuses[i].Reblogs = reblogs
+			s.statuses[i].Replies = replies
+		}
+	}
+}
+
+// Statuses returns the account's statuses, newest first.
+func (s *Server) Statuses() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Status{}, s.statuses...)
+}
+
+// InjectFault makes the next calls to the endpoint fail, one fault per call.
+func (s *Server) InjectFault(endpoint string, faults ...Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[endpoint] = append(s.faults[endpoint], faults...)
+}
+
+// Requests returns the endpoints called so far, in order.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.requests...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	endpoint, handler := s.route(r)
+	if handler == nil {
+		writeError(w, http.StatusNotFound, "Record not found")
+		return
+	}
+	s.requests = append(s.requests, endpoint)
+
+	if r.Header.Get("Authorization") != "Bearer "+s.accessToken {
+		writeError(w, http.StatusUnauthorized, "The access token is invalid")
+		return
+	}
+	if faults := s.faults[endpoint]; len(faults) > 0 {
+		s.faults[endpoint] = faults[1:]
+		fault := faults[0]
+		if fault.StatusCode == http.StatusTooManyRequests {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", s.now().Add(fault.RetryAfter).UTC().Format(time.RFC3339Nano))
+		}
+		message := fault.Message
+		if message == "" {
+			message = http.StatusText(fault.StatusCode)
+		}
+		writeError(w, fault.StatusCode, message)
+		return
+	}
+	handler(w, r)
+}
+
+// route maps a request to its endpoint and handler.
+func (s *Server) route(r *http.Request) (string, http.HandlerFunc) {
+	path := r.URL.Path
+	switch {
+	case r.Method == http.MethodGet && path == "/api/v1/accounts/verify_credentials":
+		return EndpointVerifyCredentials, s.verifyCredentials
+	case r.Method == http.MethodGet && path == "/api/v1/accounts/lookup":
+		return EndpointAccountsLookup, s.accountsLookup
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/api/v1/accounts/") && strings.HasSuffix(path, "/statuses"):
+		return EndpointAccountStatuses, s.accountStatuses
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/api/v1/statuses/") && strings.HasSuffix(path, "/context"):
+		return EndpointStatusesContext, s.statusesContext
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/api/v1/statuses/"):
+		return EndpointStatusesGet, s.statusesGet
+	case r.Method == http.MethodPost && path == "/api/v1/statuses":
+		return EndpointStatusesCreate, s.statusesCreate
+	case r.Method == http.MethodDelete && strings.HasPrefix(path, "/api/v1/statuses/"):
+		return EndpointStatusesDelete, s.statusesDelete
+	case r.Method == http.MethodGet && path == "/api/v1/notifications":
+		return EndpointNotifications, s.notifications
+	}
+	return "", nil
+}
+
+func (s *Server) account() map[string]interface{} {
+	return map[string]interface{}{
+		"id":       s.accountID,
+		"username": s.username,
+		"acct":     s.username,
+	}
+}
+
+func (s *Server) verifyCredentials(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.account())
+}
+
+func (s *Server) accountsLookup(w http.ResponseWriter, r *http.Request) {
+	if strings.TrimPrefix(r.URL.Query().Get("acct"), "@") != s.username {
+		writeError(w, http.StatusNotFound, "Record not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.account())
+}
+
+func (s *Server) accountStatuses(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/accounts/"), "/statuses")
+	if id != s.accountID {
+		writeError(w, http.StatusNotFound, "Record not found")
+		return
+	}
+	query := r.URL.Query()
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 || limit > maxPageSize {
+		limit = 20
+	}
+	maxID, _ := strconv.ParseInt(query.Get("max_id"), 10, 64)
+
+	page := []map[string]interface{}{}
+	for _, status := range s.statuses {
+		id, _ := strconv.ParseInt(status.ID, 10, 64)
+		if maxID > 0 && id >= maxID {
+			continue
+		}
+		if len(page) == limit {
+			break
+		}
+		page = append(page, s.render(status))
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) statusesGet(w http.ResponseWriter, r *http.Request) {
+	i, ok := s.findStatus(strings.TrimPrefix(r.URL.Path, "/api/v1/statuses/"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "Record not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.render(s.statuses[i]))
+}
+
+// statusesContext returns the replies below a status, in the order they
+// were added.
+func (s *Server) statusesContext(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/statuses/"), "/context")
+	if _, ok := s.findStatus(id); !ok {
+		writeError(w, http.StatusNotFound, "Record not found")
+		return
+	}
+	thread := map[string]bool{id: true}
+	descendants := []map[string]interface{}{}
+	for _, reply := range s.replies {
+		if !thread[reply.InReplyToID] {
+			continue
+		}
+		thread[reply.ID] = true
+		descendants = append(descendants, renderReply(reply))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"ancestors":   []interface{}{},
+		"descendants": descendants,
+	})
+}
+
+// notifications lists a mention notification for each reply by another
+// account, newest first. The notification IDs are those of the replies.
+// Only types[]=mention is supported.
+func (s *Server) notifications(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if types := query["types[]"]; len(types) != 1 || types[0] != "mention" {
+		writeError(w, http.StatusUnprocessableEntity, "Only mention notifications are supported")
+		return
+	}
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 || limit > maxPageSize {
+		limit = 20
+	}
+	sinceID, _ := strconv.ParseInt(query.Get("since_id"), 10, 64)
+
+	notifications := []map[string]interface{}{}
+	for i := len(s.replies) - 1; i >= 0 && len(notifications) < limit; i-- {
+		reply := s.replies[i]
+		id, _ := strconv.ParseInt(reply.ID, 10, 64)
+		if id <= sinceID || reply.Author == s.username {
+			continue
+		}
+		notifications = append(notifications, map[string]interface{}{
+			"id":         reply.ID,
+			"type":       "mention",
+			"created_at": reply.CreatedAt.UTC().Format(time.RFC3339Nano),
+			"account":    replyAccount(reply),
+			"status":     renderReply(reply),
+		})
+	}
+	writeJSON(w, http.StatusOK, notifications)
+}
+
+func (s *Server) statusesCreate(w http.ResponseWriter, r *http.Request) {
+	var text, inReplyToID string
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/json" {
+		var body struct {
+			Status      string `json:"status"`
+			InReplyToID string `json:"in_reply_to_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		text, inReplyToID = body.Status, body.InReplyToID
+	} else {
+		text, inReplyToID = r.FormValue("status"), r.FormValue("in_reply_to_id")
+	}
+	if strings.TrimSpace(text) == "" {
+		writeError(w, http.StatusUnprocessableEntity, "Validation failed: Text can't be blank")
+		return
+	}
+
+	// A repeated Idempotency-Key returns the status it created before,
+	// whatever the text of the repeated request
+	key := r.Header.Get("Idempotency-Key")
+	if post, ok := s.idempotent[key]; ok && key != "" && s.now().Sub(post.at) < idempotencyKeyTTL {
+		writeJSON(w, http.StatusOK, s.render(post.status))
+		return
+	}
+	id := s.addStatus(text)
+	i, _ := s.findStatus(id)
+	s.statuses[i].InReplyToID = inReplyToID
+	if key != "" {
+		s.idempotent[key] = idempotentPost{status: s.statuses[i], at: s.now()}
+	}
+	writeJSON(w, http.StatusOK, s.render(s.statuses[i]))
+}
+
+func (s *Server) statusesDelete(w http.ResponseWriter, r *http.Request) {
+	i, ok := s.findStatus(strings.TrimPrefix(r.URL.Path, "/api/v1/statuses/"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "Record not found")
+		return
+	}
+	deleted := s.render(s.statuses[i])
+	// Deleted statuses come back with their source text, to redraft them
+	deleted["text"] = s.statuses[i].Text
+	s.statuses = append(s.statuses[:i], s.statuses[i+1:]...)
+	writeJSON(w, http.StatusOK, deleted)
+}
+
+func (s *Server) findStatus(id string) (int, bool) {
+	for i, status := range s.statuses {
+		if status.ID == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (s *Server) render(status Status) map[string]interface{} {
+	return map[string]interface{}{
+		"id":               status.ID,
+		"created_at":       status.CreatedAt.UTC().Format(time.RFC3339Nano),
+		"content":          renderContent(status.Text),
+		"visibility":       "public",
+		"url":              "https://mastodon.example/@" + s.username + "/" + status.ID,
+		"favourites_count": status.Favourites,
+		"reblogs_count":    status.Reblogs,
+		"replies_count":    status.Replies,
+		"account":          s.account(),
+	}
+}
+
+func renderReply(reply Reply) map[string]interface{} {
+	return map[string]interface{}{
+		"id":             reply.ID,
+		"in_reply_to_id": reply.InReplyToID,
+		"created_at":     reply.CreatedAt.UTC().Format(time.RFC3339Nano),
+		"content":        renderContent(reply.Text),
+		"visibility":     "public",
+		"url":            "https://mastodon.example/@" + reply.Author + "/" + reply.ID,
+		"account":        replyAccount(reply),
+	}
+}
+
+func replyAccount(reply Reply) map[string]interface{} {
+	return map[string]interface{}{"id": "1", "username": reply.Author, "acct": reply.Author}
+}
+
+var linkPattern = regexp.MustCompile(`https?://[^\s<]+`)
+
+// renderContent turns status text into HTML the way Mastodon does:
+// paragraphs, line breaks, and links with the scheme in a hidden span.
+func renderContent(text string) string {
+	paragraphs := []string{}
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		escaped := html.EscapeString(paragraph)
+		escaped = linkPattern.ReplaceAllStringFunc(escaped, func(link string) string {
+			scheme, rest, _ := strings.Cut(link, "://")
+			return `<a href="` + link + `" rel="nofollow noopener noreferrer" target="_blank">` +
+				`<span class="invisible">` + scheme + `://</span><span class="">` + rest + `</span></a>`
+		})
+		paragraphs = append(paragraphs, "<p>"+strings.ReplaceAll(escaped, "\n", "<br />")+"</p>")
+	}
+	return strings.Join(paragraphs, "")
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{"error": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
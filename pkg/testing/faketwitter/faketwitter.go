@@ -0,0 +1,451 @@
+// Package faketwitter serves the parts of the Twitter API v1.1 and v2 the
+// operator uses, backed by an in-memory timeline, so clients can be tested
+// over real HTTP.
+package faketwitter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dghubble/oauth1"
+)
+
+// APIHost is the host the server stands in for. Clients from Client and
+// OAuth1Client send requests for it to the server instead.
+const APIHost = "api.twitter.com"
+
+// Endpoints, named like the operator's rate limiter names them
+const (
+	EndpointVerifyCredentials        = "account/verify_credentials"
+	EndpointStatusesUpdate           = "statuses/update"
+	EndpointStatusesDestroy          = "statuses/destroy"
+	EndpointStatusesUserTimeline     = "statuses/user_timeline"
+	EndpointStatusesMentionsTimeline = "statuses/mentions_timeline"
+	EndpointV2UsersMe                = "GET /2/users/me"
+	EndpointV2UsersByUsername        = "GET /2/users/by/username/:username"
+	EndpointV2UsersTweets            = "GET /2/users/:id/tweets"
+	EndpointV2UsersMentions          = "GET /2/users/:id/mentions"
+	EndpointV2TweetsLookup           = "GET /2/tweets"
+	EndpointV2TweetsCreate           = "POST /2/tweets"
+	EndpointV2TweetsDelete           = "DELETE /2/tweets/:id"
+	EndpointV2SearchRecent           = "GET /2/tweets/search/recent"
+)
+
+// Reply is a tweet by another user answering one on the timeline, or only
+// mentioning the user when InReplyTo is 0. Replies are found through v2
+// recent search, and all of them are on the mentions timelines.
+type Reply struct {
+	ID        int64
+	InReplyTo int64
+	Author    string
+	Text      string
+	CreatedAt time.Time
+}
+
+// Credentials the server accepts OAuth 1.0a signatures from
+type Credentials struct {
+	ConsumerKey       string
+	ConsumerSecret    string
+	AccessToken       string
+	AccessTokenSecret string
+}
+
+type Tweet struct {
+	ID   int64
+	Text string
+	// InReplyTo is the tweet this one was posted in reply to
+	InReplyTo int64
+	Likes     int64
+	Retweets  int64
+	Replies   int64
+	ExtendedMetrics
+	CreatedAt time.Time
+}
+
+// ExtendedMetrics are the metrics beyond likes, retweets and replies. Only
+// v2 has all of them, and only renders the non-public ones when asked.
+type ExtendedMetrics struct {
+	Quotes    int64
+	Bookmarks int64
+	// Non-public
+	Impressions   int64
+	URLLinkClicks int64
+	ProfileClicks int64
+}
+
+// Fault replaces the response to one call. A Fault with Drop set closes the
+// connection without a response. Otherwise the status code, and the error
+// code and message for v1.1, are returned in the endpoint's error format.
+type Fault struct {
+	StatusCode int
+	Code       int
+	Message    string
+	Drop       bool
+}
+
+type rateLimit struct {
+	limit     int
+	window    time.Duration
+	remaining int
+	reset     time.Time
+}
+
+type Server struct {
+	server *httptest.Server
+	URL    string
+
+	mu           sync.Mutex
+	userID       int64
+	screenName   string
+	credentials  Credentials
+	bearerTokens map[string]bool
+	nonces       map[string]bool
+	tweets       []Tweet
+	replies      []Reply
+	nextID       int64
+	limits       map[string]*rateLimit
+	faults       map[string][]Fault
+	requests     []string
+	now          func() time.Time
+}
+
+// New starts a server for the account screenName, which accepts requests
+// signed with creds.
+func New(screenName string, creds Credentials) *Server {
+	s := &Server{
+		userID:       4200,
+		screenName:   screenName,
+		credentials:  creds,
+		bearerTokens: map[string]bool{},
+		nonces:       map[string]bool{},
+		nextID:       1000,
+		limits:       map[string]*rateLimit{},
+		faults:       map[string][]Fault{},
+		now:          time.Now,
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+	return s
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Client returns a client without authorization that sends requests for
+// APIHost to the server.
+func (s *Server) Client() *http.Client {
+	target, _ := url.Parse(s.URL)
+	return &http.Client{Transport: &redirectTransport{target: target.Host}}
+}
+
+// OAuth1Client returns a client that signs requests with the server's
+// credentials and sends them to the server.
+func (s *Server) OAuth1Client() *http.Client {
+	return s.OAuth1ClientFor(s.credentials)
+}
+
+// OAuth1ClientFor returns a client that signs requests with other
+// credentials, to test how the server rejects them.
+func (s *Server) OAuth1ClientFor(creds Credentials) *http.Client {
+	config := oauth1.NewConfig(creds.ConsumerKey, creds.ConsumerSecret)
+	token := oauth1.NewToken(creds.AccessToken, creds.AccessTokenSecret)
+	ctx := context.WithValue(context.Background(), oauth1.HTTPClient, s.Client())
+	return config.Client(ctx, token)
+}
+
+// redirectTransport sends requests for APIHost to the server. The Host
+// header is kept, so signatures made for APIHost still verify.
+type redirectTransport struct {
+	target string
+}
+
+func (t *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != APIHost {
+		return http.DefaultTransport.RoundTrip(req)
+	}
+	redirected := req.Clone(req.Context())
+	redirected.URL.Scheme = "http"
+	redirected.URL.Host = t.target
+	redirected.Host = APIHost
+	return http.DefaultTransport.RoundTrip(redirected)
+}
+
+// AddBearerToken makes the server accept an OAuth 2.0 access token.
+func (s *Server) AddBearerToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bearerTokens[token] = true
+}
+
+// AddTweet puts a tweet on the timeline as if it was posted elsewhere.
+func (s *Server) AddTweet(text string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addTweet(text)
+}
+
+func (s *Server) addTweet(text string) int64 {
+	s.nextID++
+	// The timeline is kept newest first
+	s.tweets = append([]Tweet{{ID: s.nextID, Text: text, CreatedAt: s.now()}}, s.tweets...)
+	return s.nextID
+}
+
+// AddReply answers a tweet on the timeline as another user, and counts the
+// reply in the tweet's metrics.
+func (s *Server) AddReply(inReplyTo int64, author, text string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	s.replies = append(s.replies, Reply{ID: s.nextID, InReplyTo: inReplyTo, Author: author, Text: text, CreatedAt: s.now()})
+	if i, found := s.findTweet(inReplyTo); found {
+		s.tweets[i].Replies++
+	}
+	return s.nextID
+}
+
+// AddMention posts a tweet mentioning the user as another user.
+func (s *Server) AddMention(author, text string) int64 {
+	return s.AddReply(0, author, text)
+}
+
+// SetMetrics sets the engagement counts of a tweet.
+func (s *Server) SetMetrics(id, likes, retweets, replies int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.tweets {
+		if s.tweets[i].ID == id {
+			s.tweets[i].Likes = likes
+			s.tweets[i].Retweets = retweets
+			s.tweets[i].Replies = replies
+		}
+	}
+}
+
+// SetExtendedMetrics sets the metrics of a tweet beyond those of SetMetrics.
+func (s *Server) SetExtendedMetrics(id int64, metrics ExtendedMetrics) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.tweets {
+		if s.tweets[i].ID == id {
+			s.tweets[i].ExtendedMetrics = metrics
+		}
+	}
+}
+
+// Tweets returns the timeline, newest first.
+func (s *Server) Tweets() []Tweet {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Tweet{}, s.tweets...)
+}
+
+// SetRateLimit allows limit calls to the endpoint per window. Endpoints
+// without a limit never answer 429.
+func (s *Server) SetRateLimit(endpoint string, limit int, window time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limits[endpoint] = &rateLimit{
+		limit:     limit,
+		window:    window,
+		remaining: limit,
+		reset:     s.now().Add(window),
+	}
+}
+
+// InjectFault makes the next calls to the endpoint fail, one fault per call.
+func (s *Server) InjectFault(endpoint string, faults ...Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[endpoint] = append(s.faults[endpoint], faults...)
+}
+
+// Requests returns the endpoints called so far, in order.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.requests...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v2 := strings.HasPrefix(r.URL.Path, "/2/")
+	endpoint, handler := s.route(r)
+	if handler == nil {
+		s.writeError(w, v2, http.StatusNotFound, 34, "Sorry, that page does not exist.")
+		return
+	}
+	s.requests = append(s.requests, endpoint)
+
+	if !s.authorized(r) {
+		s.writeError(w, v2, http.StatusUnauthorized, 32, "Could not authenticate you.")
+		return
+	}
+	if faults := s.faults[endpoint]; len(faults) > 0 {
+		s.faults[endpoint] = faults[1:]
+		s.writeFault(w, v2, faults[0])
+		return
+	}
+	if !s.allow(w, endpoint) {
+		s.writeError(w, v2, http.StatusTooManyRequests, 88, "Rate limit exceeded")
+		return
+	}
+	handler(w, r)
+}
+
+// route maps a request to its endpoint and handler.
+func (s *Server) route(r *http.Request) (string, http.HandlerFunc) {
+	path := r.URL.Path
+	switch {
+	case r.Method == http.MethodGet && path == "/1.1/account/verify_credentials.json":
+		return EndpointVerifyCredentials, s.verifyCredentials
+	case r.Method == http.MethodPost && path == "/1.1/statuses/update.json":
+		return EndpointStatusesUpdate, s.statusesUpdate
+	case r.Method == http.MethodPost && strings.HasPrefix(path, "/1.1/statuses/destroy/"):
+		return EndpointStatusesDestroy, s.statusesDestroy
+	case r.Method == http.MethodGet && path == "/1.1/statuses/user_timeline.json":
+		return EndpointStatusesUserTimeline, s.statusesUserTimeline
+	case r.Method == http.MethodGet && path == "/1.1/statuses/mentions_timeline.json":
+		return EndpointStatusesMentionsTimeline, s.statusesMentionsTimeline
+	case r.Method == http.MethodGet && path == "/2/users/me":
+		return EndpointV2UsersMe, s.v2UsersMe
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/2/users/by/username/"):
+		return EndpointV2UsersByUsername, s.v2UsersByUsername
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/2/users/") && strings.HasSuffix(path, "/tweets"):
+		return EndpointV2UsersTweets, s.v2UsersTweets
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/2/users/") && strings.HasSuffix(path, "/mentions"):
+		return EndpointV2UsersMentions, s.v2UsersMentions
+	case r.Method == http.MethodGet && path == "/2/tweets/search/recent":
+		return EndpointV2SearchRecent, s.v2SearchRecent
+	case r.Method == http.MethodGet && path == "/2/tweets":
+		return EndpointV2TweetsLookup, s.v2TweetsLookup
+	case r.Method == http.MethodPost && path == "/2/tweets":
+		return EndpointV2TweetsCreate, s.v2TweetsCreate
+	case r.Method == http.MethodDelete && strings.HasPrefix(path, "/2/tweets/"):
+		return EndpointV2TweetsDelete, s.v2TweetsDelete
+	}
+	return "", nil
+}
+
+// allow counts the call against the endpoint's rate limit and sets the
+// x-rate-limit headers.
+func (s *Server) allow(w http.ResponseWriter, endpoint string) bool {
+	limit, ok := s.limits[endpoint]
+	if !ok {
+		return true
+	}
+	now := s.now()
+	if !now.Before(limit.reset) {
+		limit.remaining = limit.limit
+		limit.reset = now.Add(limit.window)
+	}
+	allowed := limit.remaining > 0
+	if allowed {
+		limit.remaining--
+	}
+	w.Header().Set("x-rate-limit-limit", strconv.Itoa(limit.limit))
+	w.Header().Set("x-rate-limit-remaining", strconv.Itoa(limit.remaining))
+	w.Header().Set("x-rate-limit-reset", strconv.FormatInt(limit.reset.Unix(), 10))
+	return allowed
+}
+
+func (s *Server) writeFault(w http.ResponseWriter, v2 bool, fault Fault) {
+	if fault.Drop {
+		if hijacker, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hijacker.Hijack(); err == nil {
+				conn.Close()
+				return
+			}
+		}
+		fault.StatusCode = http.StatusBadGateway
+	}
+	message := fault.Message
+	if message == "" {
+		message = http.StatusText(fault.StatusCode)
+	}
+	s.writeError(w, v2, fault.StatusCode, fault.Code, message)
+}
+
+// writeError answers in the error format of the API version, an errors
+// list for v1.1 and a problem document for v2.
+func (s *Server) writeError(w http.ResponseWriter, v2 bool, status, code int, message string) {
+	if v2 {
+		writeJSON(w, status, map[string]interface{}{
+			"title":  http.StatusText(status),
+			"detail": message,
+			"type":   "about:blank",
+			"status": status,
+		})
+		return
+	}
+	writeJSON(w, status, map[string]interface{}{
+		"errors": []map[string]interface{}{{"code": code, "message": message}},
+	})
+}
+
+func (s *Server) findTweet(id int64) (int, bool) {
+	for i, t := range s.tweets {
+		if t.ID == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (s *Server) isDuplicate(text string) bool {
+	for _, t := range s.tweets {
+		if t.Text == text {
+			return true
+		}
+	}
+	return false
+}
+
+// page returns up to count tweets with sinceID < ID <= maxID, newest first.
+// A maxID of 0 means no upper bound.
+func (s *Server) page(sinceID, maxID int64, count int) []Tweet {
+	page := []Tweet{}
+	for _, t := range s.tweets {
+		if t.ID <= sinceID || (maxID > 0 && t.ID > maxID) {
+			continue
+		}
+		if len(page) == count {
+			break
+		}
+		page = append(page, t)
+	}
+	return page
+}
+
+// mentions returns up to count replies with ID > sinceID, newest first.
+func (s *Server) mentions(sinceID int64, count int) []Reply {
+	mentions := []Reply{}
+	for i := len(s.replies) - 1; i >= 0 && len(mentions) < count; i-- {
+		if s.replies[i].ID > sinceID {
+			mentions = append(mentions, s.replies[i])
+		}
+	}
+	return mentions
+}
+
+func intParam(query url.Values, key string, defaultValue int64) (int64, error) {
+	value := query.Get(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", key, value)
+	}
+	return i, nil
+}
@@ -0,0 +1,323 @@
+package faketwitter_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jonatanblue/tweet-operator/pkg/libs/twitterclient"
+	"github.com/jonatanblue/tweet-operator/pkg/testing/faketwitter"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var creds = faketwitter.Credentials{
+	ConsumerKey:       "consumer-key",
+	ConsumerSecret:    "consumer-secret",
+	AccessToken:       "access-token",
+	AccessTokenSecret: "access-token-secret",
+}
+
+// client is what the operator uses, for either API version
+type client interface {
+	GetTweetsForUser(ctx context.Context, userName string, trackedIDs ...int64) (tweettypes.Tweets, error)
+	PostTweet(ctx context.Context, tweet *tweettypes.Tweet) (int64, error)
+	DeleteTweet(ctx context.Context, tweet *tweettypes.Tweet) error
+	GetMentions(ctx context.Context, userName, sinceID string) ([]tweettypes.Mention, error)
+}
+
+func newV1Client(httpClient *http.Client) client {
+	goTwitterClient := twitterclient.NewGoTwitterClient(httpClient)
+	return twitterclient.NewTwitterClient(goTwitterClient, goTwitterClient, twitterclient.DefaultTimelineMaxPages, 0)
+}
+
+func newV2Client(httpClient *http.Client) client {
+	return twitterclient.NewTwitterV2Client(httpClient, twitterclient.DefaultV2BaseURL, twitterclient.DefaultTimelineMaxPages, 0, false)
+}
+
+var clients = map[string]func(*http.Client) client{
+	"v1.1": newV1Client,
+	"v2":   newV2Client,
+}
+
+func texts(tweets tweettypes.Tweets) []string {
+	result := []string{}
+	for _, t := range tweets {
+		result = append(result, t.Spec.Text)
+	}
+	return result
+}
+
+func Test_Lifecycle(t *testing.T) {
+	for version, newClient := range clients {
+		t.Run(version, func(t *testing.T) {
+			server := faketwitter.New("bob", creds)
+			defer server.Close()
+			existing := server.AddTweet("Existing tweet")
+			server.SetMetrics(existing, 1, 2, 3)
+			c := newClient(server.OAuth1Client())
+
+			id, err := c.PostTweet(context.TODO(), &tweettypes.Tweet{Spec: tweettypes.TweetSpec{Text: "Hello World"}})
+			assert.NoError(t, err)
+
+			tweets, err := c.GetTweetsForUser(context.TODO(), "bob", existing)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"Hello World", "Existing tweet"}, texts(tweets))
+			assert.Equal(t, tweettypes.TweetStatus{ID: existing, URL: twitterclient.TweetURL("bob", existing), Likes: 1, Retweets: 2, Replies: 3}, tweets[1].Status)
+
+			_, err = c.PostTweet(context.TODO(), &tweettypes.Tweet{Spec: tweettypes.TweetSpec{Text: "Hello World"}})
+			assert.True(t, twitterclient.IsDuplicate(err), "duplicate: %v", err)
+
+			err = c.DeleteTweet(context.TODO(), &tweettypes.Tweet{Status: tweettypes.TweetStatus{ID: id}})
+			assert.NoError(t, err)
+			assert.Len(t, server.Tweets(), 1)
+		})
+	}
+}
+
+func Test_OAuthSignatures(t *testing.T) {
+	wrong := creds
+	wrong.ConsumerSecret = "wrong"
+
+	for version, newClient := range clients {
+		t.Run(version, func(t *testing.T) {
+			server := faketwitter.New("bob", creds)
+			defer server.Close()
+
+			tests := map[string]struct {
+				httpClient *http.Client
+				authorized bool
+			}{
+				"signed":          {httpClient: server.OAuth1Client(), authorized: true},
+				"wrong secret":    {httpClient: server.OAuth1ClientFor(wrong)},
+				"unsigned":        {httpClient: server.Client()},
+				"unknown bearer":  {httpClient: bearerClient(server, "unknown")},
+				"accepted bearer": {httpClient: bearerClient(server, "token"), authorized: true},
+			}
+			server.AddBearerToken("token")
+
+			for name, test := range tests {
+				t.Run(name, func(t *testing.T) {
+					_, err := newClient(test.httpClient).PostTweet(context.TODO(), &tweettypes.Tweet{Spec: tweettypes.TweetSpec{Text: name}})
+					if test.authorized {
+						assert.NoError(t, err)
+					} else {
+						assert.True(t, twitterclient.IsUnauthorized(err), "unauthorized: %v", err)
+					}
+				})
+			}
+		})
+	}
+}
+
+type bearerTransport struct {
+	token string
+	base  http.RoundTripper
+}
+
+func (t *bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	return t.base.RoundTrip(req)
+}
+
+func bearerClient(server *faketwitter.Server, token string) *http.Client {
+	return &http.Client{Transport: &bearerTransport{token: token, base: server.Client().Transport}}
+}
+
+func Test_RateLimits(t *testing.T) {
+	endpoints := map[string]string{
+		"v1.1": faketwitter.EndpointStatusesUpdate,
+		"v2":   faketwitter.EndpointV2TweetsCreate,
+	}
+	for version, newClient := range clients {
+		t.Run(version, func(t *testing.T) {
+			server := faketwitter.New("bob", creds)
+			defer server.Close()
+			server.SetRateLimit(endpoints[version], 1, 15*time.Minute)
+			c := newClient(server.OAuth1Client())
+
+			_, err := c.PostTweet(context.TODO(), &tweettypes.Tweet{Spec: tweettypes.TweetSpec{Text: "First"}})
+			assert.NoError(t, err)
+
+			// The client saw remaining=0 and does not even try
+			_, err = c.PostTweet(context.TODO(), &tweettypes.Tweet{Spec: tweettypes.TweetSpec{Text: "Second"}})
+			assert.True(t, twitterclient.IsRateLimited(err), "rate limited: %v", err)
+
+			// A client that does not know about the limit gets a 429
+			_, err = newClient(server.OAuth1Client()).PostTweet(context.TODO(), &tweettypes.Tweet{Spec: tweettypes.TweetSpec{Text: "Third"}})
+			var rateLimitErr *twitterclient.RateLimitError
+			assert.ErrorAs(t, err, &rateLimitErr)
+			assert.WithinDuration(t, time.Now().Add(15*time.Minute), rateLimitErr.Reset, time.Minute)
+
+			assert.Len(t, server.Tweets(), 1)
+		})
+	}
+}
+
+func Test_Faults(t *testing.T) {
+	endpoints := map[string]string{
+		"v1.1": faketwitter.EndpointStatusesUserTimeline,
+		"v2":   faketwitter.EndpointV2UsersTweets,
+	}
+	for version, newClient := range clients {
+		t.Run(version, func(t *testing.T) {
+			server := faketwitter.New("bob", creds)
+			defer server.Close()
+			server.AddTweet("Hello World")
+			server.InjectFault(
+				endpoints[version],
+				faketwitter.Fault{StatusCode: http.StatusServiceUnavailable, Code: 130, Message: "Over capacity"},
+				faketwitter.Fault{Drop: true},
+			)
+			c := newClient(server.OAuth1Client())
+
+			_, err := c.GetTweetsForUser(context.TODO(), "bob")
+			assert.ErrorContains(t, err, "Over capacity")
+
+			_, err = c.GetTweetsForUser(context.TODO(), "bob")
+			assert.Error(t, err)
+
+			tweets, err := c.GetTweetsForUser(context.TODO(), "bob")
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"Hello World"}, texts(tweets))
+		})
+	}
+}
+
+func Test_DirectURL(t *testing.T) {
+	server := faketwitter.New("bob", creds)
+	defer server.Close()
+	httpClient := twitterclient.NewOAuth1HTTPClient(&twitterclient.Credentials{
+		ConsumerKey:       creds.ConsumerKey,
+		ConsumerSecret:    creds.ConsumerSecret,
+		AccessToken:       creds.AccessToken,
+		AccessTokenSecret: creds.AccessTokenSecret,
+	})
+	c := twitterclient.NewTwitterV2Client(httpClient, server.URL+"/2/", twitterclient.DefaultTimelineMaxPages, 0, false)
+
+	assert.NoError(t, c.VerifyCredentials(context.TODO()))
+	_, err := c.PostTweet(context.TODO(), &tweettypes.Tweet{Spec: tweettypes.TweetSpec{Text: "Hello World"}})
+	assert.NoError(t, err)
+	assert.Len(t, server.Tweets(), 1)
+}
+
+func Test_V2Metrics(t *testing.T) {
+	tests := map[string]struct {
+		nonPublicMetrics bool
+		expected         tweettypes.TweetStatus
+	}{
+		"public metrics": {
+			expected: tweettypes.TweetStatus{Likes: 1, Retweets: 2, Replies: 3, Quotes: 4, Bookmarks: 5},
+		},
+		"non-public metrics": {
+			nonPublicMetrics: true,
+			expected: tweettypes.TweetStatus{
+				Likes:         1,
+				Retweets:      2,
+				Replies:       3,
+				Quotes:        4,
+				Bookmarks:     5,
+				Impressions:   60,
+				URLLinkClicks: 7,
+				ProfileClicks: 8,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := faketwitter.New("bob", creds)
+			defer server.Close()
+			id := server.AddTweet("Hello World")
+			server.SetMetrics(id, 1, 2, 3)
+			server.SetExtendedMetrics(id, faketwitter.ExtendedMetrics{
+				Quotes:        4,
+				Bookmarks:     5,
+				Impressions:   60,
+				URLLinkClicks: 7,
+				ProfileClicks: 8,
+			})
+			c := twitterclient.NewTwitterV2Client(server.OAuth1Client(), twitterclient.DefaultV2BaseURL, twitterclient.DefaultTimelineMaxPages, 0, test.nonPublicMetrics)
+
+			tweets, err := c.GetTweetsForUser(context.TODO(), "bob")
+			assert.NoError(t, err)
+			assert.Len(t, tweets, 1)
+			test.expected.ID = id
+			test.expected.URL = twitterclient.TweetURL("bob", id)
+			assert.Equal(t, test.expected, tweets[0].Status)
+		})
+	}
+}
+
+func Test_V2Replies(t *testing.T) {
+	server := faketwitter.New("bob", creds)
+	defer server.Close()
+	id := server.AddTweet("Hello World")
+	other := server.AddTweet("Other")
+	first := server.AddReply(id, "alice", "@bob Hi")
+	server.AddReply(other, "carol", "@bob Elsewhere")
+	second := server.AddReply(id, "carol", "@bob Hello")
+	c := twitterclient.NewTwitterV2Client(server.OAuth1Client(), twitterclient.DefaultV2BaseURL, twitterclient.DefaultTimelineMaxPages, 0, false)
+
+	replies, err := c.GetReplies(context.TODO(), &tweettypes.Tweet{Status: tweettypes.TweetStatus{ID: id}}, 5)
+	assert.NoError(t, err)
+	authors := []string{}
+	ids := []string{}
+	for _, r := range replies {
+		authors = append(authors, r.Author)
+		ids = append(ids, r.ID)
+		assert.False(t, r.Time.IsZero())
+	}
+	assert.Equal(t, []string{"@carol", "@alice"}, authors)
+	assert.Equal(t, []string{strconv.FormatInt(second, 10), strconv.FormatInt(first, 10)}, ids)
+	assert.Equal(t, int64(2), server.Tweets()[1].Replies)
+}
+
+func Test_Mentions(t *testing.T) {
+	for version, newClient := range clients {
+		t.Run(version, func(t *testing.T) {
+			server := faketwitter.New("bob", creds)
+			defer server.Close()
+			id := server.AddTweet("Hello World")
+			first := server.AddMention("alice", "@bob Hi")
+			server.AddReply(id, "carol", "@bob Hello")
+			c := newClient(server.OAuth1Client())
+
+			mentions, err := c.GetMentions(context.TODO(), "bob", "")
+			assert.NoError(t, err)
+			authors := []string{}
+			for _, m := range mentions {
+				authors = append(authors, m.Author)
+				assert.False(t, m.Time.IsZero())
+			}
+			assert.Equal(t, []string{"@carol", "@alice"}, authors)
+			assert.Equal(t, "https://twitter.com/alice/status/"+strconv.FormatInt(first, 10), mentions[1].URL)
+
+			mentions, err = c.GetMentions(context.TODO(), "bob", mentions[0].ID)
+			assert.NoError(t, err)
+			assert.Empty(t, mentions)
+		})
+	}
+}
+
+func Test_PostReply(t *testing.T) {
+	for version, newClient := range clients {
+		t.Run(version, func(t *testing.T) {
+			server := faketwitter.New("bob", creds)
+			defer server.Close()
+			mention := server.AddMention("alice", "@bob Hi")
+			c := newClient(server.OAuth1Client())
+
+			id, err := c.PostTweet(context.TODO(), &tweettypes.Tweet{Spec: tweettypes.TweetSpec{
+				Text:      "@alice Hello",
+				InReplyTo: strconv.FormatInt(mention, 10),
+			}})
+			assert.NoError(t, err)
+			assert.Equal(t, id, server.Tweets()[0].ID)
+			assert.Equal(t, mention, server.Tweets()[0].InReplyTo)
+		})
+	}
+}
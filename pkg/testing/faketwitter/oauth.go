@@ -0,0 +1,110 @@
+package faketwitter
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/dghubble/oauth1"
+)
+
+// authorized accepts a known OAuth 2.0 bearer token, or an OAuth 1.0a
+// HMAC-SHA1 signature made with the server's credentials, as described in
+// RFC 5849. Each nonce is only accepted once.
+func (s *Server) authorized(r *http.Request) bool {
+	header := r.Header.Get("Authorization")
+	if token := strings.TrimPrefix(header, "Bearer "); token != header {
+		return s.bearerTokens[token]
+	}
+
+	params, ok := parseOAuthHeader(header)
+	if !ok {
+		return false
+	}
+	if params["oauth_consumer_key"] != s.credentials.ConsumerKey ||
+		params["oauth_token"] != s.credentials.AccessToken ||
+		params["oauth_signature_method"] != "HMAC-SHA1" {
+		return false
+	}
+	nonce := params["oauth_nonce"]
+	if nonce == "" || s.nonces[nonce] {
+		return false
+	}
+
+	signature := params["oauth_signature"]
+	delete(params, "oauth_signature")
+	delete(params, "realm")
+	for key, values := range r.URL.Query() {
+		params[key] = values[0]
+	}
+	if r.Header.Get("Content-Type") == "application/x-www-form-urlencoded" {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return false
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return false
+		}
+		for key, values := range form {
+			params[key] = values[0]
+		}
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(s.sign(r, params))) {
+		return false
+	}
+	s.nonces[nonce] = true
+	return true
+}
+
+func (s *Server) sign(r *http.Request, params map[string]string) string {
+	// Requests redirected from the real API were signed for https
+	scheme := "http"
+	if r.Host == APIHost {
+		scheme = "https"
+	}
+	baseURL := scheme + "://" + strings.ToLower(r.Host) + r.URL.EscapedPath()
+
+	pairs := []string{}
+	for key, value := range params {
+		pairs = append(pairs, oauth1.PercentEncode(key)+"="+oauth1.PercentEncode(value))
+	}
+	sort.Strings(pairs)
+	base := strings.Join([]string{
+		r.Method,
+		oauth1.PercentEncode(baseURL),
+		oauth1.PercentEncode(strings.Join(pairs, "&")),
+	}, "&")
+
+	mac := hmac.New(sha1.New, []byte(s.credentials.ConsumerSecret+"&"+s.credentials.AccessTokenSecret))
+	mac.Write([]byte(base))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func parseOAuthHeader(header string) (map[string]string, bool) {
+	rest := strings.TrimPrefix(header, "OAuth ")
+	if rest == header {
+		return nil, false
+	}
+	params := map[string]string{}
+	for _, pair := range strings.Split(rest, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, false
+		}
+		value, err := url.PathUnescape(strings.Trim(value, `"`))
+		if err != nil {
+			return nil, false
+		}
+		params[key] = value
+	}
+	return params, true
+}
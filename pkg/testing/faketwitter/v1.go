@@ -0,0 +1,149 @@
+package faketwitter
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// createdAtLayout is the timestamp format of the v1.1 API
+const createdAtLayout = "Mon Jan 02 15:04:05 -0700 2006"
+
+const maxTimelineCount = 200
+
+type v1User struct {
+	ID         int64  `json:"id"`
+	IDStr      string `json:"id_str"`
+	ScreenName string `json:"screen_name"`
+}
+
+type v1Tweet struct {
+	ID            int64   `json:"id"`
+	IDStr         string  `json:"id_str"`
+	Text          string  `json:"text"`
+	CreatedAt     string  `json:"created_at"`
+	FavoriteCount int64   `json:"favorite_count"`
+	RetweetCount  int64   `json:"retweet_count"`
+	ReplyCount    int64   `json:"reply_count"`
+	QuoteCount    int64   `json:"quote_count"`
+	User          *v1User `json:"user"`
+}
+
+func (s *Server) v1User() *v1User {
+	return &v1User{
+		ID:         s.userID,
+		IDStr:      strconv.FormatInt(s.userID, 10),
+		ScreenName: s.screenName,
+	}
+}
+
+func (s *Server) v1Tweet(t Tweet) v1Tweet {
+	return v1Tweet{
+		ID:            t.ID,
+		IDStr:         strconv.FormatInt(t.ID, 10),
+		Text:          t.Text,
+		CreatedAt:     t.CreatedAt.Format(createdAtLayout),
+		FavoriteCount: t.Likes,
+		RetweetCount:  t.Retweets,
+		ReplyCount:    t.Replies,
+		QuoteCount:    t.Quotes,
+		User:          s.v1User(),
+	}
+}
+
+func (s *Server) verifyCredentials(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.v1User())
+}
+
+func (s *Server) statusesUpdate(w http.ResponseWriter, r *http.Request) {
+	text := r.PostFormValue("status")
+	if text == "" {
+		s.writeError(w, false, http.StatusForbidden, 170, "Missing required parameter: status.")
+		return
+	}
+	if s.isDuplicate(text) {
+		s.writeError(w, false, http.StatusForbidden, 187, "Status is a duplicate.")
+		return
+	}
+	inReplyTo, err := intParam(r.PostForm, "in_reply_to_status_id", 0)
+	if err != nil {
+		s.writeError(w, false, http.StatusBadRequest, 44, "Invalid parameter.")
+		return
+	}
+	id := s.addTweet(text)
+	i, _ := s.findTweet(id)
+	s.tweets[i].InReplyTo = inReplyTo
+	writeJSON(w, http.StatusOK, s.v1Tweet(s.tweets[i]))
+}
+
+func (s *Server) statusesDestroy(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/1.1/statuses/destroy/"), ".json")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	i, found := s.findTweet(id)
+	if err != nil || !found {
+		s.writeError(w, false, http.StatusNotFound, 144, "No status found with that ID.")
+		return
+	}
+	deleted := s.tweets[i]
+	s.tweets = append(s.tweets[:i], s.tweets[i+1:]...)
+	writeJSON(w, http.StatusOK, s.v1Tweet(deleted))
+}
+
+func (s *Server) statusesUserTimeline(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if !strings.EqualFold(query.Get("screen_name"), s.screenName) {
+		s.writeError(w, false, http.StatusNotFound, 34, "Sorry, that page does not exist.")
+		return
+	}
+	sinceID, err1 := intParam(query, "since_id", 0)
+	maxID, err2 := intParam(query, "max_id", 0)
+	count, err3 := intParam(query, "count", 20)
+	if err1 != nil || err2 != nil || err3 != nil {
+		s.writeError(w, false, http.StatusBadRequest, 44, "Invalid parameter.")
+		return
+	}
+	if count > maxTimelineCount {
+		count = maxTimelineCount
+	}
+
+	tweets := []v1Tweet{}
+	for _, t := range s.page(sinceID, maxID, int(count)) {
+		tweets = append(tweets, s.v1Tweet(t))
+	}
+	writeJSON(w, http.StatusOK, tweets)
+}
+
+// statusesMentionsTimeline lists the replies, whose authors get made up
+// user IDs.
+func (s *Server) statusesMentionsTimeline(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	sinceID, err1 := intParam(query, "since_id", 0)
+	count, err2 := intParam(query, "count", 20)
+	if err1 != nil || err2 != nil {
+		s.writeError(w, false, http.StatusBadRequest, 44, "Invalid parameter.")
+		return
+	}
+	if count > maxTimelineCount {
+		count = maxTimelineCount
+	}
+
+	tweets := []v1Tweet{}
+	for _, reply := range s.mentions(sinceID, int(count)) {
+		tweet := v1Tweet{
+			ID:        reply.ID,
+			IDStr:     strconv.FormatInt(reply.ID, 10),
+			Text:      reply.Text,
+			CreatedAt: reply.CreatedAt.Format(createdAtLayout),
+			User:      &v1User{ID: 5000, IDStr: "5000", ScreenName: reply.Author},
+		}
+		tweets = append(tweets, tweet)
+	}
+	writeJSON(w, http.StatusOK, tweets)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,287 @@
+package faketwitter
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	minV2MaxResults       = 5
+	maxV2MaxResults       = 100
+	minV2SearchMaxResults = 10
+)
+
+// conversationQuery is the only search query the server understands
+var conversationQuery = regexp.MustCompile(`^conversation_id:(\d+)( is:reply)?$`)
+
+type v2User struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+type v2PublicMetrics struct {
+	LikeCount     int64 `json:"like_count"`
+	RetweetCount  int64 `json:"retweet_count"`
+	ReplyCount    int64 `json:"reply_count"`
+	QuoteCount    int64 `json:"quote_count"`
+	BookmarkCount int64 `json:"bookmark_count"`
+}
+
+type v2NonPublicMetrics struct {
+	ImpressionCount   int64 `json:"impression_count"`
+	URLLinkClicks     int64 `json:"url_link_clicks"`
+	UserProfileClicks int64 `json:"user_profile_clicks"`
+}
+
+type v2Tweet struct {
+	ID               string              `json:"id"`
+	Text             string              `json:"text"`
+	CreatedAt        string              `json:"created_at,omitempty"`
+	PublicMetrics    *v2PublicMetrics    `json:"public_metrics,omitempty"`
+	NonPublicMetrics *v2NonPublicMetrics `json:"non_public_metrics,omitempty"`
+}
+
+func (s *Server) v2User() v2User {
+	return v2User{
+		ID:       strconv.FormatInt(s.userID, 10),
+		Username: s.screenName,
+	}
+}
+
+// v2TweetWithFields renders a tweet with the fields asked for in
+// tweet.fields, like the real API, which only returns id and text by default.
+func v2TweetWithFields(t Tweet, fields string) v2Tweet {
+	tweet := v2Tweet{
+		ID:   strconv.FormatInt(t.ID, 10),
+		Text: t.Text,
+	}
+	for _, field := range strings.Split(fields, ",") {
+		switch field {
+		case "created_at":
+			tweet.CreatedAt = t.CreatedAt.UTC().Format(time.RFC3339)
+		case "public_metrics":
+			tweet.PublicMetrics = &v2PublicMetrics{
+				LikeCount:     t.Likes,
+				RetweetCount:  t.Retweets,
+				ReplyCount:    t.Replies,
+				QuoteCount:    t.Quotes,
+				BookmarkCount: t.Bookmarks,
+			}
+		case "non_public_metrics":
+			tweet.NonPublicMetrics = &v2NonPublicMetrics{
+				ImpressionCount:   t.Impressions,
+				URLLinkClicks:     t.URLLinkClicks,
+				UserProfileClicks: t.ProfileClicks,
+			}
+		}
+	}
+	return tweet
+}
+
+func (s *Server) v2UsersMe(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": s.v2User()})
+}
+
+func (s *Server) v2UsersByUsername(w http.ResponseWriter, r *http.Request) {
+	username := strings.TrimPrefix(r.URL.Path, "/2/users/by/username/")
+	if !strings.EqualFold(username, s.screenName) {
+		s.writeError(w, true, http.StatusNotFound, 0, "Could not find user with username: ["+username+"].")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": s.v2User()})
+}
+
+func (s *Server) v2UsersTweets(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/2/users/"), "/tweets")
+	if id != strconv.FormatInt(s.userID, 10) {
+		s.writeError(w, true, http.StatusNotFound, 0, "Could not find user with id: ["+id+"].")
+		return
+	}
+	query := r.URL.Query()
+	sinceID, err1 := intParam(query, "since_id", 0)
+	maxResults, err2 := intParam(query, "max_results", 10)
+	// The pagination token is the ID of the last tweet on the previous
+	// page, which real tokens are not, but clients treat them as opaque
+	untilID, err3 := intParam(query, "pagination_token", 0)
+	if err1 != nil || err2 != nil || err3 != nil || maxResults < minV2MaxResults || maxResults > maxV2MaxResults {
+		s.writeError(w, true, http.StatusBadRequest, 0, "Invalid Request: One or more parameters to your request was invalid.")
+		return
+	}
+	maxID := int64(0)
+	if untilID > 0 {
+		maxID = untilID - 1
+	}
+
+	page := s.page(sinceID, maxID, int(maxResults))
+	data := []v2Tweet{}
+	for _, t := range page {
+		data = append(data, v2TweetWithFields(t, query.Get("tweet.fields")))
+	}
+	meta := map[string]interface{}{"result_count": len(data)}
+	if len(page) > 0 {
+		last := page[len(page)-1].ID
+		if len(s.page(sinceID, last-1, 1)) > 0 {
+			meta["next_token"] = strconv.FormatInt(last, 10)
+		}
+	}
+	resp := map[string]interface{}{"meta": meta}
+	if len(data) > 0 {
+		resp["data"] = data
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) v2TweetsLookup(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	data := []v2Tweet{}
+	errors := []map[string]interface{}{}
+	for _, idStr := range strings.Split(query.Get("ids"), ",") {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		i, found := s.findTweet(id)
+		if err != nil || !found {
+			errors = append(errors, map[string]interface{}{
+				"value":  idStr,
+				"detail": "Could not find tweet with ids: [" + idStr + "].",
+				"title":  "Not Found Error",
+				"type":   "https://api.twitter.com/2/problems/resource-not-found",
+			})
+			continue
+		}
+		data = append(data, v2TweetWithFields(s.tweets[i], query.Get("tweet.fields")))
+	}
+	resp := map[string]interface{}{}
+	if len(data) > 0 {
+		resp["data"] = data
+	}
+	if len(errors) > 0 {
+		resp["errors"] = errors
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) v2TweetsCreate(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Text  string `json:"text"`
+		Reply *struct {
+			InReplyToTweetID string `json:"in_reply_to_tweet_id"`
+		} `json:"reply"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Text == "" {
+		s.writeError(w, true, http.StatusBadRequest, 0, "Invalid Request: One or more parameters to your request was invalid.")
+		return
+	}
+	if s.isDuplicate(body.Text) {
+		s.writeError(w, true, http.StatusForbidden, 0, "You are not allowed to create a Tweet with duplicate content.")
+		return
+	}
+	var inReplyTo int64
+	if body.Reply != nil {
+		var err error
+		if inReplyTo, err = strconv.ParseInt(body.Reply.InReplyToTweetID, 10, 64); err != nil {
+			s.writeError(w, true, http.StatusBadRequest, 0, "Invalid Request: One or more parameters to your request was invalid.")
+			return
+		}
+	}
+	id := s.addTweet(body.Text)
+	i, _ := s.findTweet(id)
+	s.tweets[i].InReplyTo = inReplyTo
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"data": v2Tweet{ID: strconv.FormatInt(id, 10), Text: body.Text},
+	})
+}
+
+func (s *Server) v2TweetsDelete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/2/tweets/"), 10, 64)
+	if err != nil {
+		s.writeError(w, true, http.StatusBadRequest, 0, "Invalid Request: One or more parameters to your request was invalid.")
+		return
+	}
+	i, found := s.findTweet(id)
+	if !found {
+		s.writeError(w, true, http.StatusNotFound, 0, "Could not find tweet with id: ["+strconv.FormatInt(id, 10)+"].")
+		return
+	}
+	s.tweets = append(s.tweets[:i], s.tweets[i+1:]...)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": map[string]bool{"deleted": true},
+	})
+}
+
+// v2SearchRecent finds the replies in a conversation, newest first.
+func (s *Server) v2SearchRecent(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	match := conversationQuery.FindStringSubmatch(query.Get("query"))
+	maxResults, err := intParam(query, "max_results", 10)
+	if match == nil || err != nil || maxResults < minV2SearchMaxResults || maxResults > maxV2MaxResults {
+		s.writeError(w, true, http.StatusBadRequest, 0, "Invalid Request: One or more parameters to your request was invalid.")
+		return
+	}
+	conversationID, _ := strconv.ParseInt(match[1], 10, 64)
+	replies := []Reply{}
+	for i := len(s.replies) - 1; i >= 0 && len(replies) < int(maxResults); i-- {
+		if s.replies[i].InReplyTo == conversationID {
+			replies = append(replies, s.replies[i])
+		}
+	}
+	resp := v2Replies(replies, query.Get("tweet.fields"), query.Get("expansions") == "author_id")
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) v2UsersMentions(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/2/users/"), "/mentions")
+	if id != strconv.FormatInt(s.userID, 10) {
+		s.writeError(w, true, http.StatusNotFound, 0, "Could not find user with id: ["+id+"].")
+		return
+	}
+	query := r.URL.Query()
+	sinceID, err1 := intParam(query, "since_id", 0)
+	maxResults, err2 := intParam(query, "max_results", 10)
+	if err1 != nil || err2 != nil || maxResults < minV2MaxResults || maxResults > maxV2MaxResults {
+		s.writeError(w, true, http.StatusBadRequest, 0, "Invalid Request: One or more parameters to your request was invalid.")
+		return
+	}
+	resp := v2Replies(s.mentions(sinceID, int(maxResults)), query.Get("tweet.fields"), query.Get("expansions") == "author_id")
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// v2Replies renders replies by other users, whose IDs are made up from
+// their position in the expansion.
+func v2Replies(replies []Reply, tweetFields string, withAuthors bool) map[string]interface{} {
+	fields := strings.Split(tweetFields, ",")
+	data := []map[string]interface{}{}
+	users := []v2User{}
+	userIDs := map[string]string{}
+	for _, reply := range replies {
+		userID, ok := userIDs[reply.Author]
+		if !ok {
+			userID = strconv.Itoa(5000 + len(userIDs))
+			userIDs[reply.Author] = userID
+			users = append(users, v2User{ID: userID, Username: reply.Author})
+		}
+		tweet := map[string]interface{}{
+			"id":   strconv.FormatInt(reply.ID, 10),
+			"text": reply.Text,
+		}
+		for _, field := range fields {
+			switch field {
+			case "author_id":
+				tweet["author_id"] = userID
+			case "created_at":
+				tweet["created_at"] = reply.CreatedAt.UTC().Format(time.RFC3339)
+			}
+		}
+		data = append(data, tweet)
+	}
+	resp := map[string]interface{}{"meta": map[string]interface{}{"result_count": len(data)}}
+	if len(data) > 0 {
+		resp["data"] = data
+	}
+	if withAuthors && len(users) > 0 {
+		resp["includes"] = map[string]interface{}{"users": users}
+	}
+	return resp
+}
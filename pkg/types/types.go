@@ -1,20 +1,145 @@
 package types
 
+import "time"
+
 type Tweet struct {
 	Spec   TweetSpec
 	Status TweetStatus
 }
 
 type TweetSpec struct {
-	Name string
-	Text string
+	Namespace string
+	Name      string
+	UID       string
+	// Target is the account this view of the Tweet is for. A Tweet posted
+	// to several targets is reconciled once per target.
+	Target string
+	Text   string
+	// InReplyTo is the platform ID of the post this one answers, for
+	// auto-replies
+	InReplyTo string
+	// Approvals lists the distinct users other than the author who
+	// approved the Tweet. It is not posted until there are
+	// RequiredApprovals of them.
+	Approvals         []string
+	RequiredApprovals int
+	// Deleting is set once the Tweet resource is marked for deletion and
+	// only the finalizer keeps it around
+	Deleting bool
 }
 
 type TweetStatus struct {
-	ID       int64
-	Likes    int64
-	Retweets int64
-	Replies  int64
+	ID int64
+	// RemoteID is how the platform itself refers to the post, where the
+	// numeric ID alone is not enough, such as a Bluesky record URI
+	RemoteID  string
+	URL       string
+	Likes     int64
+	Retweets  int64
+	Replies   int64
+	Quotes    int64
+	Bookmarks int64
+	// Only reported to the author of the post, and not by every platform
+	Impressions   int64
+	URLLinkClicks int64
+	ProfileClicks int64
+	// ApprovedBy lists the approvers of the post, recorded when posting
+	ApprovedBy []string
+}
+
+// Condition reports how the last attempt to bring a post in line with its
+// Tweet went, like a Kubernetes condition.
+type Condition struct {
+	Type    string
+	Status  bool
+	Reason  string
+	Message string
 }
 
 type Tweets []Tweet
+
+// Reply is a post answering one of ours.
+type Reply struct {
+	// ID is how the platform refers to the reply
+	ID     string
+	Author string
+	Text   string
+	Time   time.Time
+}
+
+// Mention is a post addressed to the account.
+type Mention struct {
+	// ID is how the platform refers to the mention. Later mentions are
+	// fetched by starting after it.
+	ID string
+	// PostID is the post that mentions the account, which is replied to.
+	// It is the ID, unless the platform reports mentions as notifications.
+	PostID string
+	Author string
+	Text   string
+	Time   time.Time
+	URL    string
+}
+
+type MentionWatch struct {
+	Spec   MentionWatchSpec
+	Status MentionWatchStatus
+}
+
+type MentionWatchSpec struct {
+	Namespace  string
+	Name       string
+	UID        string
+	Target     string
+	WebhookURL string
+}
+
+type MentionWatchStatus struct {
+	StartedAt       time.Time
+	SinceID         string
+	Mentions        int64
+	LastMentionTime time.Time
+}
+
+type AutoReply struct {
+	Spec   AutoReplySpec
+	Status AutoReplyStatus
+}
+
+type AutoReplySpec struct {
+	Namespace  string
+	Name       string
+	UID        string
+	Target     string
+	Regex      string
+	Keywords   []string
+	Template   string
+	Cooldown   time.Duration
+	DailyLimit int
+}
+
+type AutoReplyStatus struct {
+	StartedAt time.Time
+	SinceID   string
+	Replies   int64
+	// Answered holds the mentions answered recently, newest first
+	Answered []AnsweredMention
+}
+
+type AnsweredMention struct {
+	ID     string
+	Author string
+	// ReplyID is empty while the reply is being posted
+	ReplyID string
+	Time    time.Time
+}
+
+type TweetPolicy struct {
+	Name               string
+	Namespaces         []string
+	DenyPatterns       []string
+	RequiredPatterns   []string
+	AllowedLinkDomains []string
+	// MaxMentions is nil when mentions are not limited
+	MaxMentions *int
+}
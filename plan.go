@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/go-logr/logr"
+	"github.com/jonatanblue/tweet-operator/pkg/reconciler"
+)
+
+// runPlan prints what a reconciliation would change without changing
+// anything, so the changes can be reviewed before the operator applies them.
+func runPlan(log logr.Logger, args []string) {
+	flags := flag.NewFlagSet("plan", flag.ExitOnError)
+	output := flags.String("output", "text", "Output format, text or json")
+	flags.Parse(args)
+	if *output != "text" && *output != "json" {
+		fatal(log, fmt.Errorf("unknown output format %q", *output), "Invalid --output")
+	}
+
+	kubeConfig, err := getKubeConfig()
+	if err != nil {
+		fatal(log, err, "Failed to load kubeconfig")
+	}
+	callTimeout := lookupDurationEnv(log, "CALL_TIMEOUT", defaultCallTimeout)
+	kubeConfig.Timeout = callTimeout
+	ctx := context.Background()
+	plans := []*reconciler.Plan{}
+	for _, t := range newTargets(ctx, log, kubeConfig, callTimeout) {
+		reconciler := reconciler.NewTweetReconciler(
+			newK8sClient(kubeConfig, t),
+			t.twitterClient,
+			nil,
+			nil,
+			t.userName,
+			reconciler.DefaultSnapshotTTL,
+			log.WithName("reconciler"),
+		)
+		plan, err := reconciler.Plan(ctx)
+		if err != nil {
+			fatal(log, err, "Failed to compute plan")
+		}
+		plan.Target = t.name
+		plans = append(plans, plan)
+	}
+
+	if *output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(plans)
+	} else {
+		for i, plan := range plans {
+			if i > 0 {
+				fmt.Fprintln(os.Stdout)
+			}
+			if err = plan.WriteText(os.Stdout); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		fatal(log, err, "Failed to write plan")
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// defaultShutdownGracePeriod leaves in-flight work time to finish within
+// the 30 seconds Kubernetes waits after SIGTERM by default
+const defaultShutdownGracePeriod = 25 * time.Second
+
+// exitGracePeriodExceeded is the exit code when in-flight work had to be
+// cancelled, so a post may have been made without its ID being recorded
+const exitGracePeriodExceeded = 2
+
+// newShutdown returns a context that is done once SIGTERM or SIGINT comes
+// in, after which no new work is started, and the context for in-flight
+// work, which is cancelled when it has not finished gracePeriod later. A
+// second signal kills the process right away.
+func newShutdown(log logr.Logger, gracePeriod time.Duration) (stopping, work context.Context, cancel func()) {
+	stopping, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	work, cancelWork := context.WithCancel(context.Background())
+	go func() {
+		<-stopping.Done()
+		stop()
+		if work.Err() != nil {
+			return
+		}
+		log.Info("Shutting down, finishing in-flight work", "gracePeriod", gracePeriod.String())
+		timer := time.NewTimer(gracePeriod)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			log.Info("Grace period exceeded, cancelling in-flight work")
+			cancelWork()
+		case <-work.Done():
+		}
+	}()
+	return stopping, work, func() {
+		cancelWork()
+		stop()
+	}
+}
+
+// shutdownExitCode returns the exit code once the loop has returned:
+// exitGracePeriodExceeded when in-flight work was cancelled, 0 otherwise.
+func shutdownExitCode(work context.Context) int {
+	if work.Err() != nil {
+		return exitGracePeriodExceeded
+	}
+	return 0
+}
+
+// wait pauses for d, or until ctx is done.
+func wait(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
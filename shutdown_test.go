@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/jonatanblue/tweet-operator/pkg/admission"
+	v1 "github.com/jonatanblue/tweet-operator/pkg/apis/example.com/v1"
+	tweettypes "github.com/jonatanblue/tweet-operator/pkg/types"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func Test_newShutdown(t *testing.T) {
+	tests := map[string]struct {
+		gracePeriod time.Duration
+		workTakes   time.Duration
+		err         error
+		code        int
+	}{
+		"work finishes in time": {
+			gracePeriod: time.Minute,
+			workTakes:   10 * time.Millisecond,
+		},
+		"grace period exceeded": {
+			gracePeriod: 10 * time.Millisecond,
+			workTakes:   time.Minute,
+			err:         context.Canceled,
+			code:        exitGracePeriodExceeded,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			stopping, work, cancel := newShutdown(logr.Discard(), test.gracePeriod)
+			defer cancel()
+			assert.NoError(t, stopping.Err())
+
+			terminate(t)
+			<-stopping.Done()
+			// A unit of work under way runs on, until it is done or cancelled
+			done := make(chan error)
+			go func() {
+				assert.NoError(t, work.Err())
+				wait(work, test.workTakes)
+				done <- work.Err()
+			}()
+			select {
+			case err := <-done:
+				assert.Equal(t, test.err, err)
+			case <-time.After(30 * time.Second):
+				t.Fatal("in-flight work was neither finished nor cancelled")
+			}
+			assert.Equal(t, test.code, shutdownExitCode(work))
+		})
+	}
+}
+
+func Test_AdmissionServedDuringShutdown(t *testing.T) {
+	stopping, work, cancel := newShutdown(logr.Discard(), time.Minute)
+	defer cancel()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	stopAdmission := serveAdmission(logr.Discard(), listener, writeServingCert(t), policyClientStub{})
+	url := "https://" + listener.Addr().String() + admission.PathValidateTweets
+
+	// SIGTERM comes in while a post is under way. Recording its ID is an
+	// update the API server still sends to the webhook.
+	terminate(t)
+	<-stopping.Done()
+	assert.NoError(t, reviewStatusUpdate(work, url))
+	assert.Zero(t, shutdownExitCode(work))
+
+	// Once the loop has returned
+	stopAdmission()
+	assert.Error(t, reviewStatusUpdate(context.Background(), url))
+}
+
+// terminate sends SIGTERM to the test, which newShutdown catches.
+func terminate(t *testing.T) {
+	process, err := os.FindProcess(os.Getpid())
+	assert.NoError(t, err)
+	assert.NoError(t, process.Signal(syscall.SIGTERM))
+}
+
+// reviewStatusUpdate asks the webhook to admit the update that records the
+// ID of a post.
+func reviewStatusUpdate(ctx context.Context, url string) error {
+	tweet := &v1.Tweet{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello-world", Namespace: "default"},
+		Spec:       v1.TweetSpec{Text: "Hello World"},
+	}
+	posted := tweet.DeepCopy()
+	posted.Status.ID = 1
+	body, err := json.Marshal(admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request: &admissionv1.AdmissionRequest{
+			UID:       "1234",
+			Operation: admissionv1.Update,
+			UserInfo:  authenticationv1.UserInfo{Username: "system:serviceaccount:default:tweet-operator", Groups: []string{"system:authenticated"}},
+			Object:    runtime.RawExtension{Object: posted},
+			OldObject: runtime.RawExtension{Object: tweet},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	client := &http.Client{
+		Timeout:   5 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+	}
+	resp, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	review := admissionv1.AdmissionReview{}
+	if err := json.NewDecoder(resp.Body).Decode(&review); err != nil {
+		return err
+	}
+	if review.Response == nil || !review.Response.Allowed {
+		return fmt.Errorf("status update not admitted: %+v", review.Response)
+	}
+	return nil
+}
+
+// writeServingCert writes a self-signed certificate for 127.0.0.1 where
+// the operator expects the mounted one.
+func writeServingCert(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "tweet-operator"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "tls.crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "tls.key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return dir
+}
+
+type policyClientStub []tweettypes.TweetPolicy
+
+func (stub policyClientStub) ListTweetPolicies(ctx context.Context) ([]tweettypes.TweetPolicy, error) {
+	return stub, nil
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	v1 "github.com/jonatanblue/tweet-operator/pkg/apis/example.com/v1"
+	tweetclient "github.com/jonatanblue/tweet-operator/pkg/client/clientset/versioned"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/k8sclient"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/twitterclient"
+	"github.com/jonatanblue/tweet-operator/pkg/reconciler"
+)
+
+// target is an account Tweets can be posted to. Each one is reconciled on
+// its own, so one that fails does not hold up the others.
+type target struct {
+	name          string
+	defaultTarget bool
+	twitterClient reconciler.TwitterClient
+	userName      string
+	// requiredApprovals is how many approvals a Tweet needs before it is
+	// posted to the target, unless its namespace asks for more
+	requiredApprovals int
+	reconciler        *reconciler.TweetReconciler
+	// replyIngester is nil for targets whose client cannot fetch replies
+	replyIngester *reconciler.ReplyIngester
+	// mentionWatcher is nil for targets whose client cannot fetch mentions
+	mentionWatcher *reconciler.MentionWatcher
+	// autoReplier is nil for targets whose client cannot fetch mentions
+	autoReplier *reconciler.AutoReplier
+	log         logr.Logger
+	// nextRun holds the target back after it asked to be requeued
+	nextRun time.Time
+	// nextReplies is when replies are next fetched
+	nextReplies time.Time
+	// nextMentions is when mentions are next fetched
+	nextMentions time.Time
+	// nextAutoReplies is when mentions are next answered
+	nextAutoReplies time.Time
+	// done marks a target a dry run is finished with
+	done bool
+}
+
+// newTargets returns the Twitter account configured through the
+// environment, if any, and one target for each Account resource. Tweets
+// without targets go to the one named by ACCOUNT, or to Twitter.
+func newTargets(ctx context.Context, log logr.Logger, kubeConfig *rest.Config, callTimeout time.Duration) []*target {
+	timelineMaxPages := lookupIntEnv(log, "TIMELINE_MAX_PAGES", twitterclient.DefaultTimelineMaxPages)
+	defaultName, accountIsDefault := os.LookupEnv("ACCOUNT")
+	if !accountIsDefault {
+		defaultName = k8sclient.DefaultTarget
+	}
+
+	targets := []*target{}
+	if _, ok := os.LookupEnv("TWITTER_USERNAME"); ok || !accountIsDefault {
+		twitterClient, userName := newTwitterClient(ctx, log, kubeConfig, timelineMaxPages, callTimeout)
+		targets = append(targets, &target{
+			name:              k8sclient.DefaultTarget,
+			defaultTarget:     !accountIsDefault,
+			twitterClient:     twitterClient,
+			userName:          userName,
+			requiredApprovals: lookupIntEnv(log, "REQUIRED_APPROVALS", 0),
+		})
+	}
+
+	tweetClientSet := tweetclient.NewForConfigOrDie(kubeConfig)
+	accounts, err := tweetClientSet.ExampleV1().Accounts("default").List(ctx, metav1.ListOptions{})
+	// Not found when the Account CRD is not installed
+	if err != nil && !apierrors.IsNotFound(err) {
+		fatal(log, err, "Failed to list accounts")
+	}
+	if err != nil {
+		accounts = &v1.AccountList{}
+	}
+	for _, account := range accounts.Items {
+		name := account.Name
+		if name == k8sclient.DefaultTarget && len(targets) > 0 {
+			log.Error(fmt.Errorf("account %s has the name of the Twitter target", name), "Skipping account")
+			continue
+		}
+		twitterClient, userName, err := newAccountClient(ctx, log, kubeConfig, name, timelineMaxPages, callTimeout)
+		if err != nil {
+			if name == defaultName {
+				fatal(log, err, "Failed to create client for account")
+			}
+			log.Error(err, "Skipping account", "account", name)
+			continue
+		}
+		targets = append(targets, &target{
+			name:              name,
+			defaultTarget:     name == defaultName,
+			twitterClient:     twitterClient,
+			userName:          userName,
+			requiredApprovals: account.Spec.RequiredApprovals,
+		})
+	}
+
+	for _, t := range targets {
+		if t.defaultTarget {
+			return targets
+		}
+	}
+	fatal(log, fmt.Errorf("account %s not found", defaultName), "Failed to read account")
+	return nil
+}
+
+// newK8sClient returns the target's view of the Tweets.
+func newK8sClient(kubeConfig *rest.Config, t *target) *k8sclient.K8sClient {
+	tweetClientSet := tweetclient.NewForConfigOrDie(kubeConfig)
+	kubeClientSet := kubernetes.NewForConfigOrDie(kubeConfig)
+	tweetClient := tweetClientSet.ExampleV1().Tweets("default")
+	return k8sclient.NewK8sClient(tweetClient).
+		ForTarget(t.name, t.defaultTarget).
+		WithRequiredApprovals(t.requiredApprovals).
+		WithNamespaceApprovals(kubeClientSet.CoreV1().Namespaces(), "default")
+}
+
+// newUnknownTargetClient returns the client for the targets Tweets name
+// that are not among targets, such as Accounts that were skipped.
+func newUnknownTargetClient(kubeConfig *rest.Config, targets []*target) *k8sclient.UnknownTargetClient {
+	tweetClientSet := tweetclient.NewForConfigOrDie(kubeConfig)
+	known := []string{}
+	for _, t := range targets {
+		known = append(known, t.name)
+	}
+	return k8sclient.NewUnknownTargetClient(tweetClientSet.ExampleV1().Tweets("default"), known)
+}
+
+// newReplyStore returns where the replies to the target's posts are kept.
+func newReplyStore(kubeConfig *rest.Config, t *target) *k8sclient.ReplyStore {
+	tweetClientSet := tweetclient.NewForConfigOrDie(kubeConfig)
+	return k8sclient.NewReplyStore(tweetClientSet.ExampleV1().TweetReplieses("default"), t.name)
+}
+
+// newMentionWatchClient returns the target's view of the MentionWatches.
+func newMentionWatchClient(kubeConfig *rest.Config, t *target) *k8sclient.MentionWatchClient {
+	tweetClientSet := tweetclient.NewForConfigOrDie(kubeConfig)
+	return k8sclient.NewMentionWatchClient(tweetClientSet.ExampleV1().MentionWatches("default"), t.name, t.defaultTarget)
+}
+
+// newAutoReplyClient returns the target's view of the AutoReplies.
+func newAutoReplyClient(kubeConfig *rest.Config, t *target) *k8sclient.AutoReplyClient {
+	tweetClientSet := tweetclient.NewForConfigOrDie(kubeConfig)
+	return k8sclient.NewAutoReplyClient(tweetClientSet.ExampleV1().AutoReplies("default"), t.name, t.defaultTarget)
+}
+
+// newTweetPolicyClient returns the client for the TweetPolicies, which
+// apply to all targets.
+func newTweetPolicyClient(kubeConfig *rest.Config) *k8sclient.TweetPolicyClient {
+	tweetClientSet := tweetclient.NewForConfigOrDie(kubeConfig)
+	return k8sclient.NewTweetPolicyClient(tweetClientSet.ExampleV1().TweetPolicies())
+}
+
+// reconcile runs a pass for the target, unless it is waiting to be requeued.
+func (t *target) reconcile(ctx context.Context, now time.Time) (bool, error) {
+	if now.Before(t.nextRun) {
+		return false, nil
+	}
+	reconciled, err := t.reconciler.Reconcile(ctx)
+	var requeue *reconciler.RequeueError
+	if errors.As(err, &requeue) {
+		t.nextRun = now.Add(requeue.After)
+	}
+	return reconciled, err
+}
+
+// ingestReplies fetches the replies to the target's posts once the interval
+// has passed, or later when the platform asks to wait.
+func (t *target) ingestReplies(ctx context.Context, now time.Time, interval time.Duration) error {
+	if t.replyIngester == nil {
+		return nil
+	}
+	return runPeriodically(ctx, &t.nextReplies, now, interval, t.replyIngester.Ingest)
+}
+
+// watchMentions fetches the mentions of the target's account for its
+// MentionWatches, on the same terms as ingestReplies.
+func (t *target) watchMentions(ctx context.Context, now time.Time, interval time.Duration) error {
+	if t.mentionWatcher == nil {
+		return nil
+	}
+	return runPeriodically(ctx, &t.nextMentions, now, interval, t.mentionWatcher.Watch)
+}
+
+// autoReply answers the mentions of the target's account that match its
+// AutoReplies, on the same terms as ingestReplies.
+func (t *target) autoReply(ctx context.Context, now time.Time, interval time.Duration) error {
+	if t.autoReplier == nil {
+		return nil
+	}
+	return runPeriodically(ctx, &t.nextAutoReplies, now, interval, t.autoReplier.Reply)
+}
+
+// runPeriodically runs f once next has passed, and sets the next run an
+// interval from now, or to when a rate limit resets if that is later.
+func runPeriodically(
+	ctx context.Context,
+	next *time.Time,
+	now time.Time,
+	interval time.Duration,
+	f func(context.Context) error,
+) error {
+	if now.Before(*next) {
+		return nil
+	}
+	*next = now.Add(interval)
+	err := f(ctx)
+	var rateLimitErr *twitterclient.RateLimitError
+	if errors.As(err, &rateLimitErr) && rateLimitErr.Reset.After(*next) {
+		*next = rateLimitErr.Reset
+	}
+	return err
+}
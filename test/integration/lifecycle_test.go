@@ -0,0 +1,225 @@
+//go:build integration
+
+// Package integration runs the operator binary against a real kube-apiserver
+// and etcd started by envtest, and the fake Twitter server. It needs the
+// envtest binaries, see README.md.
+package integration
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"testing"
+	"time"
+
+	v1 "github.com/jonatanblue/tweet-operator/pkg/apis/example.com/v1"
+	tweetclient "github.com/jonatanblue/tweet-operator/pkg/client/clientset/versioned"
+	typedv1 "github.com/jonatanblue/tweet-operator/pkg/client/clientset/versioned/typed/example.com/v1"
+	"github.com/jonatanblue/tweet-operator/pkg/libs/k8sclient"
+	"github.com/jonatanblue/tweet-operator/pkg/testing/faketwitter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+)
+
+const (
+	eventuallyTimeout  = 30 * time.Second
+	eventuallyInterval = 100 * time.Millisecond
+)
+
+var creds = faketwitter.Credentials{
+	ConsumerKey:       "consumer-key",
+	ConsumerSecret:    "consumer-secret",
+	AccessToken:       "access-token",
+	AccessTokenSecret: "access-token-secret",
+}
+
+func Test_TweetLifecycle(t *testing.T) {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		t.Skip("KUBEBUILDER_ASSETS is not set")
+	}
+
+	testEnv := &envtest.Environment{
+		CRDInstallOptions: envtest.CRDInstallOptions{
+			Paths: []string{filepath.Join("..", "..", "manifests", "example.com_tweets.yaml")},
+		},
+		ErrorIfCRDPathMissing: true,
+	}
+	config, err := testEnv.Start()
+	require.NoError(t, err)
+	defer testEnv.Stop()
+
+	user, err := testEnv.AddUser(envtest.User{Name: "tweet-operator", Groups: []string{"system:masters"}}, nil)
+	require.NoError(t, err)
+	kubeConfig, err := user.KubeConfig()
+	require.NoError(t, err)
+	kubeConfigPath := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(kubeConfigPath, kubeConfig, 0600))
+
+	server := faketwitter.New("bob", creds)
+	defer server.Close()
+
+	stop := startOperator(t, kubeConfigPath, server)
+	defer stop()
+
+	tweets := tweetclient.NewForConfigOrDie(config).ExampleV1().Tweets("default")
+	ctx := context.Background()
+
+	// Create and post
+	_, err = tweets.Create(ctx, &v1.Tweet{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello-world"},
+		Spec:       v1.TweetSpec{Text: "Hello World"},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	eventually(t, "tweet posted", func() bool {
+		return equal(timeline(server), []string{"Hello World"})
+	})
+	postedID := server.Tweets()[0].ID
+	eventually(t, "ID recorded with finalizer", func() bool {
+		tweet := get(t, tweets, "hello-world")
+		return twitterStatus(tweet).ID == postedID && equal(tweet.Finalizers, []string{k8sclient.Finalizer})
+	})
+	eventually(t, "Posted event recorded", func() bool {
+		events, err := kubernetes.NewForConfigOrDie(config).CoreV1().Events("default").List(ctx, metav1.ListOptions{})
+		require.NoError(t, err)
+		for _, event := range events.Items {
+			if event.InvolvedObject.Name == "hello-world" && event.Reason == "Posted" {
+				return true
+			}
+		}
+		return false
+	})
+
+	// Status sync
+	server.SetMetrics(postedID, 3, 2, 1)
+	eventually(t, "metrics synced", func() bool {
+		status := twitterStatus(get(t, tweets, "hello-world"))
+		return status.ID == postedID && status.Likes == 3 && status.Retweets == 2 && status.Replies == 1
+	})
+
+	// Edit
+	tweet := get(t, tweets, "hello-world")
+	tweet.Spec.Text = "Hello again"
+	_, err = tweets.Update(ctx, tweet, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	eventually(t, "edited tweet replaced", func() bool {
+		return equal(timeline(server), []string{"Hello again"})
+	})
+	editedID := server.Tweets()[0].ID
+	eventually(t, "new ID recorded", func() bool {
+		return twitterStatus(get(t, tweets, "hello-world")).ID == editedID
+	})
+
+	// Delete, which waits for the finalizer
+	require.NoError(t, tweets.Delete(ctx, "hello-world", metav1.DeleteOptions{}))
+	eventually(t, "tweet deleted", func() bool {
+		return equal(timeline(server), []string{})
+	})
+	eventually(t, "finalizer removed and resource gone", func() bool {
+		_, err := tweets.Get(ctx, "hello-world", metav1.GetOptions{})
+		return apierrors.IsNotFound(err)
+	})
+}
+
+// startOperator builds the operator and runs it in loop mode against the
+// test API server and the fake Twitter server. The returned func stops it
+// with SIGTERM.
+func startOperator(t *testing.T, kubeConfigPath string, server *faketwitter.Server) func() {
+	binary := filepath.Join(t.TempDir(), "tweet-operator")
+	build := exec.Command("go", "build", "-o", binary, ".")
+	build.Dir = filepath.Join("..", "..")
+	output, err := build.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	var logs bytes.Buffer
+	cmd := exec.Command(binary)
+	cmd.Env = append(
+		os.Environ(),
+		"KUBECONFIG="+kubeConfigPath,
+		"TWITTER_USERNAME=bob",
+		"TWITTER_API_VERSION=2",
+		"TWITTER_API_URL="+server.URL+"/2/",
+		"CONSUMER_KEY="+creds.ConsumerKey,
+		"CONSUMER_SECRET="+creds.ConsumerSecret,
+		"ACCESS_TOKEN="+creds.AccessToken,
+		"ACCESS_TOKEN_SECRET="+creds.AccessTokenSecret,
+		"SNAPSHOT_TTL=0s",
+		"RECONCILE_INTERVAL=100ms",
+		"LOG_FORMAT=text",
+		"LOG_VERBOSITY=1",
+	)
+	cmd.Stdout = &logs
+	cmd.Stderr = &logs
+	require.NoError(t, cmd.Start())
+
+	// Stopped like Kubernetes does, which it has to survive without losing
+	// anything
+	return func() {
+		require.NoError(t, cmd.Process.Signal(syscall.SIGTERM))
+		exited := make(chan error, 1)
+		go func() { exited <- cmd.Wait() }()
+		select {
+		case err := <-exited:
+			assert.NoError(t, err, "operator exits cleanly on SIGTERM")
+		case <-time.After(eventuallyTimeout):
+			cmd.Process.Kill()
+			<-exited
+			t.Error("operator did not exit on SIGTERM")
+		}
+		if t.Failed() {
+			t.Logf("operator logs:\n%s", logs.String())
+		}
+	}
+}
+
+func eventually(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	assert.Eventually(t, condition, eventuallyTimeout, eventuallyInterval, what)
+	if t.Failed() {
+		t.FailNow()
+	}
+}
+
+func get(t *testing.T, tweets typedv1.TweetInterface, name string) *v1.Tweet {
+	tweet, err := tweets.Get(context.Background(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+	return tweet
+}
+
+// twitterStatus returns the status of the Tweet on the Twitter target.
+func twitterStatus(tweet *v1.Tweet) v1.TargetStatus {
+	for _, status := range tweet.Status.Targets {
+		if status.Name == k8sclient.DefaultTarget {
+			return status
+		}
+	}
+	return v1.TargetStatus{}
+}
+
+func timeline(server *faketwitter.Server) []string {
+	texts := []string{}
+	for _, t := range server.Tweets() {
+		texts = append(texts, t.Text)
+	}
+	sort.Strings(texts)
+	return texts
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}